@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
+	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+	"golang.org/x/net/html"
+)
+
+const debugHelp = `Commands:
+  lives                       list the lives parsed from the page
+  live N                      show live N and the traces of the queriers parsing it, and evaluate queriers
+                              against its node
+  page                        evaluate queriers against the whole page
+  html [N]                    print the HTML of the current node, or of live N
+  xpath SELECTOR              list the nodes matching SELECTOR
+  css SELECTOR                list the nodes matching the CSS selector SELECTOR
+  q SELECTOR [| op args]...   evaluate a querier, showing arr after every filter
+  qall SELECTOR [| op args]...
+                              the same as q, selecting all matches
+  title, artists, detail, price, year, month, day, date, open, start
+                              evaluate the querier of the connector
+  help                        show this help
+  quit                        exit the debugger
+
+Filters are written like in connector files, for example: q //h2 | split " / " | keepIndex 0
+Selectors prefixed with css: are CSS selectors, for example: q css:article h2 | trim`
+
+// debug loads the test document or live page of a connector, and lets queriers be evaluated against it interactively.
+//
+// Usage: debug [--live] ConnectorID
+func debug(args []string) {
+	flags := flag.NewFlagSet("debug", flag.ExitOnError)
+	live := flags.Bool("live", false, "load the live page instead of the test document")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: debug [--live] ConnectorID")
+		return
+	}
+	connectorID := flags.Arg(0)
+	fetcher, ok := coreconnectors.Connectors[connectorID]
+	if !ok {
+		fmt.Println("Connector not found: " + connectorID)
+		return
+	}
+
+	// services are not started, so the client is created without the response cache
+	cfg, err := httpclient.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	fetcher.Client = httpclient.New(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	testDir := "test"
+	if *live {
+		testDir = ""
+	}
+	page, err := fetcher.LoadDebugPage(ctx, testDir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	d := &debugger{
+		ctx:     ctx,
+		fetcher: &fetcher,
+		page:    page,
+		node:    page.Node,
+		out:     os.Stdout,
+	}
+	fmt.Printf("Loaded %s for %s, type help for a list of commands\n", page.URL, connectorID)
+	d.run(os.Stdin)
+}
+
+type debugger struct {
+	ctx     context.Context
+	fetcher *fetchers.Simple
+	page    fetchers.DebugPage
+	// lives are the lives parsed from the page, which are parsed the first time they are needed
+	lives []fetchers.DebugLive
+	// node is the node queriers are evaluated against
+	node *html.Node
+	out  io.Writer
+}
+
+func (d *debugger) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(d.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(d.out)
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			return
+		}
+		if line == "" {
+			continue
+		}
+		err := d.exec(line)
+		if err != nil {
+			fmt.Fprintln(d.out, "error:", err)
+		}
+	}
+}
+
+func (d *debugger) exec(line string) (err error) {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch command {
+	case "help":
+		fmt.Fprintln(d.out, debugHelp)
+	case "lives":
+		err = d.parseLives()
+		if err != nil {
+			return
+		}
+		for i, l := range d.lives {
+			fmt.Fprintf(d.out, "%d: %s\n", i, summarizeNode(l.Node))
+			d.printLive(l, "\t")
+		}
+		fmt.Fprintf(d.out, "%d lives\n", len(d.lives))
+	case "live":
+		var l fetchers.DebugLive
+		l, err = d.liveAt(rest)
+		if err != nil {
+			return
+		}
+		d.node = l.Node
+		fmt.Fprintln(d.out, summarizeNode(l.Node))
+		d.printLive(l, "\t")
+		names := make([]string, 0, len(l.Traces))
+		for name := range l.Traces {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprint(d.out, name+": "+l.Traces[name].String())
+		}
+	case "page":
+		if d.page.Node == nil {
+			err = fmt.Errorf("connector uses a live html fetcher, so there is no page")
+			return
+		}
+		d.node = d.page.Node
+	case "html":
+		n := d.node
+		if rest != "" {
+			var l fetchers.DebugLive
+			l, err = d.liveAt(rest)
+			if err != nil {
+				return
+			}
+			n = l.Node
+		}
+		if n == nil {
+			err = fmt.Errorf("no node selected")
+			return
+		}
+		fmt.Fprintln(d.out, htmlquery.OutputHTML(n, true))
+	case "xpath", "css":
+		if command == "css" {
+			rest = htmlquerier.CSSPrefix + rest
+		}
+		if d.node == nil {
+			err = fmt.Errorf("no node selected")
+			return
+		}
+		var nodes []*html.Node
+		nodes, err = htmlquerier.QueryAll(d.node, rest)
+		if err != nil {
+			return
+		}
+		for i, n := range nodes {
+			fmt.Fprintf(d.out, "%d: %s\n", i, summarizeNode(n))
+		}
+		fmt.Fprintf(d.out, "%d matches\n", len(nodes))
+	case "q", "qall":
+		var spec htmlquerier.Spec
+		spec, err = htmlquerier.ParseSpec(rest)
+		if err != nil {
+			return
+		}
+		spec.All = command == "qall"
+		var q htmlquerier.Querier
+		q, err = spec.Querier()
+		if err != nil {
+			return
+		}
+		err = d.printTrace(q)
+	default:
+		q, ok := d.connectorQuerier(command)
+		if !ok {
+			err = fmt.Errorf("unknown command %s, type help for a list of commands", command)
+			return
+		}
+		if !q.Initialized {
+			err = fmt.Errorf("connector does not define a %s querier", command)
+			return
+		}
+		err = d.printTrace(q)
+	}
+	return
+}
+
+// connectorQuerier returns the querier of the connector with the given name.
+func (d *debugger) connectorQuerier(name string) (q htmlquerier.Querier, ok bool) {
+	queriers := map[string]htmlquerier.Querier{
+		"title":   d.fetcher.TitleQuerier,
+		"artists": d.fetcher.ArtistsQuerier,
+		"detail":  d.fetcher.DetailQuerier,
+		"price":   d.fetcher.PriceQuerier,
+		"year":    d.fetcher.TimeHandler.YearQuerier,
+		"month":   d.fetcher.TimeHandler.MonthQuerier,
+		"day":     d.fetcher.TimeHandler.DayQuerier,
+		"date":    d.fetcher.TimeHandler.DateQuerier,
+		"open":    d.fetcher.TimeHandler.OpenTimeQuerier,
+		"start":   d.fetcher.TimeHandler.StartTimeQuerier,
+	}
+	q, ok = queriers[name]
+	return
+}
+
+// printTrace evaluates q against the current node, printing what every filter did.
+func (d *debugger) printTrace(q htmlquerier.Querier) (err error) {
+	if d.node == nil {
+		err = fmt.Errorf("no node selected")
+		return
+	}
+	_, trace, err := q.ExecuteTrace(d.node)
+	if err != nil {
+		return
+	}
+	fmt.Fprint(d.out, trace)
+	return
+}
+
+func (d *debugger) parseLives() (err error) {
+	if d.lives != nil {
+		return
+	}
+	d.lives, err = d.fetcher.DebugLives(d.ctx, d.page)
+	return
+}
+
+func (d *debugger) liveAt(arg string) (l fetchers.DebugLive, err error) {
+	err = d.parseLives()
+	if err != nil {
+		return
+	}
+	i, err := strconv.Atoi(arg)
+	if err != nil {
+		err = fmt.Errorf("invalid live %q", arg)
+		return
+	}
+	if i < 0 || i >= len(d.lives) {
+		err = fmt.Errorf("live %d does not exist, there are %d lives", i, len(d.lives))
+		return
+	}
+	l = d.lives[i]
+	return
+}
+
+func (d *debugger) printLive(l fetchers.DebugLive, indent string) {
+	if l.Err != nil {
+		fmt.Fprintln(d.out, indent+"error: "+l.Err.Error())
+		return
+	}
+	printField := func(name string, value any) {
+		fmt.Fprintf(d.out, "%s%-14s %v\n", indent, name+":", value)
+	}
+	printField("title", l.Live.Title)
+	printField("artists", fmt.Sprintf("%q", l.Live.Artists))
+	printField("open", formatDebugTime(l.Live.OpenTime, l.Live.OpenTimeUnknown))
+	printField("start", formatDebugTime(l.Live.StartTime, l.Live.StartTimeUnknown))
+	printField("price", l.Live.Price)
+	printField("price english", l.Live.PriceEnglish)
+	printField("url", l.Live.URL)
+}
+
+func formatDebugTime(t time.Time, unknown bool) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if unknown {
+		return t.Format(time.DateOnly) + " (time unknown)"
+	}
+	return t.Format(time.DateTime)
+}
+
+// summarizeNode returns the text of a node on a single line, shortened to fit a terminal.
+func summarizeNode(n *html.Node) string {
+	if n == nil {
+		return "<nil>"
+	}
+	text := strings.Join(strings.Fields(htmlquery.InnerText(n)), " ")
+	if runes := []rune(text); len(runes) > 80 {
+		text = string(runes[:80]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
+	"github.com/yayuyokitano/livefetcher/internal/core/queries"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
+	"github.com/yayuyokitano/livefetcher/internal/services"
+)
+
+// diff fetches a connector and prints what posting its lives would change, without touching the database.
+//
+// Usage: diff [--format text|json|html] ConnectorID
+func diff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "output format, one of text, json or html")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: diff [--format text|json|html] ConnectorID")
+		return
+	}
+	connectorID := flags.Arg(0)
+	fetcher, ok := coreconnectors.Connectors[connectorID]
+	if !ok {
+		fmt.Println("Connector not found: " + connectorID)
+		return
+	}
+
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	err = i18nloader.Init()
+	if err != nil {
+		panic(err)
+	}
+	configureFetchClient()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// like RunConnector, lives fetched before an error such as a month that is not published yet are still diffed, as
+	// a crawl would post them
+	err = fetcher.Fetch(ctx)
+	var robotsErr *httpclient.RobotsError
+	switch {
+	case ctx.Err() != nil:
+		fmt.Println("fetch aborted:", context.Cause(ctx))
+		return
+	case errors.As(err, &robotsErr):
+		fmt.Println(err)
+		return
+	case len(fetcher.Lives) == 0:
+		if err == nil {
+			err = errors.New("no lives fetched")
+		}
+		fmt.Println(err)
+		return
+	}
+	if err != nil {
+		// written to stderr so that the changeset on stdout stays parseable
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	}
+	changeset, err := queries.DiffLives(ctx, fetcher.Lives, &http.Request{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	localizer := i18nloader.LocalizerFromLangs([]string{"en"})
+	switch *format {
+	case "text":
+		err = writeChangesetText(os.Stdout, connectorID, changeset, localizer)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(changeset)
+	case "html":
+		err = writeChangesetHTML(os.Stdout, connectorID, changeset, localizer)
+	default:
+		err = errors.New("unknown format: " + *format)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func describeLive(live datastructures.Live) string {
+	return fmt.Sprintf("%s %s [%s]", formatDiffTime(live.StartTime, live.StartTimeUnknown), live.Title, strings.Join(live.Artists, ", "))
+}
+
+func formatDiffTime(t time.Time, unknown bool) string {
+	if unknown {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func writeChangesetText(w io.Writer, connectorID string, changeset datastructures.Changeset, localizer i18nloader.SimplifiedLocalizer) (err error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d added, %d modified, %d deleted, %d unchanged\n", connectorID, len(changeset.Added), len(changeset.Modified), len(changeset.Deleted), changeset.Unchanged)
+	if len(changeset.Anomalies) != 0 {
+		b.WriteString("\nThis scrape would be quarantined:\n")
+		for _, anomaly := range changeset.Anomalies {
+			fmt.Fprintf(&b, "  ! %s\n", anomaly)
+		}
+	}
+	if len(changeset.Added) != 0 {
+		b.WriteString("\nAdded:\n")
+		for _, live := range changeset.Added {
+			fmt.Fprintf(&b, "+ %s\n", describeLive(live))
+		}
+	}
+	if len(changeset.Modified) != 0 {
+		b.WriteString("\nModified:\n")
+		for _, change := range changeset.Modified {
+			fmt.Fprintf(&b, "~ %s\n", describeLive(change.Old))
+			for _, field := range change.Fields.Sort() {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n", localizer.Localize(field.Type.String()), field.OldValue, field.NewValue)
+			}
+		}
+	}
+	if len(changeset.Deleted) != 0 {
+		b.WriteString("\nDeleted:\n")
+		for _, live := range changeset.Deleted {
+			fmt.Fprintf(&b, "- %s\n", describeLive(live))
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return
+}
+
+func writeChangesetHTML(w io.Writer, connectorID string, changeset datastructures.Changeset, localizer i18nloader.SimplifiedLocalizer) (err error) {
+	fp := filepath.Join("web", "template", "diff.gohtml")
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"T":              localizer.Localize,
+		"FormatLiveTime": formatDiffTime,
+	}).ParseFiles(fp)
+	if err != nil {
+		return
+	}
+	return tmpl.ExecuteTemplate(w, "diff", struct {
+		ConnectorID string
+		Changeset   datastructures.Changeset
+	}{connectorID, changeset})
+}
@@ -3,12 +3,18 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
@@ -16,9 +22,12 @@ import (
 	"github.com/yayuyokitano/livefetcher/internal/api/endpoints"
 	"github.com/yayuyokitano/livefetcher/internal/api/router"
 	runner "github.com/yayuyokitano/livefetcher/internal/core"
+	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
 	"github.com/yayuyokitano/livefetcher/internal/core/logging"
 	"github.com/yayuyokitano/livefetcher/internal/core/queries"
+	"github.com/yayuyokitano/livefetcher/internal/core/scheduler"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/templatebuilder"
 	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
 	"github.com/yayuyokitano/livefetcher/internal/services"
@@ -33,7 +42,17 @@ func main() {
 		}
 	}
 
-	switch os.Args[len(os.Args)-1] {
+	if len(os.Args) < 2 {
+		fmt.Println("Invalid command")
+		return
+	}
+
+	err := coreconnectors.LoadConnectorFiles(coreconnectors.ConnectorDir())
+	if err != nil {
+		panic(err)
+	}
+
+	switch os.Args[1] {
 	case "migrate":
 		fmt.Println("Performing migration...")
 		performMigration()
@@ -45,16 +64,43 @@ func main() {
 		fmt.Println("Finished generating!")
 		return
 	case "test":
-		err := runner.RunConnectorTest(os.Getenv("CONNECTOR_ID"))
+		err := runner.RunConnectorTest(context.Background(), os.Getenv("CONNECTOR_ID"))
 		fmt.Println(err)
 		return
+	case "crawl":
+		crawl(os.Args[2:])
+		return
+	case "quarantine":
+		quarantine(os.Args[2:])
+		return
+	case "diff":
+		diff(os.Args[2:])
+		return
+	case "debug":
+		debug(os.Args[2:])
+		return
+	case "newconnector":
+		newConnector(os.Args[2:])
+		return
+	case "backfillprices":
+		backfillPrices(os.Args[2:])
+		return
+	case "reindexsearch":
+		reindexSearch(os.Args[2:])
+		return
+	case "artists":
+		artists(os.Args[2:])
+		return
+	case "admin":
+		admin(os.Args[2:])
+		return
 	case "start":
 		fmt.Println("Starting server...")
 	default:
 		fmt.Println("Invalid command")
 		return
 	}
-	err := services.Start()
+	err = services.Start()
 	defer services.Stop()
 	if err != nil {
 		panic(err)
@@ -63,6 +109,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	configureFetchClient()
 
 	fmt.Println("Connected to Postgres!")
 
@@ -70,6 +117,404 @@ func main() {
 	startServer()
 }
 
+// crawl runs the connector scheduler in the foreground.
+//
+// Usage: crawl [--once] [--interval 6h] [--jitter 15m] [--workers 4] [--timeout 30m] [--batch-timeout 10m] [ConnectorID...]
+func crawl(args []string) {
+	cfg, err := scheduler.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	flags := flag.NewFlagSet("crawl", flag.ExitOnError)
+	once := flags.Bool("once", false, "run every connector once and exit")
+	flags.DurationVar(&cfg.Interval, "interval", cfg.Interval, "default time between two runs of a connector")
+	flags.DurationVar(&cfg.Jitter, "jitter", cfg.Jitter, "maximum random delay added to every run")
+	flags.IntVar(&cfg.Workers, "workers", cfg.Workers, "maximum number of connectors running at the same time")
+	flags.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "maximum duration of a single connector run")
+	flags.DurationVar(&cfg.BatchTimeout, "batch-timeout", cfg.BatchTimeout, "maximum duration of the work done after every batch of runs")
+	flags.Parse(args)
+
+	connectorIDs := flags.Args()
+	if len(connectorIDs) == 0 {
+		connectorIDs = coreconnectors.Connectors.ScheduledIDs()
+	}
+
+	err = services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	err = i18nloader.Init()
+	if err != nil {
+		panic(err)
+	}
+	configureFetchClient()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := newScheduler(cfg, connectorIDs)
+	if *once {
+		fmt.Printf("Running %d connectors once...\n", len(connectorIDs))
+		s.RunOnce(ctx)
+		return
+	}
+	fmt.Printf("Scheduling %d connectors every %s...\n", len(connectorIDs), cfg.Interval)
+	s.Run(ctx)
+}
+
+// quarantine lists, approves or rejects scrapes quarantined by the anomaly guard.
+//
+// Usage: quarantine [approve|reject ID]
+func quarantine(args []string) {
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	err = i18nloader.Init()
+	if err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		scrapes, err := queries.GetQuarantinedScrapes(ctx, datastructures.QuarantineStatusPending)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(scrapes) == 0 {
+			fmt.Println("No pending quarantined scrapes.")
+		}
+		for _, scrape := range scrapes {
+			fmt.Printf("%d: %s (%d lives, %s)\n", scrape.ID, strings.Join(scrape.LiveHouses, ", "), len(scrape.Lives), scrape.CreatedAt.Format(time.DateTime))
+			for _, anomaly := range scrape.Anomalies {
+				fmt.Println("\t" + anomaly)
+			}
+		}
+		return
+	}
+
+	if len(args) != 2 {
+		fmt.Println("Usage: quarantine [approve|reject ID]")
+		return
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		fmt.Println("Invalid ID: " + args[1])
+		return
+	}
+	switch args[0] {
+	case "approve":
+		deleted, added, modified, addedArtists, err := queries.ApproveQuarantinedScrape(ctx, id, &http.Request{})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Lives: deleted %d, added %d, modified %d, added %d artists\n", deleted, added, modified, addedArtists)
+	case "reject":
+		err = queries.RejectQuarantinedScrape(ctx, id)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("Rejected scrape " + args[1])
+	default:
+		fmt.Println("Usage: quarantine [approve|reject ID]")
+	}
+}
+
+// backfillPrices parses the price tiers of lives saved before they were parsed, or of every live with --all.
+//
+// Usage: backfillprices [--all]
+func backfillPrices(args []string) {
+	flags := flag.NewFlagSet("backfillprices", flag.ExitOnError)
+	all := flags.Bool("all", false, "parse the price tiers of every live again")
+	flags.Parse(args)
+
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+
+	n, err := queries.BackfillPriceTiers(context.Background(), *all)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Parsed the price tiers of %d lives\n", n)
+}
+
+// reindexSearch indexes lives saved before they were indexed for full-text search, or every live with --all.
+//
+// Usage: reindexsearch [--all]
+func reindexSearch(args []string) {
+	flags := flag.NewFlagSet("reindexsearch", flag.ExitOnError)
+	all := flags.Bool("all", false, "index every live again")
+	flags.Parse(args)
+
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	// venue names are indexed in every language
+	err = i18nloader.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	n, err := queries.ReindexSearchDocuments(context.Background(), *all)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Indexed %d lives\n", n)
+}
+
+const artistsUsage = `Usage:
+	artists credits NAME
+	artists merge SOURCE TARGET
+	artists rename NAME NEW_NAME
+	artists split NAME NEW_NAME CREDIT...
+	artists normalize [--merge]
+	artists aliases NAME
+	artists suggestions [approve|reject ID]
+	artists profile [--url URL] [--description TEXT] [--social URL]... NAME
+	artists similarity [--all]`
+
+// artists manages canonical artists, which the credits of lives are resolved to.
+func artists(args []string) {
+	if len(args) == 0 {
+		fmt.Println(artistsUsage)
+		return
+	}
+
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	// venue names are indexed in every language when search documents are updated
+	err = i18nloader.Init()
+	if err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "credits" && len(args) == 2:
+		credits, err := queries.GetArtistCredits(ctx, args[1])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, credit := range credits {
+			fmt.Println(credit)
+		}
+	case args[0] == "merge" && len(args) == 3:
+		err = queries.MergeArtists(ctx, args[1], args[2])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Merged %q into %q\n", args[1], args[2])
+	case args[0] == "rename" && len(args) == 3:
+		err = queries.RenameArtist(ctx, args[1], args[2])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Renamed %q to %q\n", args[1], args[2])
+	case args[0] == "split" && len(args) >= 4:
+		err = queries.SplitArtist(ctx, args[1], args[2], args[3:])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Split %d credits of %q into %q\n", len(args[3:]), args[1], args[2])
+	case args[0] == "normalize":
+		flags := flag.NewFlagSet("artists normalize", flag.ExitOnError)
+		merge := flags.Bool("merge", false, "merge every group of artists into the one credited by the most lives")
+		flags.Parse(args[1:])
+
+		groups, err := queries.NormalizeArtists(ctx, *merge)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, group := range groups {
+			fmt.Println(strings.Join(group, " <- "))
+		}
+		if *merge {
+			fmt.Printf("Merged %d groups of artists\n", len(groups))
+		} else {
+			fmt.Printf("Found %d groups of artists sharing a key, run with --merge to merge them\n", len(groups))
+		}
+	case args[0] == "aliases" && len(args) == 2:
+		aliases, err := queries.GetArtistAliases(ctx, args[1])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, alias := range aliases {
+			fmt.Printf("%s (%s %s)\n", alias.Alias, alias.Source, alias.Username)
+		}
+	case args[0] == "suggestions" && len(args) == 1:
+		suggestions, err := queries.GetAliasSuggestions(ctx, datastructures.AliasSuggestionStatusPending)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(suggestions) == 0 {
+			fmt.Println("No pending alias suggestions.")
+		}
+		for _, suggestion := range suggestions {
+			fmt.Printf("%d: %s -> %s (by %s, %s)\n", suggestion.ID, suggestion.Alias, suggestion.Artist, suggestion.Username, suggestion.CreatedAt.Format(time.DateTime))
+		}
+	case args[0] == "suggestions" && len(args) == 3:
+		id, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Println("Invalid ID: " + args[2])
+			return
+		}
+		switch args[1] {
+		case "approve":
+			err = queries.ApproveAliasSuggestion(ctx, id, 0)
+		case "reject":
+			err = queries.RejectAliasSuggestion(ctx, id, 0)
+		default:
+			fmt.Println(artistsUsage)
+			return
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Resolved alias suggestion %d\n", id)
+	case args[0] == "profile":
+		flags := flag.NewFlagSet("artists profile", flag.ExitOnError)
+		artistURL := flags.String("url", "", "link to the website of the artist")
+		description := flags.String("description", "", "description of the artist")
+		var socials stringsFlag
+		flags.Var(&socials, "social", "link to a social account of the artist, can be given several times")
+		flags.Parse(args[1:])
+		if flags.NArg() != 1 {
+			fmt.Println(artistsUsage)
+			return
+		}
+
+		artist, err := queries.GetArtist(ctx, flags.Arg(0), 0)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// only the given fields are replaced
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "url":
+				artist.URL = *artistURL
+			case "description":
+				artist.Description = *description
+			case "social":
+				artist.Socials = socials
+			}
+		})
+		err = queries.UpdateArtistProfile(ctx, artist.Name, artist.ArtistProfile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Updated the profile of %q\n", artist.Name)
+	case args[0] == "similarity":
+		flags := flag.NewFlagSet("artists similarity", flag.ExitOnError)
+		all := flags.Bool("all", false, "compute the similarity of every artist again, not only those queued")
+		flags.Parse(args[1:])
+
+		if *all {
+			err := queries.QueueAllArtistSimilarities(ctx)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		n, err := queries.UpdateArtistSimilarities(ctx, 0)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Computed the similarity of %d artists\n", n)
+	default:
+		fmt.Println(artistsUsage)
+	}
+}
+
+// stringsFlag is a flag that can be given several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// admin makes a user an admin, who can moderate aliases suggested by other users, or revokes it with --revoke.
+//
+// Usage: admin [--revoke] USERNAME
+func admin(args []string) {
+	flags := flag.NewFlagSet("admin", flag.ExitOnError)
+	revoke := flags.Bool("revoke", false, "revoke admin from the user")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: admin [--revoke] USERNAME")
+		return
+	}
+
+	err := services.Start()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+
+	err = queries.SetAdmin(context.Background(), flags.Arg(0), !*revoke)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *revoke {
+		fmt.Println("Revoked admin from " + flags.Arg(0))
+	} else {
+		fmt.Println("Made " + flags.Arg(0) + " an admin")
+	}
+}
+
+// configureFetchClient sets up the client used by connectors, which needs services to be started if redis is used as cache.
+func configureFetchClient() {
+	cfg, err := httpclient.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	cfg.Cache, err = httpclient.CacheFromEnv(services.RDB)
+	if err != nil {
+		panic(err)
+	}
+	httpclient.SetDefault(httpclient.New(cfg))
+}
+
+func newScheduler(cfg scheduler.Config, connectorIDs []string) *scheduler.Scheduler {
+	for id, interval := range coreconnectors.Connectors.Intervals() {
+		cfg.Intervals[id] = interval
+	}
+	s := scheduler.New(cfg, connectorIDs, runner.RunConnector)
+	s.AfterBatch(runner.UpdateArtistSimilarities)
+	return s
+}
+
 func performMigration() {
 	migrations := &migrate.FileMigrationSource{
 		Dir: "./migrations",
@@ -106,39 +551,40 @@ func performMigration() {
 	n, err := migrate.Exec(db, "postgres", migrations, migrate.Up)
 	if err != nil {
 		fmt.Println(err)
+		return
 	}
 	fmt.Printf("Applied %d migrations!\n", n)
 
+	// artist keys are computed by util.ArtistKey, which SQL cannot do, so they are brought up to date after migrating
+	err = services.StartPool()
+	defer services.Stop()
+	if err != nil {
+		panic(err)
+	}
+	groups, err := queries.NormalizeArtists(context.Background(), false)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Normalized artist keys!")
+	if len(groups) != 0 {
+		fmt.Printf("Found %d groups of artists sharing a key, run `make normalize-artists merge=1` to merge them\n", len(groups))
+	}
+
 }
 
 func startServer() {
 	fs := http.FileServer(http.Dir("./web/static"))
 	http.Handle("/static/", http.StripPrefix("/static/", fs))
 
-	/*
-		ctx := context.Background()
-		for livehouse := range coreconnectors.Connectors {
-			fmt.Println("running " + livehouse)
-			err := runner.RunConnector(ctx, livehouse)
-			fmt.Println(err)
-		}*/
-
-	/*err := runner.RunConnector(context.Background(), "ShimokitazawaReg")
-	fmt.Println(err)*/
-	ctx := context.Background()
-	err := runner.RunConnector(ctx, "IkebukuroAdm")
-	fmt.Println(err)
-
-	/*
-		err := runner.RunConnector("ShinsaibashiKnave")
-		fmt.Println(err)
-
-			err = runner.RunConnector("ShimokitazawaShelter")
-			fmt.Println(err)
-				err = runner.RunConnector("ShinjukuLoft")
-				fmt.Println(err)
-				err = runner.RunConnector("ShinsaibashiBronze")
-				fmt.Println(err)*/
+	// run the crawler alongside the server unless it is deployed separately using the crawl command
+	if os.Getenv("CRAWLER_ENABLED") == "true" {
+		cfg, err := scheduler.ConfigFromEnv()
+		if err != nil {
+			panic(err)
+		}
+		go newScheduler(cfg, coreconnectors.Connectors.ScheduledIDs()).Run(context.Background())
+	}
 
 	router.Handle("/login", router.Methods{
 		GET: endpoints.ShowLogin,
@@ -157,7 +603,31 @@ func startServer() {
 		POST: endpoints.ChangePassword,
 	})
 	router.Handle("/api/savedsearch", router.Methods{
-		POST: endpoints.PostSavedSearch,
+		POST:   endpoints.PostSavedSearch,
+		DELETE: endpoints.DeleteSavedSearch,
+	})
+	router.Handle("/api/recommendations", router.Methods{
+		GET: endpoints.GetRecommendations,
+	})
+	router.Handle("/artist/{name}", router.Methods{
+		GET: endpoints.ShowArtist,
+	})
+	router.Handle("/api/artist/{name}", router.Methods{
+		PATCH: endpoints.PatchArtist,
+	})
+	router.Handle("/api/artistaliases", router.Methods{
+		GET:    endpoints.GetArtistAliases,
+		POST:   endpoints.SuggestArtistAlias,
+		DELETE: endpoints.DeleteArtistAlias,
+	})
+	router.Handle("/api/aliassuggestions/{id}/approve", router.Methods{
+		POST: endpoints.ApproveAliasSuggestion,
+	})
+	router.Handle("/api/aliassuggestions/{id}/reject", router.Methods{
+		POST: endpoints.RejectAliasSuggestion,
+	})
+	router.Handle("/moderation/aliases", router.Methods{
+		GET: endpoints.ShowAliasSuggestions,
 	})
 	router.Handle("/list/{id}", router.Methods{
 		GET: endpoints.ShowLiveList,
@@ -208,9 +678,15 @@ func startServer() {
 	router.Handle("/authorizeGoogleCalendar", router.Methods{
 		GET: endpoints.AuthorizeGoogleCalendar,
 	})
+	router.Handle("/status", router.Methods{
+		GET: endpoints.ShowConnectorStatus,
+	})
+	router.Handle("/status/{connector}", router.Methods{
+		GET: endpoints.ShowConnectorRuns,
+	})
 	router.Handle("/api/updateTestLive", router.Methods{
 		GET: func(au datastructures.AuthUser, w1 io.Writer, r *http.Request, w2 http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
-			err := runner.RunConnector(context.Background(), "ShimokitazawaTest")
+			err := runner.RunConnector(r.Context(), "ShimokitazawaTest")
 			if err != nil {
 				return nil, logging.SE(http.StatusInternalServerError, err.Error())
 			}
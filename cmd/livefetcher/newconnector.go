@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+)
+
+const newConnectorUsage = "Usage: newconnector --venue venue-id --prefecture prefecture --area area --url URL --lat latitude --lng longitude [--id ConnectorID] [--name Name]"
+
+// newConnector scaffolds a new connector, saving its test document and adding placeholder translations.
+//
+// Usage: newconnector --venue venue-id --prefecture prefecture --area area --url URL --lat latitude --lng longitude [--id ConnectorID] [--name Name]
+func newConnector(args []string) {
+	opts := coreconnectors.ScaffoldOptions{
+		ConnectorDir: coreconnectors.ConnectorDir(),
+		TestDir:      "test",
+		LocaleDir:    filepath.Join("internal", "i18n", "locales"),
+	}
+	flags := flag.NewFlagSet("newconnector", flag.ExitOnError)
+	flags.StringVar(&opts.ID, "id", "", "connector ID, derived from the venue ID if not set")
+	flags.StringVar(&opts.VenueID, "venue", "", "venue ID, such as kokura-fuse")
+	flags.StringVar(&opts.PrefectureName, "prefecture", "", "prefecture of the venue, such as fukuoka")
+	flags.StringVar(&opts.AreaName, "area", "", "area of the venue, such as kokura")
+	flags.StringVar(&opts.URL, "url", "", "schedule page of the venue, saved as test document")
+	flags.Float64Var(&opts.Latitude, "lat", 0, "latitude of the venue")
+	flags.Float64Var(&opts.Longitude, "lng", 0, "longitude of the venue")
+	flags.StringVar(&opts.Name, "name", "", "placeholder name of the venue in the locale files")
+	flags.Parse(args)
+	if opts.VenueID == "" || opts.PrefectureName == "" || opts.AreaName == "" || opts.URL == "" {
+		fmt.Println(newConnectorUsage)
+		return
+	}
+
+	// services are not started, so the client is created without the response cache
+	cfg, err := httpclient.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	opts.Client = httpclient.New(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	res, err := coreconnectors.Scaffold(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Created connector %s\n", res.ID)
+	fmt.Println("\tconnector: " + res.ConnectorFile)
+	fmt.Println("\ttest document: " + res.TestDocument)
+	for _, path := range res.LocaleFiles {
+		fmt.Println("\ttranslations: " + path)
+	}
+	fmt.Printf("Fill in the selectors and translations, then run make testconnector c=%s\n", res.ID)
+}
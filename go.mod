@@ -4,6 +4,7 @@ go 1.22.6
 
 require (
 	github.com/BurntSushi/toml v1.4.0
+	github.com/andybalholm/cascadia v1.3.2
 	github.com/antchfx/htmlquery v1.3.4
 	github.com/go-playground/form v3.1.4+incompatible
 	github.com/gojp/kana v0.1.0
@@ -14,6 +15,8 @@ require (
 	github.com/redis/go-redis/v9 v9.5.4
 	github.com/rubenv/sql-migrate v1.6.1
 	golang.org/x/net v0.35.0
+	golang.org/x/oauth2 v0.24.0
+	google.golang.org/api v0.214.0
 )
 
 require (
@@ -41,10 +44,8 @@ require (
 	go.opentelemetry.io/otel v1.29.0 // indirect
 	go.opentelemetry.io/otel/metric v1.29.0 // indirect
 	go.opentelemetry.io/otel/trace v1.29.0 // indirect
-	golang.org/x/oauth2 v0.24.0 // indirect
 	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	google.golang.org/api v0.214.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241209162323-e6fa225c2576 // indirect
 	google.golang.org/grpc v1.67.1 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
@@ -0,0 +1,287 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/logging"
+	"github.com/yayuyokitano/livefetcher/internal/core/queries"
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/templatebuilder"
+	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
+)
+
+type aliasRequest struct {
+	Artist string `form:"artist" json:"artist"`
+	Alias  string `form:"alias" json:"alias"`
+}
+
+// requireAdmin returns an error unless the user is an admin.
+func requireAdmin(user datastructures.AuthUser, r *http.Request) *logging.StatusError {
+	if user.Username == "" {
+		return logging.SE(http.StatusUnauthorized, i18nloader.GetLocalizer(r).Localize("error.please-log-in"))
+	}
+	admin, err := queries.IsAdmin(r.Context(), user.ID)
+	if err != nil {
+		return logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	if !admin {
+		return logging.SE(http.StatusForbidden, i18nloader.GetLocalizer(r).Localize("error.action-not-permitted"))
+	}
+	return nil
+}
+
+// artistError returns the status error of an error returned when viewing or changing artists and their aliases.
+func artistError(r *http.Request, err error) *logging.StatusError {
+	localizer := i18nloader.GetLocalizer(r)
+	switch {
+	case errors.Is(err, queries.ErrArtistNotFound):
+		return logging.SE(http.StatusNotFound, localizer.Localize("error.artist-not-found"))
+	case errors.Is(err, queries.ErrInvalidAlias):
+		return logging.SE(http.StatusBadRequest, localizer.Localize("error.invalid-alias"))
+	case errors.Is(err, queries.ErrInvalidArtistProfile):
+		return logging.SE(http.StatusBadRequest, localizer.Localize("error.invalid-artist-profile"))
+	case errors.Is(err, queries.ErrAliasExists):
+		return logging.SE(http.StatusConflict, localizer.Localize("error.alias-exists"))
+	case errors.Is(err, queries.ErrAliasSuggestionPending):
+		return logging.SE(http.StatusConflict, localizer.Localize("error.alias-suggestion-pending"))
+	case errors.Is(err, queries.ErrAliasNotFound), errors.Is(err, queries.ErrAliasSuggestionNotPending):
+		return logging.SE(http.StatusNotFound, localizer.Localize("error.not-found"))
+	}
+	return logging.SE(http.StatusInternalServerError, localizer.Localize("error.unknown-error")).SetInternalError(err)
+}
+
+type artistTemplateInput struct {
+	Artist    datastructures.Artist `json:"artist"`
+	Lives     datastructures.Lives  `json:"lives"`
+	PastLives datastructures.Lives  `json:"pastLives"`
+	IsAdmin   bool                  `json:"-"`
+}
+
+// ShowArtist shows an artist along with their upcoming lives, the latest of their past lives, and where and with whom
+// they play most.
+func ShowArtist(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	name := r.PathValue("name")
+	artist, err := queries.GetArtist(r.Context(), name, user.ID)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+
+	var pagination queries.Pagination
+	se := util.ParseForm(r, &pagination)
+	if se != nil {
+		return nil, se
+	}
+	if pagination.Limit == 0 {
+		pagination.Limit = 24
+	}
+	query := queries.LiveQuery{
+		ArtistName: artist.Name,
+		Pagination: pagination,
+	}
+	err = query.Validate()
+	if err != nil {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.parse-error")).SetInternalError(err)
+	}
+
+	calendarResults := util.GetCalendarData(r.Context(), user)
+
+	lives, err := queries.GetLives(r.Context(), query, user, r)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	pastLives, err := queries.GetLives(r.Context(), queries.LiveQuery{
+		ArtistName:      artist.Name,
+		IncludeOldLives: true,
+		To:              time.Now(),
+		Pagination: queries.Pagination{
+			Sort:  queries.LiveSortLatest,
+			Limit: 12,
+		},
+	}, user, r)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	admin, err := queries.IsAdmin(r.Context(), user.ID)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	calendarEvents := <-calendarResults
+
+	lp := filepath.Join("web", "template", "layout.gohtml")
+	fp := filepath.Join("web", "template", "artist.gohtml")
+	favoriteButtonPartial := filepath.Join("web", "template", "partials", "favoriteButton.gohtml")
+	livesPartial := filepath.Join("web", "template", "partials", "lives.gohtml")
+	livePartial := filepath.Join("web", "template", "partials", "live.gohtml")
+	tmpl, err := templatebuilder.Build(w, r, user, template.FuncMap{
+		"ArtistTitle": func() string {
+			return i18nloader.GetLocalizer(r).Localize("artist.title", "Artist", artist.Name)
+		},
+		"GetCalendarEvents": func() string {
+			return calendarEvents.ToDataMapString()
+		},
+	}, lp, fp, favoriteButtonPartial, livesPartial, livePartial)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	return &datastructures.Response{
+		Template: tmpl,
+		Data: artistTemplateInput{
+			Artist:    artist,
+			Lives:     lives,
+			PastLives: pastLives,
+			IsAdmin:   admin,
+		},
+	}, nil
+}
+
+// PatchArtist replaces the link, description and social links of an artist.
+func PatchArtist(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	var profile datastructures.ArtistProfile
+	se = util.ParseForm(r, &profile)
+	if se != nil {
+		return nil, se
+	}
+
+	err := queries.UpdateArtistProfile(r.Context(), r.PathValue("name"), profile)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+	w.Write([]byte(i18nloader.GetLocalizer(r).Localize("artist.profile-saved")))
+	return nil, nil
+}
+
+// GetArtistAliases returns the aliases of an artist along with where they come from as JSON.
+func GetArtistAliases(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	artist := r.FormValue("artist")
+	if artist == "" {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.missing-parameter"))
+	}
+
+	aliases, err := queries.GetArtistAliases(r.Context(), artist)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+	b, err := json.Marshal(aliases)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.marshal-error")).SetInternalError(err)
+	}
+	w.Write(b)
+	return nil, nil
+}
+
+// SuggestArtistAlias suggests an alias for an artist, which is added right away if suggested by an admin.
+func SuggestArtistAlias(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	if user.Username == "" {
+		return nil, logging.SE(http.StatusUnauthorized, i18nloader.GetLocalizer(r).Localize("error.please-log-in"))
+	}
+
+	var req aliasRequest
+	se := util.ParseForm(r, &req)
+	if se != nil {
+		return nil, se
+	}
+	if req.Artist == "" || req.Alias == "" {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.missing-parameter"))
+	}
+
+	added, err := queries.SuggestAlias(r.Context(), user, req.Artist, req.Alias)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+	if added {
+		w.Write([]byte(i18nloader.GetLocalizer(r).Localize("artist.alias-added")))
+	} else {
+		w.Write([]byte(i18nloader.GetLocalizer(r).Localize("artist.alias-suggested")))
+	}
+	return nil, nil
+}
+
+// DeleteArtistAlias removes an alias from an artist.
+func DeleteArtistAlias(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	var req aliasRequest
+	se = util.ParseForm(r, &req)
+	if se != nil {
+		return nil, se
+	}
+
+	err := queries.DeleteAlias(r.Context(), req.Artist, req.Alias)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+	return nil, nil
+}
+
+// ShowAliasSuggestions shows the alias suggestions waiting for moderation.
+func ShowAliasSuggestions(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	suggestions, err := queries.GetAliasSuggestions(r.Context(), datastructures.AliasSuggestionStatusPending)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	lp := filepath.Join("web", "template", "layout.gohtml")
+	fp := filepath.Join("web", "template", "aliassuggestions.gohtml")
+	tmpl, err := templatebuilder.Build(w, r, user, template.FuncMap{}, lp, fp)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	return &datastructures.Response{
+		Template: tmpl,
+		Data:     suggestions,
+	}, nil
+}
+
+// ApproveAliasSuggestion adds a suggested alias to its artist.
+func ApproveAliasSuggestion(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	return resolveAliasSuggestion(user, w, r, queries.ApproveAliasSuggestion, "artist.alias-suggestion-approved")
+}
+
+// RejectAliasSuggestion discards a suggested alias.
+func RejectAliasSuggestion(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	return resolveAliasSuggestion(user, w, r, queries.RejectAliasSuggestion, "artist.alias-suggestion-rejected")
+}
+
+func resolveAliasSuggestion(user datastructures.AuthUser, w io.Writer, r *http.Request, resolve func(ctx context.Context, id int, moderatorID int) error, message string) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id == 0 {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.not-found"))
+	}
+
+	err = resolve(r.Context(), id, user.ID)
+	if err != nil {
+		return nil, artistError(r, err)
+	}
+	w.Write([]byte(i18nloader.GetLocalizer(r).Localize(message)))
+	return nil, nil
+}
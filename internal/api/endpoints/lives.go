@@ -34,6 +34,9 @@ func searchTitle(query queries.LiveQuery, r *http.Request, suffix string) string
 	if query.Artist != "" {
 		return i18nloader.GetLocalizer(r).Localize("general.search-artist-"+suffix, "Artist", query.Artist)
 	}
+	if query.Search != "" {
+		return i18nloader.GetLocalizer(r).Localize("general.search-keyword-"+suffix, "Keyword", query.Search)
+	}
 	return i18nloader.GetLocalizer(r).Localize("general.main-" + suffix)
 }
 
@@ -46,6 +49,10 @@ func GetLives(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http
 	if query.Limit == 0 {
 		query.Limit = 24
 	}
+	err := query.Validate()
+	if err != nil {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.parse-error")).SetInternalError(err)
+	}
 
 	calendarResults := util.GetCalendarData(r.Context(), user)
 
@@ -186,6 +193,24 @@ func GetFavoriteLives(user datastructures.AuthUser, w io.Writer, r *http.Request
 	}, nil
 }
 
+// GetRecommendations returns the upcoming lives recommended to the user as JSON, along with why they are recommended.
+func GetRecommendations(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	if user.Username == "" {
+		return nil, logging.SE(http.StatusUnauthorized, i18nloader.GetLocalizer(r).Localize("error.please-log-in"))
+	}
+
+	recommendations, err := queries.GetRecommendations(r.Context(), user, r)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	b, err := json.Marshal(recommendations)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.marshal-error")).SetInternalError(err)
+	}
+	w.Write(b)
+	return nil, nil
+}
+
 func GetDailyLivesJSON(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
 	year, err := strconv.Atoi(r.PathValue("year"))
 	if err != nil {
@@ -200,7 +225,16 @@ func GetDailyLivesJSON(user datastructures.AuthUser, w io.Writer, r *http.Reques
 		return nil, logging.SE(http.StatusBadRequest, "unknown-error")
 	}
 
+	// the map passes its viewport and center to only fetch the lives in view, nearest first
 	var query queries.LiveQuery
+	se := util.ParseForm(r, &query)
+	if se != nil {
+		return nil, se
+	}
+	err = query.Validate()
+	if err != nil {
+		return nil, logging.SE(http.StatusBadRequest, "error.parse-error").SetInternalError(err)
+	}
 	query.From = time.Date(year, time.Month(month), day, 2, 0, 0, 0, util.JapanTime)
 	query.To = query.From.Add(24 * time.Hour)
 
@@ -248,12 +282,17 @@ func PostSavedSearch(user datastructures.AuthUser, w io.Writer, r *http.Request,
 		return nil, se
 	}
 
+	err := query.Validate()
+	if err != nil {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.parse-error")).SetInternalError(err)
+	}
+
 	if (query.Artist == "" || query.Artist == `""`) && len(query.AdditionalArtists) == 0 {
 		return nil, logging.SE(http.StatusBadRequest, "please enter an artist search")
 	}
 
 	if query.Artist != "" {
-		err := queries.PostSavedSearch(r.Context(), query.Artist, query.AllowAllLocations, user, r)
+		err := queries.PostSavedSearch(r.Context(), query.Artist, query.AllowAllLocations, query.NightFilters, user, r)
 		if err != nil {
 			return nil, logging.SE(http.StatusInternalServerError, "unknown-error").SetInternalError(err)
 		}
@@ -261,7 +300,7 @@ func PostSavedSearch(user datastructures.AuthUser, w io.Writer, r *http.Request,
 		return nil, nil
 	} else if len(query.AdditionalArtists) > 0 {
 		for artist := range query.AdditionalArtists {
-			err := queries.PostSavedSearch(r.Context(), fmt.Sprintf(`"%s"`, artist), query.AllowAllLocations, user, r)
+			err := queries.PostSavedSearch(r.Context(), fmt.Sprintf(`"%s"`, artist), query.AllowAllLocations, query.NightFilters, user, r)
 			if err != nil {
 				return nil, logging.SE(http.StatusInternalServerError, "unknown-error").SetInternalError(err)
 			}
@@ -272,6 +311,28 @@ func PostSavedSearch(user datastructures.AuthUser, w io.Writer, r *http.Request,
 	return nil, logging.SE(http.StatusForbidden, i18nloader.GetLocalizer(r).Localize("error.refresh-error"))
 }
 
+// DeleteSavedSearch deletes the saved search of the user for the artist searched.
+func DeleteSavedSearch(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	if user.Username == "" {
+		return nil, logging.SE(http.StatusForbidden, i18nloader.GetLocalizer(r).Localize("error.refresh-error"))
+	}
+
+	var query queries.LiveQuery
+	se := util.ParseForm(r, &query)
+	if se != nil {
+		return nil, se
+	}
+	if query.Artist == "" || query.Artist == `""` {
+		return nil, logging.SE(http.StatusBadRequest, i18nloader.GetLocalizer(r).Localize("error.missing-parameter"))
+	}
+
+	err := queries.DeleteSavedSearch(r.Context(), query.Artist, user)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	return nil, nil
+}
+
 func AddToCalendar(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
 	if user.Username == "" {
 		return nil, logging.SE(http.StatusUnauthorized, i18nloader.GetLocalizer(r).Localize("error.refresh-error"))
@@ -0,0 +1,125 @@
+package endpoints
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"time"
+
+	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
+	"github.com/yayuyokitano/livefetcher/internal/core/logging"
+	"github.com/yayuyokitano/livefetcher/internal/core/queries"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/templatebuilder"
+	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
+)
+
+// number of runs shown in the run history of a single connector
+const connectorRunHistoryLength = 50
+
+var connectorStatusOrder = map[datastructures.ConnectorStatus]int{
+	datastructures.ConnectorStatusBroken:   0,
+	datastructures.ConnectorStatusDegraded: 1,
+	datastructures.ConnectorStatusUnknown:  2,
+	datastructures.ConnectorStatusHealthy:  3,
+}
+
+func statusFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"FormatDuration": func(d time.Duration) string {
+			return d.Round(time.Second).String()
+		},
+		"FormatCacheHits": func(run datastructures.ConnectorRun) string {
+			rate := run.CacheHitRate()
+			if rate < 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d/%d (%.0f%%)", run.CacheHits, run.CacheHits+run.CacheMisses, rate*100)
+		},
+	}
+}
+
+// ShowConnectorStatus shows the health of every connector. Runs record internal errors, so only admins can see them.
+func ShowConnectorStatus(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	venues := make(map[string]string)
+	for connectorID, connector := range coreconnectors.Connectors {
+		venues[connectorID] = connector.VenueID
+	}
+
+	health, err := queries.GetConnectorHealth(r.Context(), venues)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	// show the connectors needing attention first
+	slices.SortFunc(health, func(a, b datastructures.ConnectorHealth) int {
+		return cmp.Or(
+			cmp.Compare(connectorStatusOrder[a.Status], connectorStatusOrder[b.Status]),
+			cmp.Compare(a.ConnectorID, b.ConnectorID),
+		)
+	})
+
+	lp := filepath.Join("web", "template", "layout.gohtml")
+	fp := filepath.Join("web", "template", "status.gohtml")
+	tmpl, err := templatebuilder.Build(w, r, user, statusFuncMap(), lp, fp)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	return &datastructures.Response{
+		Template: tmpl,
+		Data:     health,
+	}, nil
+}
+
+// ShowConnectorRuns shows the run history of a connector to admins.
+func ShowConnectorRuns(user datastructures.AuthUser, w io.Writer, r *http.Request, _ http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
+	se := requireAdmin(user, r)
+	if se != nil {
+		return nil, se
+	}
+
+	connectorID := r.PathValue("connector")
+	connector, ok := coreconnectors.Connectors[connectorID]
+	if !ok {
+		return nil, logging.SE(http.StatusNotFound, i18nloader.GetLocalizer(r).Localize("error.not-found"))
+	}
+
+	runs, err := queries.GetConnectorRuns(r.Context(), connectorID, connectorRunHistoryLength)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+	health := datastructures.ConnectorHealth{
+		ConnectorID: connectorID,
+		VenueID:     connector.VenueID,
+		Status:      queries.EvaluateConnectorHealth(runs[:min(len(runs), queries.ConnectorHealthWindow)]),
+		RecentRuns:  runs,
+	}
+	if len(runs) > 0 {
+		health.LastRun = &runs[0]
+	}
+
+	funcMap := statusFuncMap()
+	funcMap["ConnectorID"] = func() string {
+		return connectorID
+	}
+	lp := filepath.Join("web", "template", "layout.gohtml")
+	fp := filepath.Join("web", "template", "connectorruns.gohtml")
+	tmpl, err := templatebuilder.Build(w, r, user, funcMap, lp, fp)
+	if err != nil {
+		return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+	}
+
+	return &datastructures.Response{
+		Template: tmpl,
+		Data:     health,
+	}, nil
+}
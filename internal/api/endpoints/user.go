@@ -238,7 +238,8 @@ func ShowSettings(user datastructures.AuthUser, w io.Writer, r *http.Request, ht
 }
 
 type dashboardTemplateInput struct {
-	SavedLives datastructures.Lives
+	SavedLives      datastructures.Lives
+	Recommendations []datastructures.Recommendation
 }
 
 func ShowDashboard(user datastructures.AuthUser, w io.Writer, r *http.Request, httpWriter http.ResponseWriter) (*datastructures.Response, *logging.StatusError) {
@@ -279,17 +280,23 @@ func ShowDashboard(user datastructures.AuthUser, w io.Writer, r *http.Request, h
 	}
 
 	var savedLives datastructures.Lives
+	var recommendations []datastructures.Recommendation
 	if user.ID != 0 {
 		savedLives, err = queries.GetLives(r.Context(), queries.LiveQuery{SavedSearchUserId: user.ID}, user, r)
 		if err != nil {
 			return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
 		}
+		recommendations, err = queries.GetRecommendations(r.Context(), user, r)
+		if err != nil {
+			return nil, logging.SE(http.StatusInternalServerError, i18nloader.GetLocalizer(r).Localize("error.unknown-error")).SetInternalError(err)
+		}
 	}
 
 	return &datastructures.Response{
 		Template: tmpl,
 		Data: dashboardTemplateInput{
-			SavedLives: savedLives,
+			SavedLives:      savedLives,
+			Recommendations: recommendations,
 		},
 	}, nil
 }
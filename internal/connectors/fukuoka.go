@@ -62,39 +62,3 @@ var ZeppFukuokaFetcher = fetchers.CreateZeppFetcher("fukuoka", "fukuoka", "fukuo
 	FirstLiveStartTime:    time.Date(2025, 4, 4, 19, 0, 0, 0, util.JapanTime),
 	FirstLiveURL:          "https://www.zepp.co.jp/hall/fukuoka/schedule/single/?rid=146915",
 })
-
-var KokuraFuseFetcher = fetchers.Simple{
-	BaseURL:              "https://kokurafuse.com/",
-	ShortYearIterableURL: "https://kokurafuse.com/monthly/?d=20%d-%02d-01",
-	LiveSelector:         "//article[@class='schedule-item']",
-	DetailsLinkSelector:  "//a",
-	TitleQuerier:         *htmlquerier.Q("//h2"),
-	ArtistsQuerier:       *htmlquerier.Q("//dl[@class='event__cast']/dd").Split(" / "),
-	PriceQuerier:         *htmlquerier.Q("//dl[@class='event__price']/dd").NormalizeWhitespace(),
-
-	TimeHandler: fetchers.TimeHandler{
-		YearQuerier:      *htmlquerier.Q("//h3[@class='content-title']/small"),
-		MonthQuerier:     *htmlquerier.Q("//h3[@class='content-title']/small").After("年"),
-		DayQuerier:       *htmlquerier.Q("//span[@class='event__date-day']"),
-		OpenTimeQuerier:  *htmlquerier.Q("//dl[@class='event__time']//dd"),
-		StartTimeQuerier: *htmlquerier.Q("//dl[@class='event__time']//dd").After("開演"),
-	},
-
-	PrefectureName: "fukuoka",
-	AreaName:       "kokura",
-	VenueID:        "kokura-fuse",
-	Latitude:       33.886437,
-	Longitude:      130.879937,
-	RequireArtists: true,
-
-	TestInfo: fetchers.TestInfo{
-		NumberOfLives:         24,
-		FirstLiveTitle:        `MUZIC PUMP`,
-		FirstLiveArtists:      []string{"PSYCO LOGIC BOX", "BADWHY’s", "reo goble and his band", "珊々瑚々", "dop", "THEBIGDIPPER"},
-		FirstLivePrice:        "前売 2,500円 / 当日 3,000円",
-		FirstLivePriceEnglish: "Reservation 2,500円 / Door 3,000円",
-		FirstLiveOpenTime:     time.Date(2025, 3, 1, 17, 30, 0, 0, util.JapanTime),
-		FirstLiveStartTime:    time.Date(2025, 3, 1, 18, 0, 0, 0, util.JapanTime),
-		FirstLiveURL:          "https://kokurafuse.com/schedule/schedule2697/",
-	},
-}
@@ -1,9 +1,8 @@
 package connectors
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"regexp"
 	"strings"
 	"time"
@@ -11,6 +10,7 @@ import (
 	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
 	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
 	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 )
 
 /******************
@@ -411,15 +411,10 @@ var ShinsaibashiJanusFetcher = fetchers.Simple{
 	},
 }
 
+// shinsaibashiKanonGetSecurity runs during package initialization, before the fetch configuration is loaded,
+// so it uses its own client with the default configuration rather than the shared one.
 func shinsaibashiKanonGetSecurity() string {
-	client := &http.Client{Timeout: 10 * time.Second}
-	r, err := client.Get("https://kanon-art.jp/schedule/")
-	if err != nil {
-		return ""
-	}
-	defer r.Body.Close()
-
-	s, err := io.ReadAll(r.Body)
+	s, err := httpclient.New(httpclient.DefaultConfig()).GetBytes(context.Background(), "https://kanon-art.jp/schedule/")
 	if err != nil {
 		return ""
 	}
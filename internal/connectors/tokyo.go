@@ -1,6 +1,7 @@
 package connectors
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
 	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
 	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 	"golang.org/x/net/html"
 )
 
@@ -44,6 +46,8 @@ var ShimokitazawaTestFetcher = fetchers.Simple{
 	Latitude:       35.661563,
 	Longitude:      139.666938,
 
+	ExcludeFromSchedule: true,
+
 	TestInfo: fetchers.TestInfo{
 		IgnoreTest: true,
 	},
@@ -92,11 +96,12 @@ type kichijojiBlackAndBlueResponse []struct {
 
 var KichijojiBlackAndBlueFetcher = fetchers.Simple{
 	BaseURL: "https://blackandblue.tokyo/",
-	LiveHTMLFetcher: func(testDocument []byte) (nodes []*html.Node, err error) {
+	LiveHTMLFetcher: func(ctx context.Context, client *httpclient.Client, testDocument []byte) (nodes []*html.Node, err error) {
 		nodes = make([]*html.Node, 0)
 		var list kichijojiBlackAndBlueResponse
 		if testDocument == nil {
-			if err = util.GetJSON(
+			if err = client.GetJSON(
+				ctx,
 				util.InsertYearMonth("https://blackandblue.tokyo/wp-admin/admin-ajax.php?action=eventorganiser-fullcal&start=%d-%02d-01&timeformat=g%3Ai%20A"),
 				&list,
 			); err != nil {
@@ -887,7 +892,7 @@ func appendFlattenedStrobeList(a *[]shibuyaStrobeFlatElement, listResponse shibu
 	}
 }
 
-func getShibuyaStrobeLiveList(testDocument []byte) (list []shibuyaStrobeFlatElement) {
+func getShibuyaStrobeLiveList(ctx context.Context, client *httpclient.Client, testDocument []byte) (list []shibuyaStrobeFlatElement) {
 	list = make([]shibuyaStrobeFlatElement, 0)
 	if testDocument != nil {
 		var res shibuyaStrobeListResponse
@@ -904,7 +909,8 @@ func getShibuyaStrobeLiveList(testDocument []byte) (list []shibuyaStrobeFlatElem
 		for len(list) != prevLength {
 			prevLength = len(list)
 			var res shibuyaStrobeListResponse
-			if err := util.GetJSON(
+			if err := client.GetJSON(
+				ctx,
 				fmt.Sprintf("https://www.strobe-cafe.com/schedule/get-data-schedule.php?y=20%d&m=%02d", year, month),
 				&res,
 			); err != nil {
@@ -937,9 +943,9 @@ func createShibuyaStrobeHtml(live shibuyaStrobeFlatElement) string {
 // TODO: this is in harajuku
 var ShibuyaStrobeFetcher = fetchers.Simple{
 	BaseURL: "https://www.strobe-cafe.com/",
-	LiveHTMLFetcher: func(testDocument []byte) (nodes []*html.Node, err error) {
+	LiveHTMLFetcher: func(ctx context.Context, client *httpclient.Client, testDocument []byte) (nodes []*html.Node, err error) {
 		nodes = make([]*html.Node, 0)
-		list := getShibuyaStrobeLiveList(testDocument)
+		list := getShibuyaStrobeLiveList(ctx, client, testDocument)
 		for _, live := range list {
 			node, err := html.Parse(strings.NewReader(
 				createShibuyaStrobeHtml(live),
@@ -1038,11 +1044,12 @@ func shibuyaTokioTokyoGetBody(body, title string) string {
 // please never break i do not want to ever deal with this again what the fuck is this abomination
 var ShibuyaTokioTokyoFetcher = fetchers.Simple{
 	BaseURL: "https://tokio.world/",
-	LiveHTMLFetcher: func(testDocument []byte) (nodes []*html.Node, err error) {
+	LiveHTMLFetcher: func(ctx context.Context, client *httpclient.Client, testDocument []byte) (nodes []*html.Node, err error) {
 		nodes = make([]*html.Node, 0)
 		var list shibuyaTokioTokyoListResponse
 		if testDocument == nil {
-			if err = util.GetJSON(
+			if err = client.GetJSON(
+				ctx,
 				"https://api.cms.studiodesignapp.com/documents:runQuery?q=eyJzdHJ1Y3R1cmVkUXVlcnkiOnsiZnJvbSI6W3siY29sbGVjdGlvbklkIjoicHVibGlzaGVkIiwiYWxsRGVzY2VuZGFudHMiOnRydWV9XSwid2hlcmUiOnsiY29tcG9zaXRlRmlsdGVyIjp7Im9wIjoiQU5EIiwiZmlsdGVycyI6W3siZmllbGRGaWx0ZXIiOnsiZmllbGQiOnsiZmllbGRQYXRoIjoiX21ldGEucHJvamVjdC5pZCJ9LCJvcCI6IkVRVUFMIiwidmFsdWUiOnsic3RyaW5nVmFsdWUiOiIyNGMyMTZkOTUwY2U0OTY5YWU2ZiJ9fX0seyJmaWVsZEZpbHRlciI6eyJmaWVsZCI6eyJmaWVsZFBhdGgiOiJfbWV0YS5zY2hlbWEua2V5In0sIm9wIjoiRVFVQUwiLCJ2YWx1ZSI6eyJzdHJpbmdWYWx1ZSI6InplQ2FyaG5yIn19fV19fSwib3JkZXJCeSI6W3siZmllbGQiOnsiZmllbGRQYXRoIjoiX21ldGEucHVibGlzaGVkQXQifSwiZGlyZWN0aW9uIjoiREVTQ0VORElORyJ9XSwibGltaXQiOjF9fQ%3D%3D",
 				&list,
 			); err != nil {
@@ -1055,7 +1062,8 @@ var ShibuyaTokioTokyoFetcher = fetchers.Simple{
 		}
 		for _, liveReference := range list[0].Document.Fields.Default.MapValue.Fields.List.ArrayValue.Values {
 			var live shibuyaTokioTokyoLiveJSON
-			if err = util.GetJSON(
+			if err = client.GetJSON(
+				ctx,
 				strings.Replace(liveReference.ReferenceValue, "projects/studio-7e371/databases/(default)/", "https://api.cms.studiodesignapp.com/", 1),
 				&live,
 			); err != nil {
@@ -1103,8 +1111,9 @@ var ShibuyaTokioTokyoFetcher = fetchers.Simple{
 		FirstLiveArtists:      []string{"BESPER", "LUKA", "佐野諒太", "浜野はるき", "灯橙あか"},
 		FirstLivePrice:        "このライブハウスのイベントの値段にアクセスできません。ライブのリンクをチェックしてください。",
 		FirstLivePriceEnglish: "Cannot access prices for lives at this venue. Please check live link.",
-		FirstLiveOpenTime:     time.Date(2024, 3, 12, 03, 24, 0, 0, util.JapanTime),
-		FirstLiveStartTime:    time.Date(2024, 3, 12, 03, 24, 0, 0, util.JapanTime),
+		FirstLiveOpenTime:     time.Date(2024, 3, 12, 0, 0, 0, 0, util.JapanTime),
+		FirstLiveStartTime:    time.Date(2024, 3, 12, 0, 0, 0, 0, util.JapanTime),
+		FirstLiveTimeUnknown:  true,
 		FirstLiveURL:          "https://tokio.world/posts/OMwpVbLU",
 	},
 }
@@ -2098,7 +2107,7 @@ var ShinjukuLoftFetcher = fetchers.CreateLoftFetcher(
 
 var ShinjukuMarbleFetcher = fetchers.Simple{
 	BaseURL: "https://shinjuku-marble.com/",
-	LiveHTMLFetcher: func(testDocument []byte) (nodes []*html.Node, err error) {
+	LiveHTMLFetcher: func(ctx context.Context, client *httpclient.Client, testDocument []byte) (nodes []*html.Node, err error) {
 		nodes = make([]*html.Node, 0)
 		var newNodes []*html.Node
 		offset := 0
@@ -2106,10 +2115,9 @@ var ShinjukuMarbleFetcher = fetchers.Simple{
 		hasMoreEvent := true
 		if testDocument == nil {
 			for i := 0; i < 20 && hasMoreEvent; i++ {
-				client := &http.Client{Timeout: 10 * time.Second}
 				reqBody := fmt.Sprintf("action=mec_grid_load_more&mec_start_date=%s&mec_offset=%d&atts%%5Bsk-options%%5D%%5Bgrid%%5D%%5Bstyle%%5D=classic&atts%%5Bid%%5D=314&apply_sf_date=0", endDate, offset)
 				var req *http.Request
-				req, err = http.NewRequest("POST", "https://shinjuku-marble.com/wp-admin/admin-ajax.php", strings.NewReader(reqBody))
+				req, err = http.NewRequestWithContext(ctx, "POST", "https://shinjuku-marble.com/wp-admin/admin-ajax.php", strings.NewReader(reqBody))
 				if err != nil {
 					return
 				}
@@ -2119,20 +2127,20 @@ var ShinjukuMarbleFetcher = fetchers.Simple{
 				if err != nil {
 					return
 				}
-				defer res.Body.Close()
 
 				var b []byte
 				b, err = io.ReadAll(res.Body)
+				res.Body.Close()
 				if err != nil {
 					return
 				}
-				newNodes, offset, endDate, hasMoreEvent = parseShinjukuMarbleResponse(b)
+				newNodes, offset, endDate, hasMoreEvent = parseShinjukuMarbleResponse(ctx, client, b)
 				if newNodes != nil {
 					nodes = append(nodes, newNodes...)
 				}
 			}
 		} else {
-			newNodes, _, _, _ = parseShinjukuMarbleResponse(testDocument)
+			newNodes, _, _, _ = parseShinjukuMarbleResponse(ctx, client, testDocument)
 			nodes = newNodes
 		}
 		return
@@ -2175,7 +2183,7 @@ type ShinjukuMarbleResponse struct {
 	Offset       int    `json:"offset"`
 }
 
-func parseShinjukuMarbleResponse(s []byte) (nodes []*html.Node, offset int, endDate string, hasMoreEvent bool) {
+func parseShinjukuMarbleResponse(ctx context.Context, client *httpclient.Client, s []byte) (nodes []*html.Node, offset int, endDate string, hasMoreEvent bool) {
 	nodes = make([]*html.Node, 0)
 	var res ShinjukuMarbleResponse
 	if err := json.Unmarshal(s, &res); err != nil {
@@ -2210,7 +2218,7 @@ func parseShinjukuMarbleResponse(s []byte) (nodes []*html.Node, offset int, endD
 	close(queue)
 	for i := 0; i < min(10, len(links)); i++ {
 		wg.Add(1)
-		go fetchers.FetchLiveConcurrent(baseUrl, queue, "//a", &wg)
+		go fetchers.FetchLiveConcurrent(ctx, client, baseUrl, queue, "//a", &wg)
 	}
 	wg.Wait()
 	for _, liveDetails := range liveSlice {
@@ -1,6 +1,8 @@
 package coreconnectors
 
 import (
+	"time"
+
 	"github.com/yayuyokitano/livefetcher/internal/connectors"
 	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
 )
@@ -26,7 +28,6 @@ var Connectors = ConnectorsType{
 	"KoenjiClubRoots":       connectors.KoenjiClubRootsFetcher,
 	"KoenjiHigh":            connectors.KoenjiHighFetcher,
 	"KoenjiShowBoat":        connectors.KoenjiShowBoatFetcher,
-	"KokuraFuse":            connectors.KokuraFuseFetcher,
 	"ShinsaibashiSinkagura": connectors.ShinsaibashiSinkaguraFetcher,
 	"SangenjayaHeavensDoor": connectors.SangenjayaHeavensDoorFetcher,
 	"TakadanobabaClubPhase": connectors.TakadanobabaClubPhaseFetcher,
@@ -113,3 +114,26 @@ var Connectors = ConnectorsType{
 	"ShinsaibashiVaron":           connectors.ShinsaibashiVaronFetcher,
 	"YoyogiBarbara":               connectors.YoyogiBarbaraFetcher,
 }
+
+// ScheduledIDs returns the IDs of all connectors that should be run by the scheduler.
+// Connectors excluded from the schedule, such as test connectors, are only ever run manually.
+func (c ConnectorsType) ScheduledIDs() (ids []string) {
+	for id, connector := range c {
+		if connector.ExcludeFromSchedule {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return
+}
+
+// Intervals returns the crawl intervals of all connectors that override the scheduler default.
+func (c ConnectorsType) Intervals() map[string]time.Duration {
+	intervals := make(map[string]time.Duration)
+	for id, connector := range c {
+		if connector.CrawlInterval > 0 {
+			intervals[id] = connector.CrawlInterval
+		}
+	}
+	return intervals
+}
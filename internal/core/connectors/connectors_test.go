@@ -3,6 +3,7 @@ package coreconnectors
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"testing"
 
@@ -19,6 +20,15 @@ type Translation struct {
 
 var translations map[string]Translation
 
+func TestMain(m *testing.M) {
+	err := LoadConnectorFiles("../../../connectors")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
 func TestConnectors(t *testing.T) {
 	initTranslations(t)
 	testResults := make(chan util.ConnectorTestResult, 1)
@@ -63,6 +73,19 @@ func TestConnectors(t *testing.T) {
 	fmt.Println(res.String())
 }
 
+func TestScheduledIDs(t *testing.T) {
+	ids := Connectors.ScheduledIDs()
+	if slices.Contains(ids, "ShimokitazawaTest") {
+		t.Error("expected test connector to be excluded from the schedule")
+	}
+	// connectors whose tests are skipped are still crawled
+	for _, id := range []string{"ShibuyaDive", "ShibuyaWWWBeta", "ShinsaibashiQupe"} {
+		if !slices.Contains(ids, id) {
+			t.Errorf("expected %s to be scheduled", id)
+		}
+	}
+}
+
 func executeConnectorTest(t *testing.T, connector fetchers.Simple, wg *sync.WaitGroup, errorCreator *util.ConnectorTestErrorCreator) {
 	for lang, translation := range translations {
 		if translation.Prefectures[connector.PrefectureName] == "" {
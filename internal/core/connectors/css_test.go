@@ -0,0 +1,145 @@
+package coreconnectors
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+)
+
+// cssVariant returns a copy of connector with every selector replaced by its CSS equivalent in css.
+// Filters and all other settings are left untouched, so that the copy only differs in how nodes are selected.
+func cssVariant(t *testing.T, connector fetchers.Simple, css map[string]string) fetchers.Simple {
+	t.Helper()
+	replace := func(selector string) string {
+		if selector == "" {
+			return ""
+		}
+		replacement, ok := css[selector]
+		if !ok {
+			t.Fatalf("no CSS equivalent of selector %q", selector)
+		}
+		return replacement
+	}
+	for _, selector := range []*string{
+		&connector.LiveSelector,
+		&connector.DetailsLinkSelector,
+		&connector.MultiLiveDaySelector,
+		&connector.ExpandedLiveSelector,
+		&connector.ExpandedLiveGroupSelector,
+		&connector.NextSelector,
+	} {
+		*selector = replace(*selector)
+	}
+	for _, q := range []*htmlquerier.Querier{
+		&connector.TitleQuerier,
+		&connector.ArtistsQuerier,
+		&connector.DetailQuerier,
+		&connector.PriceQuerier,
+		&connector.TimeHandler.YearQuerier,
+		&connector.TimeHandler.MonthQuerier,
+		&connector.TimeHandler.DayQuerier,
+		&connector.TimeHandler.DateQuerier,
+		&connector.TimeHandler.OpenTimeQuerier,
+		&connector.TimeHandler.StartTimeQuerier,
+	} {
+		if q.Initialized {
+			*q = q.WithSelector(replace(q.Selector()))
+		}
+	}
+	return connector
+}
+
+func TestCSSConnectorParity(t *testing.T) {
+	cases := []struct {
+		connector string
+		css       map[string]string
+	}{
+		{
+			connector: "KokuraFuse",
+			css: map[string]string{
+				"//article[@class='schedule-item']":  "css:article[class='schedule-item']",
+				"//a":                                "css:a",
+				"//h2":                               "css:h2",
+				"//dl[@class='event__cast']/dd":      "css:dl[class='event__cast'] > dd",
+				"//dl[@class='event__price']/dd":     "css:dl[class='event__price'] > dd",
+				"//h3[@class='content-title']/small": "css:h3[class='content-title'] > small",
+				"//span[@class='event__date-day']":   "css:span[class='event__date-day']",
+				"//dl[@class='event__time']//dd":     "css:dl[class='event__time'] dd",
+			},
+		},
+		{
+			connector: "KichijojiBlackAndBlue",
+			css: map[string]string{
+				"//span[@id='url']":   "css:span#url",
+				"//span[@id='title']": "css:span#title",
+				"//span[@id='body']":  "css:span#body",
+				"//span[@id='date']":  "css:span#date",
+			},
+		},
+		{
+			connector: "ShindaitaFever",
+			css: map[string]string{
+				"//div[@id='mekuri']/a[2]":             "css:div#mekuri > a:nth-of-type(2)",
+				"//div[contains(@class, 'hentry')]":    "css:div.hentry",
+				"//h2[contains(@class, 'eventtitle')]": "css:h2.eventtitle",
+				"//h3/p":                               "css:h3 > p",
+				"//div[2]/div[1]/div[3]":               "css:div:nth-of-type(2) > div:nth-of-type(1) > div:nth-of-type(3)",
+				"//div[2]/div[1]/div[2]":               "css:div:nth-of-type(2) > div:nth-of-type(1) > div:nth-of-type(2)",
+			},
+		},
+		{
+			connector: "ZeppDiverCity",
+			css: map[string]string{
+				"//div[@class='sch-contentWrap']/a[contains(@class, 'sch-content')]": "css:div[class='sch-contentWrap'] > a.sch-content",
+				// CSS cannot select the context node itself, so the details link stays an XPath expression.
+				".":                                  ".",
+				"//h3":                               "css:h3",
+				"//h2":                               "css:h2",
+				"//p[contains(./text(), '[PRICE]')]": `css:p:containsOwn("[PRICE]")`,
+				"//table[@class='event-calendar-table']//h4":    "css:table[class='event-calendar-table'] h4",
+				"//p[@class='sch-content-date__month']":         "css:p[class='sch-content-date__month']",
+				"//span[@class='sch-content-text-date__open']":  "css:span[class='sch-content-text-date__open']",
+				"//span[@class='sch-content-text-date__start']": "css:span[class='sch-content-text-date__start']",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, c := range cases {
+		t.Run(c.connector, func(t *testing.T) {
+			xpath, ok := Connectors[c.connector]
+			if !ok {
+				t.Fatalf("expected connector %s to exist", c.connector)
+			}
+			css := cssVariant(t, xpath, c.css)
+
+			page, err := xpath.LoadDebugPage(ctx, "../../../test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := xpath.DebugLives(ctx, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := css.DebugLives(ctx, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(expected) == 0 || len(expected) != len(actual) {
+				t.Fatalf("expected %d lives, got %d", len(expected), len(actual))
+			}
+			for i := range expected {
+				// a LiveHTMLFetcher builds new nodes on every call, so only nodes of a parsed page can be compared
+				if page.Node != nil && expected[i].Node != actual[i].Node {
+					t.Errorf("live %d: selectors matched different nodes", i)
+				}
+				if !reflect.DeepEqual(expected[i].Live, actual[i].Live) || (expected[i].Err == nil) != (actual[i].Err == nil) {
+					t.Errorf("live %d: expected %+v (error %v), got %+v (error %v)", i, expected[i].Live, expected[i].Err, actual[i].Live, actual[i].Err)
+				}
+			}
+		})
+	}
+}
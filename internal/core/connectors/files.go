@@ -0,0 +1,88 @@
+package coreconnectors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yayuyokitano/livefetcher/internal/core/fetchers"
+)
+
+// DefaultConnectorDir is the directory connector files are loaded from, unless CONNECTOR_DIR is set.
+const DefaultConnectorDir = "connectors"
+
+// connectorFile is the format of a connector file, which may define any number of connectors.
+type connectorFile struct {
+	Connectors []fetchers.Spec `toml:"connector"`
+}
+
+// ConnectorDir returns the directory connector files are loaded from.
+func ConnectorDir() string {
+	if dir := os.Getenv("CONNECTOR_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultConnectorDir
+}
+
+// ParseConnectorFile parses the connectors defined in a single connector file.
+//
+// Unknown keys are treated as errors, so that typos do not silently leave out part of a connector.
+func ParseConnectorFile(path string) (connectors ConnectorsType, err error) {
+	var file connectorFile
+	md, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		return
+	}
+	if undecoded := md.Undecoded(); len(undecoded) != 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		err = fmt.Errorf("%s: unknown keys %s", path, strings.Join(keys, ", "))
+		return
+	}
+
+	connectors = make(ConnectorsType)
+	for _, spec := range file.Connectors {
+		var connector fetchers.Simple
+		connector, err = spec.Simple()
+		if err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+			return
+		}
+		if _, ok := connectors[spec.ID]; ok {
+			err = fmt.Errorf("%s: connector %s is defined more than once", path, spec.ID)
+			return
+		}
+		connectors[spec.ID] = connector
+	}
+	return
+}
+
+// LoadConnectorFiles adds the connectors defined in every .toml file in dir to Connectors.
+//
+// A missing directory is not an error, as all connectors may be defined in code.
+func LoadConnectorFiles(dir string) (err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return
+	}
+
+	for _, path := range paths {
+		var connectors ConnectorsType
+		connectors, err = ParseConnectorFile(path)
+		if err != nil {
+			return
+		}
+		for id, connector := range connectors {
+			if _, ok := Connectors[id]; ok {
+				err = fmt.Errorf("%s: connector %s is already defined", path, id)
+				return
+			}
+			Connectors[id] = connector
+		}
+	}
+	return
+}
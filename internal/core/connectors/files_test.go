@@ -0,0 +1,75 @@
+package coreconnectors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConnectorFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "connectors.toml")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseConnectorFile(t *testing.T) {
+	path := writeConnectorFile(t, `
+[[connector]]
+id = "Example"
+base-url = "https://example.com/"
+initial-url = "https://example.com/schedule"
+live-selector = "//article"
+prefecture = "tokyo"
+area = "shibuya"
+venue-id = "example"
+crawl-interval = "12h"
+title = { selector = "//h2" }
+artists = { selector = "//p", filters = [{ op = "split", args = [" / "] }, { op = "keepIndex", args = [0] }] }
+`)
+	connectors, err := ParseConnectorFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, ok := connectors["Example"]
+	if !ok {
+		t.Fatal("expected connector Example to be defined")
+	}
+	if connector.VenueID != "example" || connector.CrawlInterval.Hours() != 12 {
+		t.Errorf("unexpected connector %+v", connector)
+	}
+}
+
+func TestParseConnectorFileErrors(t *testing.T) {
+	const base = `
+[[connector]]
+id = "Example"
+prefecture = "tokyo"
+area = "shibuya"
+venue-id = "example"
+`
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", base + `titel = { selector = "//h2" }`, "unknown keys"},
+		{"unknown filter", base + `title = { selector = "//h2", filters = [{ op = "splat" }] }`, "unknown filter splat"},
+		{"wrong arity", base + `title = { selector = "//h2", filters = [{ op = "split" }] }`, "expected 1 arguments"},
+		{"wrong type", base + `title = { selector = "//h2", filters = [{ op = "keepIndex", args = ["0"] }] }`, "must be an integer"},
+		{"invalid regex", base + `artists = { selector = "//p", filters = [{ op = "replaceAllRegex", args = ["[", ""] }] }`, "connector Example: artists: replaceAllRegex: argument 1 must be a valid regular expression"},
+		{"unknown fetcher", base + `live-html-fetcher = "missing"`, "no live html fetcher"},
+		{"duplicate", base + base, "defined more than once"},
+		{"missing venue", `[[connector]]
+id = "Example"`, "must have venue-id"},
+	}
+	for _, test := range tests {
+		_, err := ParseConnectorFile(writeConnectorFile(t, test.content))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.want, err)
+		}
+	}
+}
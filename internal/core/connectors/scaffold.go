@@ -0,0 +1,292 @@
+package coreconnectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+)
+
+var (
+	connectorIDPattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	slugPattern        = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// ScaffoldOptions describes the connector created by Scaffold.
+type ScaffoldOptions struct {
+	// ID is the connector ID, derived from VenueID if empty, so that kokura-fuse becomes KokuraFuse.
+	ID             string
+	VenueID        string
+	PrefectureName string
+	AreaName       string
+	// URL is the schedule page of the venue, which is saved as the test document.
+	URL       string
+	Latitude  float64
+	Longitude float64
+	// Name is the placeholder used for missing translations, defaulting to the respective ID.
+	Name string
+
+	// ConnectorDir is the directory the connector file is written to.
+	ConnectorDir string
+	// TestDir is the directory containing test documents, organized by prefecture and area.
+	TestDir string
+	// LocaleDir is the directory containing the locale files translations are added to.
+	LocaleDir string
+	// Client is used to download the test document, defaulting to httpclient.Default().
+	Client *httpclient.Client
+}
+
+// ScaffoldResult lists the files created or modified by Scaffold.
+type ScaffoldResult struct {
+	ID            string
+	ConnectorFile string
+	TestDocument  string
+	LocaleFiles   []string
+}
+
+// Scaffold creates a new connector: it saves the schedule page as test document, appends a skeleton connector to
+// the connector file of the prefecture, and adds placeholder translations for the venue, area and prefecture.
+//
+// The skeleton is excluded from the schedule, which keeps it from being crawled until its selectors are filled in.
+// Scaffold refuses to create a connector whose ID or venue ID is already in use.
+func Scaffold(ctx context.Context, opts ScaffoldOptions) (res ScaffoldResult, err error) {
+	if opts.ID == "" {
+		opts.ID = connectorIDFromVenueID(opts.VenueID)
+	}
+	err = opts.validate()
+	if err != nil {
+		return
+	}
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		err = fmt.Errorf("url must be http or https, got %s", opts.URL)
+		return
+	}
+
+	res.ID = opts.ID
+	res.ConnectorFile = filepath.Join(opts.ConnectorDir, opts.PrefectureName+".toml")
+	res.TestDocument = filepath.Join(opts.TestDir, opts.PrefectureName, opts.AreaName, opts.VenueID+".html")
+	if _, statErr := os.Stat(res.TestDocument); statErr == nil {
+		err = fmt.Errorf("test document %s already exists", res.TestDocument)
+		return
+	}
+
+	// download first, so that nothing is written if the page cannot be fetched
+	client := opts.Client
+	if client == nil {
+		client = httpclient.Default()
+	}
+	doc, err := client.GetBytes(ctx, opts.URL)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(res.TestDocument), 0o755)
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(res.TestDocument, doc, 0o644)
+	if err != nil {
+		return
+	}
+
+	err = appendSkeleton(res.ConnectorFile, opts, u)
+	if err != nil {
+		return
+	}
+
+	res.LocaleFiles, err = filepath.Glob(filepath.Join(opts.LocaleDir, "*.toml"))
+	if err != nil {
+		return
+	}
+	for _, path := range res.LocaleFiles {
+		err = addPlaceholderTranslations(path, opts)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (opts ScaffoldOptions) validate() error {
+	if !connectorIDPattern.MatchString(opts.ID) {
+		return fmt.Errorf("connector id must be in PascalCase, got %q", opts.ID)
+	}
+	for name, value := range map[string]string{"venue id": opts.VenueID, "prefecture": opts.PrefectureName, "area": opts.AreaName} {
+		if !slugPattern.MatchString(value) {
+			return fmt.Errorf("%s must be lowercase words separated by dashes, got %q", name, value)
+		}
+	}
+	if _, ok := Connectors[opts.ID]; ok {
+		return fmt.Errorf("connector %s already exists", opts.ID)
+	}
+	for id, connector := range Connectors {
+		if connector.VenueID == opts.VenueID {
+			return fmt.Errorf("venue %s is already used by connector %s", opts.VenueID, id)
+		}
+	}
+	return nil
+}
+
+// connectorIDFromVenueID converts a venue ID such as kokura-fuse to a connector ID such as KokuraFuse.
+func connectorIDFromVenueID(venueID string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(venueID, "-") {
+		if word == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+var skeletonTemplate = template.Must(template.New("skeleton").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"float": func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) },
+}).Parse(`
+[[connector]]
+id = {{quote .Opts.ID}}
+base-url = {{quote .BaseURL}}
+initial-url = {{quote .Opts.URL}}
+# TODO: fill in the selectors below, see fetchers.Spec and htmlquerier.Spec for all available keys
+live-selector = ""
+prefecture = {{quote .Opts.PrefectureName}}
+area = {{quote .Opts.AreaName}}
+venue-id = {{quote .Opts.VenueID}}
+latitude = {{float .Opts.Latitude}}
+longitude = {{float .Opts.Longitude}}
+# remove exclude-from-schedule once the connector passes its test, so that it is crawled
+exclude-from-schedule = true
+
+title = { selector = "" }
+artists = { selector = "" }
+price = { selector = "" }
+
+[connector.time]
+day = { selector = "" }
+open-time = { selector = "" }
+start-time = { selector = "" }
+
+[connector.test]
+number-of-lives = 0
+first-live-title = ""
+first-live-artists = []
+first-live-price = ""
+first-live-price-english = ""
+first-live-url = ""
+`))
+
+// appendSkeleton appends a skeleton connector to the connector file at path, creating it if it does not exist.
+func appendSkeleton(path string, opts ScaffoldOptions, u *url.URL) (err error) {
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	return skeletonTemplate.Execute(f, struct {
+		Opts    ScaffoldOptions
+		BaseURL string
+	}{opts, u.Scheme + "://" + u.Host + "/"})
+}
+
+// addPlaceholderTranslations adds placeholder translations for the venue, and for its area and prefecture if they are
+// new, to the locale file at path.
+func addPlaceholderTranslations(path string, opts ScaffoldOptions) (err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(b), "\n")
+
+	placeholder := func(id string) string {
+		if opts.Name != "" {
+			return opts.Name
+		}
+		return id
+	}
+	lines = addTranslation(lines, "livehouse", opts.VenueID, placeholder(opts.VenueID))
+	lines = addTranslation(lines, "prefecture", opts.PrefectureName, opts.PrefectureName)
+	lines = addTranslation(lines, "area."+opts.PrefectureName, opts.AreaName, opts.AreaName)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644)
+}
+
+// addTranslation adds key to the given table of the lines of a locale file, unless it is already present.
+//
+// The key is inserted in alphabetical order if the table is sorted, and at the end of the table otherwise.
+// A missing table is created after the last table sharing its parent, such as area.tokyo for area.osaka.
+func addTranslation(lines []string, table, key, value string) []string {
+	entry := key + " = " + strconv.Quote(value)
+	header := "[" + table + "]"
+
+	start := slices.Index(lines, header)
+	if start == -1 {
+		at := len(lines)
+		if parent, _, ok := strings.Cut(table, "."); ok {
+			for i, line := range lines {
+				if strings.HasPrefix(line, "["+parent+".") {
+					at = tableEnd(lines, i)
+				}
+			}
+		}
+		for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		return slices.Insert(lines, at, "", header, entry)
+	}
+
+	end := tableEnd(lines, start)
+	var keys []string
+	for _, line := range lines[start+1 : end] {
+		k, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(k) == key {
+			return lines
+		}
+		keys = append(keys, strings.TrimSpace(k))
+	}
+
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	at := end
+	if slices.IsSorted(keys) {
+		for i := start + 1; i < end; i++ {
+			k, _, ok := strings.Cut(lines[i], "=")
+			if ok && strings.TrimSpace(k) > key {
+				at = i
+				break
+			}
+		}
+	}
+	return slices.Insert(lines, at, entry)
+}
+
+// tableEnd returns the index of the line after the table starting at line start.
+func tableEnd(lines []string, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "[") {
+			return i
+		}
+	}
+	return len(lines)
+}
@@ -0,0 +1,122 @@
+package coreconnectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+)
+
+const testLocale = `[livehouse]
+alpha = "Alpha"
+gamma = "Gamma"
+
+[prefecture]
+tokyo = "Tokyo"
+
+[area.tokyo]
+shibuya = "Shibuya"
+
+[util]
+prefecture-area = "{{.Area}}, {{.Prefecture}}"
+`
+
+func TestScaffold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>schedule</body></html>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	localeDir := filepath.Join(dir, "locales")
+	err := os.MkdirAll(localeDir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(localeDir, "en_US.toml"), []byte(testLocale), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := httpclient.DefaultConfig()
+	cfg.RobotsTxt = false
+	cfg.HostInterval = 0
+	opts := ScaffoldOptions{
+		VenueID:        "beta-hall",
+		PrefectureName: "osaka",
+		AreaName:       "namba",
+		URL:            server.URL + "/schedule",
+		Latitude:       34.66,
+		Longitude:      135.5,
+		Name:           "Beta Hall",
+		ConnectorDir:   filepath.Join(dir, "connectors"),
+		TestDir:        filepath.Join(dir, "test"),
+		LocaleDir:      localeDir,
+		Client:         httpclient.New(cfg),
+	}
+	res, err := Scaffold(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "BetaHall" {
+		t.Errorf("expected connector id BetaHall, got %s", res.ID)
+	}
+
+	doc, err := os.ReadFile(filepath.Join(dir, "test", "osaka", "namba", "beta-hall.html"))
+	if err != nil || !strings.Contains(string(doc), "schedule") {
+		t.Errorf("expected test document to be saved, got %q, %v", doc, err)
+	}
+
+	connectors, err := ParseConnectorFile(res.ConnectorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, ok := connectors["BetaHall"]
+	if !ok || connector.VenueID != "beta-hall" || !connector.ExcludeFromSchedule || connector.TestInfo.IgnoreTest || connector.InitialURL != opts.URL {
+		t.Errorf("unexpected skeleton connector %+v", connector)
+	}
+
+	locale, err := os.ReadFile(filepath.Join(localeDir, "en_US.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[livehouse]
+alpha = "Alpha"
+beta-hall = "Beta Hall"
+gamma = "Gamma"
+
+[prefecture]
+osaka = "osaka"
+tokyo = "Tokyo"
+
+[area.tokyo]
+shibuya = "Shibuya"
+
+[area.osaka]
+namba = "namba"
+
+[util]
+prefecture-area = "{{.Area}}, {{.Prefecture}}"
+`
+	if string(locale) != expected {
+		t.Errorf("unexpected locale file:\n%s", locale)
+	}
+}
+
+func TestScaffoldDuplicate(t *testing.T) {
+	tests := []ScaffoldOptions{
+		{ID: "KokuraFuse", VenueID: "new-venue", PrefectureName: "fukuoka", AreaName: "kokura", URL: "https://example.com"},
+		{VenueID: "kokura-fuse", ID: "NewVenue", PrefectureName: "fukuoka", AreaName: "kokura", URL: "https://example.com"},
+	}
+	for _, opts := range tests {
+		_, err := Scaffold(context.Background(), opts)
+		if err == nil || !strings.Contains(err.Error(), "already") {
+			t.Errorf("expected %s (%s) to be refused as duplicate, got %v", opts.ID, opts.VenueID, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
+	"golang.org/x/net/html"
+)
+
+// DebugPage is a page of a connector loaded for debugging.
+type DebugPage struct {
+	// Node is the parsed page, which is nil for connectors using a LiveHTMLFetcher.
+	Node *html.Node
+	// URL is the URL of the page, which is used to resolve links.
+	URL *url.URL
+	// TestDocument is the test document passed to the LiveHTMLFetcher, if any.
+	TestDocument []byte
+}
+
+// Traces records how each querier of a live arrived at its value, keyed by querier name.
+type Traces map[string]htmlquerier.Trace
+
+// DebugLive is a live parsed while debugging, along with the node it was parsed from.
+type DebugLive struct {
+	Node *html.Node
+	Live datastructures.Live
+	// Traces are the traces of the queriers run while parsing the live.
+	Traces Traces
+	// Err is the error parsing the live, in which case Live is incomplete.
+	Err error
+}
+
+// pageURL returns the URL of the page that is tested, and loaded by LoadDebugPage.
+func (s *Simple) pageURL() string {
+	switch {
+	case s.InitialURL != "":
+		return s.InitialURL
+	case s.ShortYearIterableURL != "" || s.ShortYearReverseIterableURL != "":
+		return s.getCurrentShortURL()
+	default:
+		return s.BaseURL
+	}
+}
+
+// LoadDebugPage loads the page the connector is tested against.
+// If testDir is not empty, the test document of the connector in testDir is loaded, otherwise the page is fetched.
+func (s *Simple) LoadDebugPage(ctx context.Context, testDir string) (page DebugPage, err error) {
+	page.URL, err = url.Parse(s.pageURL())
+	if err != nil {
+		return
+	}
+	if s.IgnoreRobotsTxt {
+		ctx = httpclient.WithoutRobotsTxt(ctx)
+	}
+
+	if testDir == "" {
+		if s.LiveHTMLFetcher == nil {
+			page.Node, err = s.client().LoadHTML(ctx, page.URL.String())
+		}
+		return
+	}
+
+	path := filepath.Join(testDir, s.PrefectureName, s.AreaName, s.VenueID)
+	if s.LiveHTMLFetcher != nil {
+		page.TestDocument, err = os.ReadFile(path + ".txt")
+		return
+	}
+	page.Node, err = htmlquery.LoadDoc(path + ".html")
+	return
+}
+
+// DebugLives parses the lives of a page like Fetch does, returning every live along with the node it was parsed from.
+//
+// Unlike Fetch, lives that fail to parse, lives in the past and lives without artists are included.
+func (s *Simple) DebugLives(ctx context.Context, page DebugPage) (lives []DebugLive, err error) {
+	if s.IgnoreRobotsTxt {
+		ctx = httpclient.WithoutRobotsTxt(ctx)
+	}
+	debug := *s
+	debug.isTesting = true
+	debug.onLive = func(n *html.Node, l datastructures.Live, traces Traces, err error) {
+		lives = append(lives, DebugLive{Node: n, Live: l, Traces: traces, Err: err})
+	}
+	_, err = debug.fetchLives(ctx, page.Node, page.URL, page.TestDocument)
+	if err != nil {
+		err = fmt.Errorf("parsing lives: %w", err)
+	}
+	return
+}
+
+// debugLive reports a parsed live to DebugLives, if it is running.
+func (s *Simple) debugLive(n *html.Node, l datastructures.Live, err error) {
+	if s.onLive != nil {
+		s.onLive(n, l, maps.Clone(s.traces), err)
+	}
+}
+
+// query executes q, recording its trace under name while testing.
+func (s *Simple) query(name string, q *htmlquerier.Querier, n *html.Node) (a []string, err error) {
+	if s.traces == nil {
+		return q.Execute(n)
+	}
+	a, trace, err := q.ExecuteTrace(n)
+	s.traces[name] = trace
+	return
+}
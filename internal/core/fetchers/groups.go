@@ -305,6 +305,7 @@ func CreateBassOnTopFetcher(
 			FirstLivePriceEnglish: testInfo.FirstLivePriceEnglish,
 			FirstLiveOpenTime:     testInfo.FirstLiveOpenTime,
 			FirstLiveStartTime:    testInfo.FirstLiveStartTime,
+			FirstLiveTimeUnknown:  testInfo.FirstLiveTimeUnknown,
 			FirstLiveURL:          testInfo.FirstLiveURL,
 		},
 	}
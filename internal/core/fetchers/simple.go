@@ -4,8 +4,10 @@
 package fetchers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -17,6 +19,7 @@ import (
 	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
 	"github.com/yayuyokitano/livefetcher/internal/core/util"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 	"golang.org/x/net/html"
 )
 
@@ -32,16 +35,25 @@ type TimeHandler struct {
 	// DayQuerier is a querier that returns the day of the live.
 	DayQuerier htmlquerier.Querier
 
+	// DateQuerier is a querier ending with DateFrom, that returns the date of the live.
+	// It always executes in the context of LiveSelector.
+	//
+	// Parts of the date not captured by DateFrom are taken from YearQuerier, MonthQuerier and DayQuerier if present,
+	// so DateQuerier can replace any of them. If the year is known from neither, it is derived from the month.
+	DateQuerier htmlquerier.Querier
+
 	// OpenTimeQuerier is a querier that returns the open time of the live in format xx:xx
 	//
 	// The core handles hours >= 24, incrementing day and subtracting hours appropriately.
 	// The core also will automatically remove any extra characters not part of a time.
+	// If the result contains no time, the open time of the live is unknown.
 	OpenTimeQuerier htmlquerier.Querier
 
 	// OpenTimeQuerier is a querier that returns the start time of the live in format xx:xx
 	//
 	// The core handles hours >= 24, incrementing day and subtracting hours appropriately.
 	// The core also will automatically remove any extra characters not part of a time.
+	// If the result contains no time, the start time of the live is unknown.
 	StartTimeQuerier htmlquerier.Querier
 
 	// IsYearInLive specifies whether each live has their own year element,
@@ -77,6 +89,9 @@ type TestInfo struct {
 	FirstLiveOpenTime time.Time
 	// FirstLiveStartTime specifies the expected starting timestamp of the first live in the test document.
 	FirstLiveStartTime time.Time
+	// FirstLiveTimeUnknown specifies that the first live in the test document has neither an open nor a start time,
+	// in which case FirstLiveOpenTime and FirstLiveStartTime should be midnight of its date.
+	FirstLiveTimeUnknown bool
 	// FirstLiveURL specifies the expected URL of the first live in the test document.
 	FirstLiveURL string
 	// KnownEmpty is a workaround property, specifying that we expect that one of the live entries in the live test will be empty.
@@ -95,7 +110,13 @@ type TestInfo struct {
 	IgnoreTest bool
 }
 
+// LiveHTMLFetcherFunc is a function returning html nodes corresponding to lives, see Simple.LiveHTMLFetcher.
+// When testing, testDocument contains the test document of the connector, otherwise it is nil.
+type LiveHTMLFetcherFunc func(ctx context.Context, client *httpclient.Client, testDocument []byte) ([]*html.Node, error)
+
 // Simple is the basic fetcher, which currently all fetchers base themselves off of.
+//
+// Selectors are XPath expressions, or CSS selectors when prefixed with htmlquerier.CSSPrefix, and can be mixed freely.
 type Simple struct {
 	// BaseURL is the base URL of the live website.
 	// Fetchers often do much href parsing, often requiring us to know the base url in advance.
@@ -109,13 +130,15 @@ type Simple struct {
 	// If there are multiple pages, and a usable NextSelector isn't available, IterableURL must be used.
 	InitialURL string
 
-	// LiveSelector specifies an xpath selector for one live.
+	// LiveSelector specifies a selector for one live.
 	// Livefetcher will query for all instances of this selector, and treat every match as a separate live.
 	LiveSelector string
 
 	// LiveHTMLFetcher specifies a function that returns an array of html nodes corresponding to lives
 	// Do not use this unless absolutely necessary
-	LiveHTMLFetcher func([]byte) ([]*html.Node, error)
+	//
+	// Any requests made by the function must go through the provided client, using the provided context.
+	LiveHTMLFetcher LiveHTMLFetcherFunc
 
 	// MultiLiveDaySelector provides a selector for a more complicated case of multiple lives in same day.
 	//
@@ -234,6 +257,28 @@ type Simple struct {
 	// Whether to require an artist in a live.
 	RequireArtists bool
 
+	// CrawlInterval specifies how often the scheduler should run the connector.
+	//
+	// Leave this empty to use the default interval of the scheduler.
+	// Only set this if the live house updates its schedule unusually often or rarely.
+	CrawlInterval time.Duration
+
+	// IgnoreRobotsTxt disables consulting robots.txt before requests to the site of the live house.
+	//
+	// Only set this if the live house has explicitly allowed us to fetch their site despite their robots.txt.
+	IgnoreRobotsTxt bool
+
+	// ExcludeFromSchedule keeps the scheduler from running the connector, which is then only ever run manually.
+	//
+	// Only set this for test connectors and connectors still being written.
+	// To skip the tests of a connector, use TestInfo.IgnoreTest instead.
+	ExcludeFromSchedule bool
+
+	// Client specifies the HTTP client used for all requests made while fetching.
+	//
+	// Leave this empty to use the shared client, which rate limits requests across all connectors.
+	Client *httpclient.Client
+
 	// TestInfo is a struct specifying expected values for some tests for the connector.
 	// See TestInfo documentation for details.
 	TestInfo TestInfo
@@ -241,24 +286,51 @@ type Simple struct {
 	// Lives is used internally in the core for processing lives.
 	// Do not use this in connectors.
 	Lives []datastructures.Live
+	// PagesFetched is used internally in the core for counting the pages loaded while fetching.
+	// Do not use this in connectors.
+	PagesFetched int
 	// isTesting is used internally in the core for processing lives.
 	// Do not use this in connectors.
 	isTesting bool
+	// onLive is used internally in the core for debugging, and is called with every live parsed, including skipped ones.
+	onLive func(n *html.Node, l datastructures.Live, traces Traces, err error)
+	// traces is used internally in the core for recording querier traces of the live being parsed while testing.
+	traces Traces
+	// liveTraces is used internally in the core for recording the traces of every live parsed while testing, in the
+	// order the lives are returned in.
+	liveTraces []Traces
+}
+
+func (s *Simple) client() *httpclient.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return httpclient.Default()
 }
 
-func (s *Simple) Fetch() (err error) {
+// Fetch fetches all lives of the connector into s.Lives.
+//
+// If ctx is cancelled or its deadline exceeded, in-flight page loads are aborted and the context error is returned,
+// with s.Lives and s.PagesFetched reflecting the progress made up to that point.
+//
+// When following next links or iterating months, a page that does not exist (404) after the first one ends the
+// iteration without an error, as sites commonly answer so for months that are not published yet.
+func (s *Simple) Fetch(ctx context.Context) (err error) {
+	if s.IgnoreRobotsTxt {
+		ctx = httpclient.WithoutRobotsTxt(ctx)
+	}
 	if s.InitialURL != "" && s.NextSelector != "" {
-		err = s.iterateUsingNextLink()
+		err = s.iterateUsingNextLink(ctx)
 		if err != nil {
 			return
 		}
 	} else if s.ShortYearIterableURL != "" || s.ShortYearReverseIterableURL != "" {
-		err = s.iterateUsingShortYear()
+		err = s.iterateUsingShortYear(ctx)
 		if err != nil {
 			return
 		}
 	} else {
-		err = s.fetchSingle()
+		err = s.fetchSingle(ctx)
 		if err != nil {
 			return
 		}
@@ -266,16 +338,17 @@ func (s *Simple) Fetch() (err error) {
 	return
 }
 
-func (s *Simple) fetchSingle() (err error) {
-	n, err := htmlquery.LoadURL(s.InitialURL)
+func (s *Simple) fetchSingle(ctx context.Context) (err error) {
+	n, err := s.client().LoadHTML(ctx, s.InitialURL)
 	if err != nil {
 		return
 	}
+	s.PagesFetched++
 	initialURL, err := url.Parse(s.InitialURL)
 	if err != nil {
 		return
 	}
-	l, err := s.fetchLives(n, initialURL, nil)
+	l, err := s.fetchLives(ctx, n, initialURL, nil)
 	if err != nil {
 		return
 	}
@@ -283,27 +356,28 @@ func (s *Simple) fetchSingle() (err error) {
 	return
 }
 
-func (s *Simple) iterateUsingNextLink() (err error) {
+func (s *Simple) iterateUsingNextLink(ctx context.Context) (err error) {
 	base, err := url.Parse(s.BaseURL)
 	if err != nil {
 		return
 	}
 
-	n, err := htmlquery.LoadURL(s.InitialURL)
+	n, err := s.client().LoadHTML(ctx, s.InitialURL)
 	if err != nil {
 		return
 	}
+	s.PagesFetched++
 	initialURL, err := url.Parse(s.InitialURL)
 	if err != nil {
 		return
 	}
-	l, err := s.fetchLives(n, initialURL, nil)
+	l, err := s.fetchLives(ctx, n, initialURL, nil)
 	if err != nil {
 		return
 	}
 	prevURL := initialURL
 
-	for next, err2 := htmlquery.Query(n, s.NextSelector); next != nil && err2 == nil; next, err2 = htmlquery.Query(n, s.NextSelector) {
+	for next, err2 := htmlquerier.Query(n, s.NextSelector); next != nil && err2 == nil; next, err2 = htmlquerier.Query(n, s.NextSelector) {
 		var nextURL *url.URL
 		nextURL, err = base.Parse(htmlquery.SelectAttr(next, "href"))
 		if err != nil {
@@ -313,12 +387,17 @@ func (s *Simple) iterateUsingNextLink() (err error) {
 			break
 		}
 		prevURL = nextURL
-		n, err = htmlquery.LoadURL(nextURL.String())
+		n, err = s.client().LoadHTML(ctx, nextURL.String())
+		if httpclient.IsNotFound(err) {
+			err = nil
+			break
+		}
 		if err != nil {
 			break
 		}
+		s.PagesFetched++
 		var appL []datastructures.Live
-		appL, err = s.fetchLives(n, nextURL, nil)
+		appL, err = s.fetchLives(ctx, n, nextURL, nil)
 		if err != nil {
 			break
 		}
@@ -351,24 +430,29 @@ func (s *Simple) getNewIterableURL(year, month int) (*url.URL, error) {
 	}
 }
 
-func (s *Simple) iterateUsingShortYear() (err error) {
+func (s *Simple) iterateUsingShortYear(ctx context.Context) (err error) {
 	t := time.Now()
 	year := t.Year() % 100
 	month := int(t.Month())
 	var l []datastructures.Live
-	for err == nil {
+	for first := true; err == nil; first = false {
 		var n *html.Node
 		var newURL *url.URL
 		newURL, err = s.getNewIterableURL(year, month)
 		if err != nil {
 			break
 		}
-		n, err = htmlquery.LoadURL(newURL.String())
+		n, err = s.client().LoadHTML(ctx, newURL.String())
+		if !first && httpclient.IsNotFound(err) {
+			err = nil
+			break
+		}
 		if err != nil {
 			break
 		}
+		s.PagesFetched++
 		var appL []datastructures.Live
-		appL, err = s.fetchLives(n, newURL, nil)
+		appL, err = s.fetchLives(ctx, n, newURL, nil)
 		if err != nil {
 			break
 		}
@@ -390,26 +474,35 @@ type LiveQueueElement struct {
 	Live *html.Node
 	Res  *html.Node
 	Url  *url.URL
+	Err  error
 }
 
-func FetchLiveConcurrent(baseURL *url.URL, queue chan *LiveQueueElement, expandedLiveSelector string, wg *sync.WaitGroup) {
+// FetchLiveConcurrent loads the detail page of every live in queue, storing it in the Res field of the live.
+//
+// Lives whose detail page could not be loaded are left with a nil Res, and the error in Err.
+// Once ctx is done, the remaining lives in queue are skipped.
+func FetchLiveConcurrent(ctx context.Context, client *httpclient.Client, baseURL *url.URL, queue chan *LiveQueueElement, expandedLiveSelector string, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for job := range queue {
-		liveAnchor, err := htmlquery.Query(job.Live, expandedLiveSelector)
+		if ctx.Err() != nil {
+			continue
+		}
+		liveAnchor, err := htmlquerier.Query(job.Live, expandedLiveSelector)
 		if err != nil || liveAnchor == nil {
-			return
+			continue
 		}
 
 		var liveDetails *html.Node
 		var url *url.URL
 		url, err = baseURL.Parse(htmlquery.SelectAttr(liveAnchor, "href"))
 		if err != nil {
-			return
+			continue
 		}
 		job.Url = url
-		liveDetails, err = htmlquery.LoadURL(url.String())
+		liveDetails, err = client.LoadHTML(ctx, url.String())
 		if err != nil || liveDetails == nil {
-			return
+			job.Err = err
+			continue
 		}
 		job.Res = liveDetails
 	}
@@ -420,11 +513,11 @@ type LiveContext struct {
 	url *url.URL
 }
 
-func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []byte) (l []datastructures.Live, err error) {
+func (s *Simple) fetchLives(ctx context.Context, n *html.Node, overviewURL *url.URL, testDocument []byte) (l []datastructures.Live, err error) {
 	var lives []LiveContext
 	if s.ExpandedLiveSelector != "" {
 		var overview []*html.Node
-		overview, err = htmlquery.QueryAll(n, s.LiveSelector)
+		overview, err = htmlquerier.QueryAll(n, s.LiveSelector)
 		if err != nil {
 			return
 		}
@@ -445,10 +538,32 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 		close(queue)
 		for i := 0; i < min(10, len(overview)); i++ {
 			wg.Add(1)
-			go FetchLiveConcurrent(overviewURL, queue, s.ExpandedLiveSelector, &wg)
+			go FetchLiveConcurrent(ctx, s.client(), overviewURL, queue, s.ExpandedLiveSelector, &wg)
 		}
 		wg.Wait()
+		var robotsErr *httpclient.RobotsError
 		for _, liveDetails := range res {
+			if liveDetails.Res == nil {
+				if robotsErr == nil {
+					errors.As(liveDetails.Err, &robotsErr)
+				}
+				continue
+			}
+			s.PagesFetched++
+		}
+		// lives we are not allowed to fetch should not silently disappear
+		if robotsErr != nil {
+			err = robotsErr
+			return
+		}
+		// do not return lives from a partially loaded page, as they would be indistinguishable from a complete one
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		for _, liveDetails := range res {
+			if liveDetails.Res == nil {
+				continue
+			}
 			if s.ExpandedLiveGroupSelector == "" {
 				lives = append(lives, LiveContext{
 					n:   liveDetails.Res,
@@ -456,7 +571,7 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 				})
 			} else {
 				var liveNodes []*html.Node
-				liveNodes, err = htmlquery.QueryAll(liveDetails.Res, s.ExpandedLiveGroupSelector)
+				liveNodes, err = htmlquerier.QueryAll(liveDetails.Res, s.ExpandedLiveGroupSelector)
 				if err != nil || len(liveNodes) == 0 {
 					continue
 				}
@@ -470,7 +585,7 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 		}
 	} else if s.LiveSelector != "" {
 		var rawLives []*html.Node
-		rawLives, err = htmlquery.QueryAll(n, s.LiveSelector)
+		rawLives, err = htmlquerier.QueryAll(n, s.LiveSelector)
 		if err != nil {
 			return
 		}
@@ -486,10 +601,11 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 		}
 	} else if s.LiveHTMLFetcher != nil {
 		var rawLives []*html.Node
-		rawLives, err = s.LiveHTMLFetcher(testDocument)
+		rawLives, err = s.LiveHTMLFetcher(ctx, s.client(), testDocument)
 		if err != nil {
 			return
 		}
+		s.PagesFetched++
 		if rawLives == nil {
 			err = errors.New("raw live query returned nil")
 			return
@@ -506,8 +622,15 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 		return
 	}
 
+	if s.isTesting {
+		s.traces = make(Traces)
+	}
+
+	// with a DateQuerier, the page may have no year or month at all
+	dateOnly := s.TimeHandler.DateQuerier.Initialized && !s.TimeHandler.MonthQuerier.Initialized
+
 	var year string
-	if !s.TimeHandler.IsYearInLive {
+	if !s.TimeHandler.IsYearInLive && !(dateOnly && !s.TimeHandler.YearQuerier.Initialized) {
 		year, err = s.getYear(n)
 		if err != nil {
 			return
@@ -515,7 +638,7 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 	}
 
 	var month string
-	if !s.TimeHandler.IsMonthInLive {
+	if !s.TimeHandler.IsMonthInLive && !dateOnly {
 		month, err = s.getMonth(n)
 		if err != nil {
 			return
@@ -523,12 +646,16 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 	}
 
 	var day string
+	// queriers run on the page are part of the trace of every live
+	pageTraces := maps.Clone(s.traces)
 
 	for _, live := range lives {
+		s.traces = maps.Clone(pageTraces)
 
 		if s.TimeHandler.IsYearInLive {
 			year, err = s.getYear(live.n)
 			if err != nil {
+				s.debugLive(live.n, datastructures.Live{}, err)
 				fmt.Println(err)
 				err = nil
 				continue
@@ -545,16 +672,30 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 		}
 
 		prevDay := day
-		day, err = s.getDay(live.n)
-		if err != nil || day == "" {
-			err = nil
-			day = prevDay
+		if s.TimeHandler.DayQuerier.Initialized || !s.TimeHandler.DateQuerier.Initialized {
+			day, err = s.getDay(live.n)
+			if err != nil || day == "" {
+				err = nil
+				day = prevDay
+			}
+		}
+
+		liveYear := year
+		if s.TimeHandler.DateQuerier.Initialized {
+			liveYear, month, day, err = s.getDate(live.n, year, month, day)
+			if err != nil {
+				s.debugLive(live.n, datastructures.Live{}, err)
+				fmt.Println(err)
+				err = nil
+				continue
+			}
 		}
 
 		timeCutoff := time.Now().AddDate(0, -1, 0)
 
 		if s.MultiLiveDaySelector == "" {
-			appL, err := s.fetchDetails(live.n, live.url, year, month, day)
+			appL, err := s.fetchDetails(live.n, live.url, liveYear, month, day)
+			s.debugLive(live.n, appL, err)
 			if err != nil {
 				fmt.Println(err)
 				err = nil
@@ -567,13 +708,15 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 				continue
 			}
 			l = append(l, appL)
+			s.recordLiveTraces()
 		} else {
-			dailyLives, err := htmlquery.QueryAll(live.n, s.MultiLiveDaySelector)
+			dailyLives, err := htmlquerier.QueryAll(live.n, s.MultiLiveDaySelector)
 			if err != nil || dailyLives == nil {
 				continue
 			}
 			for _, dailyLive := range dailyLives {
-				appL, err := s.fetchDetails(dailyLive, live.url, year, month, day)
+				appL, err := s.fetchDetails(dailyLive, live.url, liveYear, month, day)
+				s.debugLive(dailyLive, appL, err)
 				if err != nil {
 					fmt.Println(err)
 					err = nil
@@ -586,31 +729,38 @@ func (s *Simple) fetchLives(n *html.Node, overviewURL *url.URL, testDocument []b
 					continue
 				}
 				l = append(l, appL)
+				s.recordLiveTraces()
 			}
 		}
 	}
 	return
 }
 
+// recordLiveTraces records the traces of the live just parsed while testing, see liveTraces.
+func (s *Simple) recordLiveTraces() {
+	if s.isTesting {
+		s.liveTraces = append(s.liveTraces, maps.Clone(s.traces))
+	}
+}
+
 func (s *Simple) fetchDetails(live *html.Node, overviewURL *url.URL, year string, month string, day string) (l datastructures.Live, err error) {
 	date := fmt.Sprintf("%s-%s-%s", year, month, day)
 
-	var open time.Time
-	var start time.Time
-	open, err = s.getOpenTime(live, date)
+	open, openUnknown, err := s.getOpenTime(live, date)
 	if err != nil {
 		return
 	}
 
-	start, err = s.getStartTime(live, date)
+	start, startUnknown, err := s.getStartTime(live, date)
 	if err != nil {
 		return
 	}
-	if open.Hour() == 3 && open.Minute() == 24 && !(start.Hour() == 3 && start.Minute() == 24) {
-		open = start
+	// lives listing a single time are assumed to open and start at that time
+	if openUnknown && !startUnknown {
+		open, openUnknown = start, false
 	}
-	if start.Hour() == 3 && start.Minute() == 24 && !(open.Hour() == 3 && open.Minute() == 24) {
-		start = open
+	if startUnknown && !openUnknown {
+		start, startUnknown = open, false
 	}
 
 	var price string
@@ -634,7 +784,7 @@ func (s *Simple) fetchDetails(live *html.Node, overviewURL *url.URL, year string
 	detailsURL := overviewURL.String()
 	if s.DetailsLinkSelector != "" {
 		var detailsLink *html.Node
-		detailsLink, err = htmlquery.Query(live, s.DetailsLinkSelector)
+		detailsLink, err = htmlquerier.Query(live, s.DetailsLinkSelector)
 		if err == nil && detailsLink != nil {
 			var newURL *url.URL
 			newURL, err = overviewURL.Parse(htmlquery.SelectAttr(detailsLink, "href"))
@@ -652,12 +802,14 @@ func (s *Simple) fetchDetails(live *html.Node, overviewURL *url.URL, year string
 	}
 
 	l = datastructures.Live{
-		Title:        title,
-		Artists:      artists,
-		OpenTime:     open,
-		StartTime:    start,
-		Price:        strings.TrimSpace(price),
-		PriceEnglish: strings.TrimSpace(util.EnglishPriceHandler(price)),
+		Title:            title,
+		Artists:          artists,
+		OpenTime:         open,
+		StartTime:        start,
+		OpenTimeUnknown:  openUnknown,
+		StartTimeUnknown: startUnknown,
+		Price:            strings.TrimSpace(price),
+		PriceEnglish:     strings.TrimSpace(util.EnglishPriceHandler(price)),
 		Venue: datastructures.LiveHouse{
 			ID: s.VenueID,
 			Area: datastructures.Area{
@@ -674,10 +826,10 @@ func (s *Simple) fetchDetails(live *html.Node, overviewURL *url.URL, year string
 
 func (s *Simple) FetchArtists(n *html.Node) (a []string, err error) {
 	if s.ArtistsQuerier.Initialized {
-		a, err = s.ArtistsQuerier.Execute(n)
+		a, err = s.query("artists", &s.ArtistsQuerier, n)
 		a = util.ProcessArtists(a)
 	} else {
-		a, err = s.DetailQuerier.Execute(n)
+		a, err = s.query("detail", &s.DetailQuerier, n)
 		if err != nil || len(a) == 0 {
 			return
 		}
@@ -688,7 +840,7 @@ func (s *Simple) FetchArtists(n *html.Node) (a []string, err error) {
 
 func (s *Simple) getTitle(n *html.Node) (title string, err error) {
 	var a []string
-	a, err = s.TitleQuerier.Execute(n)
+	a, err = s.query("title", &s.TitleQuerier, n)
 	if err != nil {
 		return
 	}
@@ -708,7 +860,7 @@ func isolateFirstNumber(old string) (isolated string, err error) {
 func (s *Simple) getYear(n *html.Node) (year string, err error) {
 	var res []string
 	if s.TimeHandler.YearQuerier.Initialized {
-		res, err = s.TimeHandler.YearQuerier.Execute(n)
+		res, err = s.query("year", &s.TimeHandler.YearQuerier, n)
 		if err != nil {
 			return
 		}
@@ -737,7 +889,7 @@ func (s *Simple) getYear(n *html.Node) (year string, err error) {
 }
 
 func (s *Simple) getMonth(n *html.Node) (month string, err error) {
-	res, err := s.TimeHandler.MonthQuerier.Execute(n)
+	res, err := s.query("month", &s.TimeHandler.MonthQuerier, n)
 	if err != nil {
 		return
 	}
@@ -753,7 +905,7 @@ func (s *Simple) getMonth(n *html.Node) (month string, err error) {
 }
 
 func (s *Simple) getDay(n *html.Node) (day string, err error) {
-	res, err := s.TimeHandler.DayQuerier.Execute(n)
+	res, err := s.query("day", &s.TimeHandler.DayQuerier, n)
 	if err != nil {
 		return
 	}
@@ -767,16 +919,51 @@ func (s *Simple) getDay(n *html.Node) (day string, err error) {
 	return
 }
 
+// getDate returns the year, month and day of a live, replacing the given ones with the parts captured by DateQuerier.
+// If the year is still unknown, it is derived from the month.
+func (s *Simple) getDate(n *html.Node, year, month, day string) (y string, m string, d string, err error) {
+	y, m, d = year, month, day
+	res, err := s.query("date", &s.TimeHandler.DateQuerier, n)
+	if err != nil {
+		return
+	}
+	if res[0] != "" {
+		var date htmlquerier.Date
+		date, err = htmlquerier.ParseDate(res[0])
+		if err != nil {
+			return
+		}
+		if date.Year != 0 {
+			y = strconv.Itoa(date.Year)
+		}
+		if date.Month != 0 {
+			m = fmt.Sprintf("%02d", date.Month)
+		}
+		if date.Day != 0 {
+			d = fmt.Sprintf("%02d", date.Day)
+		}
+	}
+	if y == "" && m != "" {
+		var month int
+		month, err = strconv.Atoi(m)
+		if err != nil {
+			return
+		}
+		y = strconv.Itoa(util.GetRelevantYear(month))
+	}
+	return
+}
+
 func (s *Simple) getPrice(n *html.Node) (price string, err error) {
 	var prices []string
 	if s.PriceQuerier.Initialized {
-		prices, err = s.PriceQuerier.Execute(n)
+		prices, err = s.query("price", &s.PriceQuerier, n)
 		if err != nil || len(prices) == 0 {
 			return
 		}
 		price = prices[0]
 	} else if s.DetailQuerier.Initialized {
-		prices, err = s.DetailQuerier.Execute(n)
+		prices, err = s.query("detail", &s.DetailQuerier, n)
 		if err != nil || len(prices) == 0 {
 			return
 		}
@@ -787,46 +974,38 @@ func (s *Simple) getPrice(n *html.Node) (price string, err error) {
 	return
 }
 
-func (s *Simple) getOpenTime(n *html.Node, date string) (open time.Time, err error) {
+func (s *Simple) getOpenTime(n *html.Node, date string) (open time.Time, unknown bool, err error) {
 	var arr []string
 	if s.TimeHandler.OpenTimeQuerier.Initialized {
-		arr, err = s.TimeHandler.OpenTimeQuerier.Execute(n)
+		arr, err = s.query("open-time", &s.TimeHandler.OpenTimeQuerier, n)
 		if err != nil || arr[0] == "" {
-			open, err = util.ParseTime(date, "03:24")
-			return
+			return util.ParseTime(date, "")
 		}
-		open, err = util.ParseTime(date, arr[0])
+		return util.ParseTime(date, arr[0])
 	} else if s.DetailQuerier.Initialized {
-		arr, err = s.DetailQuerier.Execute(n)
+		arr, err = s.query("detail", &s.DetailQuerier, n)
 		if err != nil || arr[0] == "" {
-			open, err = util.ParseTime(date, "03:24")
-			return
+			return util.ParseTime(date, "")
 		}
-		open, err = util.ParseTime(date, util.FindTime(strings.Join(arr, ""), "open"))
-	} else {
-		open, err = util.ParseTime(date, "03:24")
+		return util.ParseTime(date, util.FindTime(strings.Join(arr, ""), "open"))
 	}
-	return
+	return util.ParseTime(date, "")
 }
 
-func (s *Simple) getStartTime(n *html.Node, date string) (start time.Time, err error) {
+func (s *Simple) getStartTime(n *html.Node, date string) (start time.Time, unknown bool, err error) {
 	var arr []string
 	if s.TimeHandler.StartTimeQuerier.Initialized {
-		arr, err = s.TimeHandler.StartTimeQuerier.Execute(n)
+		arr, err = s.query("start-time", &s.TimeHandler.StartTimeQuerier, n)
 		if err != nil || arr[0] == "" {
-			start, err = util.ParseTime(date, "03:24")
-			return
+			return util.ParseTime(date, "")
 		}
-		start, err = util.ParseTime(date, arr[0])
+		return util.ParseTime(date, arr[0])
 	} else if s.DetailQuerier.Initialized {
-		arr, err = s.DetailQuerier.Execute(n)
+		arr, err = s.query("detail", &s.DetailQuerier, n)
 		if err != nil || arr[0] == "" {
-			start, err = util.ParseTime(date, "03:24")
-			return
+			return util.ParseTime(date, "")
 		}
-		start, err = util.ParseTime(date, util.FindTime(strings.Join(arr, ""), "start"))
-	} else {
-		start, err = util.ParseTime(date, "03:24")
+		return util.ParseTime(date, util.FindTime(strings.Join(arr, ""), "start"))
 	}
-	return
+	return util.ParseTime(date, "")
 }
@@ -0,0 +1,188 @@
+package fetchers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+)
+
+// TimeHandlerSpec is a declarative description of a TimeHandler.
+type TimeHandlerSpec struct {
+	Year          htmlquerier.Spec `toml:"year"`
+	Month         htmlquerier.Spec `toml:"month"`
+	Day           htmlquerier.Spec `toml:"day"`
+	Date          htmlquerier.Spec `toml:"date"`
+	OpenTime      htmlquerier.Spec `toml:"open-time"`
+	StartTime     htmlquerier.Spec `toml:"start-time"`
+	IsYearInLive  bool             `toml:"year-in-live"`
+	IsMonthInLive bool             `toml:"month-in-live"`
+}
+
+// TestInfoSpec is a declarative description of TestInfo.
+type TestInfoSpec struct {
+	NumberOfLives         int       `toml:"number-of-lives"`
+	FirstLiveTitle        string    `toml:"first-live-title"`
+	FirstLiveArtists      []string  `toml:"first-live-artists"`
+	FirstLivePrice        string    `toml:"first-live-price"`
+	FirstLivePriceEnglish string    `toml:"first-live-price-english"`
+	FirstLiveOpenTime     time.Time `toml:"first-live-open-time"`
+	FirstLiveStartTime    time.Time `toml:"first-live-start-time"`
+	FirstLiveTimeUnknown  bool      `toml:"first-live-time-unknown"`
+	FirstLiveURL          string    `toml:"first-live-url"`
+	KnownEmpty            bool      `toml:"known-empty"`
+	SkipOfflineTest       bool      `toml:"skip-offline-test"`
+	IgnoreTest            bool      `toml:"ignore-test"`
+}
+
+// Spec is a declarative description of a Simple fetcher, as written in connector files.
+//
+// Every field corresponds to the Simple field of the same name, see Simple for documentation.
+type Spec struct {
+	// ID is the connector ID, which the connector is registered under.
+	ID string `toml:"id"`
+
+	BaseURL                     string `toml:"base-url"`
+	InitialURL                  string `toml:"initial-url"`
+	LiveSelector                string `toml:"live-selector"`
+	MultiLiveDaySelector        string `toml:"multi-live-day-selector"`
+	ExpandedLiveSelector        string `toml:"expanded-live-selector"`
+	ExpandedLiveGroupSelector   string `toml:"expanded-live-group-selector"`
+	ShortYearIterableURL        string `toml:"short-year-iterable-url"`
+	ShortYearReverseIterableURL string `toml:"short-year-reverse-iterable-url"`
+	NextSelector                string `toml:"next-selector"`
+	DetailsLink                 string `toml:"details-link"`
+	DetailsLinkSelector         string `toml:"details-link-selector"`
+
+	// LiveHTMLFetcher is the name of a function registered using RegisterLiveHTMLFetcher.
+	LiveHTMLFetcher string `toml:"live-html-fetcher"`
+
+	Title   htmlquerier.Spec `toml:"title"`
+	Artists htmlquerier.Spec `toml:"artists"`
+	Detail  htmlquerier.Spec `toml:"detail"`
+	Price   htmlquerier.Spec `toml:"price"`
+
+	Time TimeHandlerSpec `toml:"time"`
+
+	PrefectureName  string  `toml:"prefecture"`
+	AreaName        string  `toml:"area"`
+	VenueID         string  `toml:"venue-id"`
+	Latitude        float64 `toml:"latitude"`
+	Longitude       float64 `toml:"longitude"`
+	RequireArtists  bool    `toml:"require-artists"`
+	CrawlInterval   string  `toml:"crawl-interval"`
+	IgnoreRobotsTxt bool    `toml:"ignore-robots-txt"`
+	// ExcludeFromSchedule is for test connectors and connectors still being written, use test.ignore-test to skip tests.
+	ExcludeFromSchedule bool `toml:"exclude-from-schedule"`
+
+	Test TestInfoSpec `toml:"test"`
+}
+
+var (
+	liveHTMLFetchersMu sync.RWMutex
+	liveHTMLFetchers   = make(map[string]LiveHTMLFetcherFunc)
+)
+
+// RegisterLiveHTMLFetcher registers a LiveHTMLFetcher, which connector files can then use by name.
+func RegisterLiveHTMLFetcher(name string, fn LiveHTMLFetcherFunc) {
+	liveHTMLFetchersMu.Lock()
+	defer liveHTMLFetchersMu.Unlock()
+	liveHTMLFetchers[name] = fn
+}
+
+// Simple creates the fetcher described by the spec.
+func (spec Spec) Simple() (s Simple, err error) {
+	if spec.ID == "" {
+		err = fmt.Errorf("connector is missing id")
+		return
+	}
+	if spec.VenueID == "" || spec.PrefectureName == "" || spec.AreaName == "" {
+		err = fmt.Errorf("connector %s must have venue-id, prefecture and area", spec.ID)
+		return
+	}
+
+	s = Simple{
+		BaseURL:                     spec.BaseURL,
+		InitialURL:                  spec.InitialURL,
+		LiveSelector:                spec.LiveSelector,
+		MultiLiveDaySelector:        spec.MultiLiveDaySelector,
+		ExpandedLiveSelector:        spec.ExpandedLiveSelector,
+		ExpandedLiveGroupSelector:   spec.ExpandedLiveGroupSelector,
+		ShortYearIterableURL:        spec.ShortYearIterableURL,
+		ShortYearReverseIterableURL: spec.ShortYearReverseIterableURL,
+		NextSelector:                spec.NextSelector,
+		DetailsLink:                 spec.DetailsLink,
+		DetailsLinkSelector:         spec.DetailsLinkSelector,
+		TimeHandler: TimeHandler{
+			IsYearInLive:  spec.Time.IsYearInLive,
+			IsMonthInLive: spec.Time.IsMonthInLive,
+		},
+		PrefectureName:      spec.PrefectureName,
+		AreaName:            spec.AreaName,
+		VenueID:             spec.VenueID,
+		Latitude:            spec.Latitude,
+		Longitude:           spec.Longitude,
+		RequireArtists:      spec.RequireArtists,
+		IgnoreRobotsTxt:     spec.IgnoreRobotsTxt,
+		ExcludeFromSchedule: spec.ExcludeFromSchedule,
+		TestInfo: TestInfo{
+			NumberOfLives:         spec.Test.NumberOfLives,
+			FirstLiveTitle:        spec.Test.FirstLiveTitle,
+			FirstLiveArtists:      spec.Test.FirstLiveArtists,
+			FirstLivePrice:        spec.Test.FirstLivePrice,
+			FirstLivePriceEnglish: spec.Test.FirstLivePriceEnglish,
+			FirstLiveOpenTime:     spec.Test.FirstLiveOpenTime,
+			FirstLiveStartTime:    spec.Test.FirstLiveStartTime,
+			FirstLiveTimeUnknown:  spec.Test.FirstLiveTimeUnknown,
+			FirstLiveURL:          spec.Test.FirstLiveURL,
+			KnownEmpty:            spec.Test.KnownEmpty,
+			SkipOfflineTest:       spec.Test.SkipOfflineTest,
+			IgnoreTest:            spec.Test.IgnoreTest,
+		},
+	}
+
+	if spec.LiveHTMLFetcher != "" {
+		liveHTMLFetchersMu.RLock()
+		fn, ok := liveHTMLFetchers[spec.LiveHTMLFetcher]
+		liveHTMLFetchersMu.RUnlock()
+		if !ok {
+			err = fmt.Errorf("connector %s: no live html fetcher registered as %s", spec.ID, spec.LiveHTMLFetcher)
+			return
+		}
+		s.LiveHTMLFetcher = fn
+	}
+
+	if spec.CrawlInterval != "" {
+		s.CrawlInterval, err = time.ParseDuration(spec.CrawlInterval)
+		if err != nil {
+			err = fmt.Errorf("connector %s: crawl-interval: %w", spec.ID, err)
+			return
+		}
+	}
+
+	queriers := []struct {
+		name   string
+		spec   htmlquerier.Spec
+		target *htmlquerier.Querier
+	}{
+		{"title", spec.Title, &s.TitleQuerier},
+		{"artists", spec.Artists, &s.ArtistsQuerier},
+		{"detail", spec.Detail, &s.DetailQuerier},
+		{"price", spec.Price, &s.PriceQuerier},
+		{"time.year", spec.Time.Year, &s.TimeHandler.YearQuerier},
+		{"time.month", spec.Time.Month, &s.TimeHandler.MonthQuerier},
+		{"time.day", spec.Time.Day, &s.TimeHandler.DayQuerier},
+		{"time.date", spec.Time.Date, &s.TimeHandler.DateQuerier},
+		{"time.open-time", spec.Time.OpenTime, &s.TimeHandler.OpenTimeQuerier},
+		{"time.start-time", spec.Time.StartTime, &s.TimeHandler.StartTimeQuerier},
+	}
+	for _, q := range queriers {
+		*q.target, err = q.spec.Querier()
+		if err != nil {
+			err = fmt.Errorf("connector %s: %s: %w", spec.ID, q.name, err)
+			return
+		}
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package fetchers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/antchfx/htmlquery"
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
 	"github.com/yayuyokitano/livefetcher/internal/core/util"
 	"golang.org/x/net/html"
 )
@@ -137,7 +139,7 @@ func (s *Simple) Test(t *testing.T, errorCreator *util.ConnectorTestErrorCreator
 }
 
 func (s *Simple) testRemoteShortYearIterable(url string) (err error) {
-	n, err := htmlquery.LoadURL(url)
+	n, err := s.client().LoadHTML(context.Background(), url)
 	if err != nil {
 		return
 	}
@@ -147,7 +149,7 @@ func (s *Simple) testRemoteShortYearIterable(url string) (err error) {
 }
 
 func (s *Simple) testRemoteInitialNext() (err error) {
-	n, err := htmlquery.LoadURL(s.InitialURL)
+	n, err := s.client().LoadHTML(context.Background(), s.InitialURL)
 	if err != nil {
 		return
 	}
@@ -162,7 +164,7 @@ func (s *Simple) testRemoteInitialNext() (err error) {
 }
 
 func (s *Simple) testHasNextURL(n *html.Node) (err error) {
-	next, err := htmlquery.Query(n, s.NextSelector)
+	next, err := htmlquerier.Query(n, s.NextSelector)
 	if err != nil {
 		return
 	}
@@ -189,7 +191,8 @@ func (s *Simple) testStaticLive(n *html.Node, path string, testDocument []byte)
 		return
 	}
 
-	l, err := s.fetchLives(n, pathURL, testDocument)
+	s.liveTraces = nil
+	l, err := s.fetchLives(context.Background(), n, pathURL, testDocument)
 	if err != nil {
 		return
 	}
@@ -199,6 +202,10 @@ func (s *Simple) testStaticLive(n *html.Node, path string, testDocument []byte)
 		return
 	}
 	firstLive := l[0]
+	var firstTraces Traces
+	if len(s.liveTraces) != 0 {
+		firstTraces = s.liveTraces[0]
+	}
 	if !reflect.DeepEqual(firstLive.Artists, s.TestInfo.FirstLiveArtists) {
 		var expected []byte
 		expected, err = json.Marshal(s.TestInfo.FirstLiveArtists)
@@ -212,27 +219,31 @@ func (s *Simple) testStaticLive(n *html.Node, path string, testDocument []byte)
 			actual = []byte(fmt.Sprintf("%v", firstLive.Artists))
 			err = nil
 		}
-		err = fmt.Errorf("expected artists %v, got %v", string(expected), string(actual))
+		err = fmt.Errorf("expected artists %v, got %v%s", string(expected), string(actual), formatTraces(firstTraces, "artists", "detail"))
 		return
 	}
 	if firstLive.Title != s.TestInfo.FirstLiveTitle {
-		err = fmt.Errorf("expected title %s, got %s", s.TestInfo.FirstLiveTitle, firstLive.Title)
+		err = fmt.Errorf("expected title %s, got %s%s", s.TestInfo.FirstLiveTitle, firstLive.Title, formatTraces(firstTraces, "title"))
 		return
 	}
 	if firstLive.Price != s.TestInfo.FirstLivePrice {
-		err = fmt.Errorf("expected price %s, got %s", s.TestInfo.FirstLivePrice, firstLive.Price)
+		err = fmt.Errorf("expected price %s, got %s%s", s.TestInfo.FirstLivePrice, firstLive.Price, formatTraces(firstTraces, "price", "detail"))
 		return
 	}
 	if firstLive.PriceEnglish != s.TestInfo.FirstLivePriceEnglish {
-		err = fmt.Errorf("expected english price %s, got %s", s.TestInfo.FirstLivePriceEnglish, firstLive.PriceEnglish)
+		err = fmt.Errorf("expected english price %s, got %s%s", s.TestInfo.FirstLivePriceEnglish, firstLive.PriceEnglish, formatTraces(firstTraces, "price", "detail"))
 		return
 	}
 	if firstLive.OpenTime.Unix() != s.TestInfo.FirstLiveOpenTime.Unix() {
-		err = fmt.Errorf("expected opentime %s, got %s", s.TestInfo.FirstLiveOpenTime, firstLive.OpenTime)
+		err = fmt.Errorf("expected opentime %s, got %s%s", s.TestInfo.FirstLiveOpenTime, firstLive.OpenTime, formatTraces(firstTraces, "year", "month", "day", "date", "open-time", "detail"))
 		return
 	}
 	if firstLive.StartTime.Unix() != s.TestInfo.FirstLiveStartTime.Unix() {
-		err = fmt.Errorf("expected starttime %s, got %s", s.TestInfo.FirstLiveStartTime, firstLive.StartTime)
+		err = fmt.Errorf("expected starttime %s, got %s%s", s.TestInfo.FirstLiveStartTime, firstLive.StartTime, formatTraces(firstTraces, "year", "month", "day", "date", "start-time", "detail"))
+		return
+	}
+	if firstLive.OpenTimeUnknown != s.TestInfo.FirstLiveTimeUnknown || firstLive.StartTimeUnknown != s.TestInfo.FirstLiveTimeUnknown {
+		err = fmt.Errorf("expected time unknown to be %t, got open %t and start %t%s", s.TestInfo.FirstLiveTimeUnknown, firstLive.OpenTimeUnknown, firstLive.StartTimeUnknown, formatTraces(firstTraces, "open-time", "start-time", "detail"))
 		return
 	}
 	if s.InitialURL != "" && firstLive.URL != s.TestInfo.FirstLiveURL {
@@ -256,7 +267,7 @@ func (s *Simple) testNotEmpty(n *html.Node, path string) (err error) {
 	if err != nil {
 		return
 	}
-	l, err := s.fetchLives(n, pathURL, nil)
+	l, err := s.fetchLives(context.Background(), n, pathURL, nil)
 	if err != nil {
 		return
 	}
@@ -369,3 +380,16 @@ func (s *Simple) testNotEmpty(n *html.Node, path string) (err error) {
 
 	return
 }
+
+// formatTraces formats the traces of the given queriers of a live, for showing which filter mangled a value.
+func formatTraces(traces Traces, names ...string) string {
+	var b strings.Builder
+	for _, name := range names {
+		trace, ok := traces[name]
+		if !ok {
+			continue
+		}
+		b.WriteString("\n" + name + ": " + trace.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,245 @@
+package htmlquerier
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// Capture adds a filter that replaces each string with the named capture group of the first match of the regular
+// expression exp, or with the whole match if group is empty. Strings not matching exp are replaced with empty string.
+//
+// This replaces chains such as SplitRegexIndex, After and Before that only serve to isolate part of a string.
+// Capture panics if exp is invalid or does not contain group, use a Spec to get an error instead.
+func (q *Querier) Capture(exp, group string) *Querier {
+	re, err := compileCapture(exp, group)
+	if err != nil {
+		panic("htmlquerier: " + err.Error())
+	}
+	return q.AddFilter(func(s string) string {
+		m := re.FindStringSubmatch(s)
+		if m == nil {
+			return ""
+		}
+		if group == "" {
+			return m[0]
+		}
+		return m[re.SubexpIndex(group)]
+	}).describe("Capture", exp, group)
+}
+
+func compileCapture(exp, group string) (re *regexp.Regexp, err error) {
+	re, err = regexp.Compile(exp)
+	if err != nil {
+		return
+	}
+	if group != "" && re.SubexpIndex(group) == -1 {
+		err = fmt.Errorf("%s has no capture group named %s", exp, group)
+	}
+	return
+}
+
+// Date is a date captured by DateFrom, where parts that were not captured are zero.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// String formats the date as YYYY-MM-DD, which is what DateFrom replaces strings with.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// ParseDate parses a date formatted by DateFrom.
+func ParseDate(s string) (d Date, err error) {
+	_, err = fmt.Sscanf(s, "%4d-%2d-%2d", &d.Year, &d.Month, &d.Day)
+	if err != nil {
+		err = fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return
+}
+
+var monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// DateFrom adds a filter that replaces each string with the date captured by the named groups year, month and day of
+// the regular expression exp, such as (?P<month>\d+)/(?P<day>\d+), formatted as a Date.
+// Strings not matching exp, or containing an invalid date, are replaced with empty string.
+//
+// Fullwidth numbers are matched as halfwidth ones, months may also be English month names, and two digit years are in the 2000s.
+// A querier ending with DateFrom is meant to be used as TimeHandler.DateQuerier.
+// DateFrom panics if exp is invalid or captures none of year, month and day, use a Spec to get an error instead.
+func (q *Querier) DateFrom(exp string) *Querier {
+	re, err := compileDate(exp)
+	if err != nil {
+		panic("htmlquerier: " + err.Error())
+	}
+	return q.AddFilter(func(s string) string {
+		m := re.FindStringSubmatch(width.Fold.String(s))
+		if m == nil {
+			return ""
+		}
+		var d Date
+		var ok bool
+		if i := re.SubexpIndex("year"); i != -1 {
+			d.Year, ok = parseDatePart(m[i], 0, 9999)
+			if !ok {
+				return ""
+			}
+			if d.Year < 100 {
+				d.Year += 2000
+			}
+		}
+		if i := re.SubexpIndex("month"); i != -1 {
+			d.Month, ok = parseMonth(m[i])
+			if !ok {
+				return ""
+			}
+		}
+		if i := re.SubexpIndex("day"); i != -1 {
+			d.Day, ok = parseDatePart(m[i], 1, 31)
+			if !ok {
+				return ""
+			}
+		}
+		return d.String()
+	}).describe("DateFrom", exp)
+}
+
+func compileDate(exp string) (re *regexp.Regexp, err error) {
+	re, err = regexp.Compile(exp)
+	if err != nil {
+		return
+	}
+	if re.SubexpIndex("year") == -1 && re.SubexpIndex("month") == -1 && re.SubexpIndex("day") == -1 {
+		err = fmt.Errorf("%s has no capture group named year, month or day", exp)
+	}
+	return
+}
+
+func parseDatePart(s string, lo, hi int) (n int, ok bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	return n, err == nil && n >= lo && n <= hi
+}
+
+func parseMonth(s string) (month int, ok bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range monthNames {
+		if strings.HasPrefix(s, name) {
+			return i + 1, true
+		}
+	}
+	return parseDatePart(s, 1, 12)
+}
+
+// timeLabels are the labels of the open and start times, lowercased.
+var timeLabels = map[int][]string{
+	openTime:  {"開場", "open", "doors", "door", "オープン"},
+	startTime: {"開演", "start", "スタート"},
+}
+
+const (
+	openTime  = 1
+	startTime = 2
+)
+
+// timeExp matches a time such as 18:30, or 翌1:00 for 25:00.
+const timeExp = `(翌\s*)?(\d{1,2})\s*:\s*(\d{2})`
+
+var (
+	kanjiTimePattern = regexp.MustCompile(`(\d{1,2})時(?:(\d{1,2})分|(半))?`)
+	timePattern      = regexp.MustCompile(timeExp)
+	// combinedTimePattern matches both times written after both labels, such as OPEN/START 18:30/19:00
+	combinedTimePattern = regexp.MustCompile(`(?:` + labelExp(openTime) + `)\s*[/・&|]\s*(?:` + labelExp(startTime) + `)\s*:?\s*` + timeExp + `\s*[/・~〜-]\s*` + timeExp)
+	labelPattern        = regexp.MustCompile(labelExp(openTime) + "|" + labelExp(startTime))
+	// labelTimePatterns match a time written after its label, such as 開場 18:30
+	labelTimePatterns = map[int]*regexp.Regexp{
+		openTime:  regexp.MustCompile(`(?:` + labelExp(openTime) + `)[\s:/]*` + timeExp),
+		startTime: regexp.MustCompile(`(?:` + labelExp(startTime) + `)[\s:/]*` + timeExp),
+	}
+	// timeLabelPatterns match a time written before its label, such as 18:30開場
+	timeLabelPatterns = map[int]*regexp.Regexp{
+		openTime:  regexp.MustCompile(timeExp + `\s*(?:` + labelExp(openTime) + `)`),
+		startTime: regexp.MustCompile(timeExp + `\s*(?:` + labelExp(startTime) + `)`),
+	}
+)
+
+func labelExp(kind int) string {
+	labels := make([]string, len(timeLabels[kind]))
+	for i, label := range timeLabels[kind] {
+		labels[i] = regexp.QuoteMeta(label)
+	}
+	return strings.Join(labels, "|")
+}
+
+// OpenTime adds a filter that replaces each string with the open time labelled 開場 or OPEN in it, formatted as xx:xx.
+// Strings without a labelled open time are replaced with empty string.
+//
+// Times may be written before or after their labels, as in 18:30開場 or OPEN 18:30, or both after both labels, as in
+// OPEN/START 18:30/19:00. Fullwidth numbers and times such as 18時半 are understood, and times past midnight keep
+// 24+ hour notation, with 翌1:00 becoming 25:00, which the core moves to the next day.
+func (q *Querier) OpenTime() *Querier {
+	return q.AddFilter(func(s string) string {
+		return findLabelledTime(s, openTime)
+	}).describe("OpenTime")
+}
+
+// StartTime adds a filter that replaces each string with the start time labelled 開演 or START in it, formatted as
+// xx:xx. See OpenTime for the formats understood.
+func (q *Querier) StartTime() *Querier {
+	return q.AddFilter(func(s string) string {
+		return findLabelledTime(s, startTime)
+	}).describe("StartTime")
+}
+
+func findLabelledTime(s string, kind int) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, strings.ToLower(width.Fold.String(s)))
+	s = kanjiTimePattern.ReplaceAllStringFunc(s, func(t string) string {
+		m := kanjiTimePattern.FindStringSubmatch(t)
+		minute := m[2]
+		if m[3] != "" {
+			minute = "30"
+		}
+		return fmt.Sprintf("%s:%02s", m[1], minute)
+	})
+
+	if m := combinedTimePattern.FindStringSubmatch(s); m != nil {
+		return formatTime(m[1+(kind-1)*3:])
+	}
+
+	label := labelPattern.FindStringIndex(s)
+	if label == nil {
+		return ""
+	}
+	// a page writes all its times either before or after their labels, which the first time and label tell
+	pattern := labelTimePatterns[kind]
+	if t := timePattern.FindStringIndex(s); t != nil && t[0] < label[0] {
+		pattern = timeLabelPatterns[kind]
+	}
+	m := pattern.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return formatTime(m[1:])
+}
+
+// formatTime formats the groups of timeExp as xx:xx.
+func formatTime(m []string) string {
+	hour, err := strconv.Atoi(m[1])
+	if err != nil {
+		return ""
+	}
+	if m[0] != "" && hour < 24 {
+		hour += 24
+	}
+	return fmt.Sprintf("%02d:%s", hour, m[2])
+}
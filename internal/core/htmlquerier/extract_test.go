@@ -0,0 +1,134 @@
+package htmlquerier_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+)
+
+// executeOn executes q on a document consisting of a single paragraph containing text.
+func executeOn(t *testing.T, q *htmlquerier.Querier, text string) string {
+	t.Helper()
+	n, err := htmlquery.Parse(strings.NewReader("<p>" + text + "</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr, err := q.Execute(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arr[0]
+}
+
+func TestCapture(t *testing.T) {
+	tests := []struct {
+		exp, group, text, expected string
+	}{
+		{`(?P<n>\d+)日`, "n", "3月14日(金)", "14"},
+		{`\d+`, "", "vol.12 開催", "12"},
+		{`(?P<n>\d+)日`, "n", "未定", ""},
+	}
+	for _, test := range tests {
+		res := executeOn(t, htmlquerier.Q("//p").Capture(test.exp, test.group), test.text)
+		if res != test.expected {
+			t.Errorf("Capture(%q, %q) on %q: expected %q, got %q", test.exp, test.group, test.text, test.expected, res)
+		}
+	}
+}
+
+func TestDateFrom(t *testing.T) {
+	tests := []struct {
+		exp, text, expected string
+	}{
+		{`(?P<month>\d+)/(?P<day>\d+)`, "3/14(金)", "0000-03-14"},
+		{`(?P<year>\d+)\.(?P<month>\d+)\.(?P<day>\d+)`, "24.12.01 SUN", "2024-12-01"},
+		{`(?P<year>\d+)年(?P<month>\d+)月(?P<day>\d+)日`, "２０２５年１月５日", "2025-01-05"},
+		{`(?P<day>\d+) (?P<month>[A-Za-z]+)`, "FRI 7 March", "0000-03-07"},
+		{`(?P<day>\d+)`, "14", "0000-00-14"},
+		{`(?P<month>\d+)/(?P<day>\d+)`, "13/14", ""},
+		{`(?P<month>\d+)/(?P<day>\d+)`, "TBA", ""},
+	}
+	for _, test := range tests {
+		res := executeOn(t, htmlquerier.Q("//p").DateFrom(test.exp), test.text)
+		if res != test.expected {
+			t.Errorf("DateFrom(%q) on %q: expected %q, got %q", test.exp, test.text, test.expected, res)
+		}
+		if res == "" {
+			continue
+		}
+		date, err := htmlquerier.ParseDate(res)
+		if err != nil {
+			t.Error(err)
+		}
+		if date.String() != res {
+			t.Errorf("expected ParseDate(%q) to round trip, got %s", res, date)
+		}
+	}
+}
+
+func TestLabelledTimes(t *testing.T) {
+	tests := []struct {
+		text, open, start string
+	}{
+		{"開場18:30 開演19:00", "18:30", "19:00"},
+		{"開場\u00a017:30\u00a0/\u00a0開演\u00a018:00", "17:30", "18:00"},
+		{"18:30開場 / 19:00開演", "18:30", "19:00"},
+		{"OPEN 18:30 / START 19:00", "18:30", "19:00"},
+		{"open/start 18:30/19:00", "18:30", "19:00"},
+		{"開場・開演 18:00〜18:30", "18:00", "18:30"},
+		{"ＯＰＥＮ１８：３０　ＳＴＡＲＴ１９：００", "18:30", "19:00"},
+		{"開場18時半 開演19時", "18:30", "19:00"},
+		{"OPEN 24:00 / START 24:30", "24:00", "24:30"},
+		{"開場23:30 開演翌0:30", "23:30", "24:30"},
+		{"START 19:00", "", "19:00"},
+		{"19:00", "", ""},
+	}
+	for _, test := range tests {
+		open := executeOn(t, htmlquerier.Q("//p").OpenTime(), test.text)
+		if open != test.open {
+			t.Errorf("OpenTime on %q: expected %q, got %q", test.text, test.open, open)
+		}
+		start := executeOn(t, htmlquerier.Q("//p").StartTime(), test.text)
+		if start != test.start {
+			t.Errorf("StartTime on %q: expected %q, got %q", test.text, test.start, start)
+		}
+	}
+}
+
+func TestExtractSpec(t *testing.T) {
+	tests := []struct {
+		filter         htmlquerier.FilterSpec
+		text, expected string
+	}{
+		{htmlquerier.FilterSpec{Op: "capture", Args: []any{`(?P<month>\d+)月`, "month"}}, "2025年3月", "3"},
+		{htmlquerier.FilterSpec{Op: "dateFrom", Args: []any{`(?P<month>\d+)/(?P<day>\d+)`}}, "3/14(金)", "0000-03-14"},
+		{htmlquerier.FilterSpec{Op: "openTime"}, "OPEN 18:30 / START 19:00", "18:30"},
+		{htmlquerier.FilterSpec{Op: "startTime"}, "OPEN 18:30 / START 19:00", "19:00"},
+	}
+	for _, test := range tests {
+		q, err := htmlquerier.Spec{Selector: "//p", Filters: []htmlquerier.FilterSpec{test.filter}}.Querier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := executeOn(t, &q, test.text)
+		if res != test.expected {
+			t.Errorf("%s %v on %q: expected %q, got %q", test.filter.Op, test.filter.Args, test.text, test.expected, res)
+		}
+	}
+}
+
+func TestExtractSpecErrors(t *testing.T) {
+	tests := []htmlquerier.FilterSpec{
+		{Op: "capture", Args: []any{`(\d+`, ""}},
+		{Op: "capture", Args: []any{`(?P<n>\d+)`, "m"}},
+		{Op: "dateFrom", Args: []any{`(\d+)/(\d+)`}},
+	}
+	for _, filter := range tests {
+		_, err := htmlquerier.Spec{Selector: "//p", Filters: []htmlquerier.FilterSpec{filter}}.Querier()
+		if err == nil {
+			t.Errorf("expected %s %v to be rejected", filter.Op, filter.Args)
+		}
+	}
+}
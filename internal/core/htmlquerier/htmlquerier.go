@@ -5,6 +5,8 @@ package htmlquerier
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/antchfx/htmlquery"
@@ -34,13 +36,20 @@ type Querier struct {
 	// Whether to print extra logs
 	verbose bool
 	// filters contains the filters to apply to modify result.
-	filters []func([]string) []string
+	filters []filter
+}
+
+// filter is a filter of a Querier, along with a description of the method that added it, used in traces.
+type filter struct {
+	name string
+	fn   func([]string) []string
 }
 
 // QAll creates a pointer to a Querier struct.
 // A Querier struct initialized using QAll will fetch all instances of the selector, get the string within, and assign them all to arr.
 //
-// Any basic filters specified will be applied individually on each match
+// Any basic filters specified will be applied individually on each match.
+// selector is an XPath expression, or a CSS selector when prefixed with CSSPrefix.
 func QAll(selector string) *Querier {
 	return &Querier{
 		selector:    selector,
@@ -51,6 +60,8 @@ func QAll(selector string) *Querier {
 
 // Q creates a pointer to a Querier struct.
 // A Querier struct initialized using Q will only select the first match and get the string from that.
+//
+// selector is an XPath expression, or a CSS selector when prefixed with CSSPrefix.
 func Q(selector string) *Querier {
 	return &Querier{
 		selector:    selector,
@@ -58,6 +69,19 @@ func Q(selector string) *Querier {
 	}
 }
 
+// Selector returns the selector the querier fetches its initial string(s) from.
+func (q Querier) Selector() string {
+	return q.selector
+}
+
+// WithSelector returns a copy of the querier that fetches its initial string(s) from another selector, keeping its
+// filters. This lets the same querier be run with an XPath expression and its CSS equivalent.
+func (q Querier) WithSelector(selector string) Querier {
+	q.selector = selector
+	q.filters = slices.Clone(q.filters)
+	return q
+}
+
 // PreserveWhitespace disables the normalization of whitespace
 func (q *Querier) PreserveWhitespace() *Querier {
 	q.preserveWhitespace = true
@@ -87,28 +111,28 @@ func normalizeWhitespace(s string) string {
 func (q *Querier) NormalizeWhitespace() *Querier {
 	return q.AddFilter(func(s string) string {
 		return normalizeWhitespace(s)
-	})
+	}).describe("NormalizeWhitespace")
 }
 
 // Trim adds a filter to the querier that removes any leading and trailing whitespace.
 func (q *Querier) Trim() *Querier {
 	return q.AddFilter(func(s string) string {
 		return trim(s)
-	})
+	}).describe("Trim")
 }
 
 // TrimPrefix adds a filter to the querier that removes a specific prefix from the string.
 func (q *Querier) TrimPrefix(prefix string) *Querier {
 	return q.AddFilter(func(s string) string {
 		return strings.TrimPrefix(s, prefix)
-	})
+	}).describe("TrimPrefix", prefix)
 }
 
 // TrimSuffix adds a filter to the querier that removes a specific suffix from the string.
 func (q *Querier) TrimSuffix(suffix string) *Querier {
 	return q.AddFilter(func(s string) string {
 		return strings.TrimSuffix(s, suffix)
-	})
+	}).describe("TrimSuffix", suffix)
 }
 
 // CutWrapper adds a filter to the querier that removes a wrapping prefix and suffix only if both are present.
@@ -118,7 +142,7 @@ func (q *Querier) CutWrapper(prefix, suffix string) *Querier {
 			s = s[len(prefix) : len(s)-len(suffix)]
 		}
 		return s
-	})
+	}).describe("CutWrapper", prefix, suffix)
 }
 
 // BeforeSelector sets an endSelector, and will ensure that only text before the selector specified is selected.
@@ -129,6 +153,25 @@ func (q *Querier) BeforeSelector(selector string) *Querier {
 
 // Execute executes the query. This is only used internally in the core, please do not call this in connectors.
 func (q *Querier) Execute(n *html.Node) (a []string, err error) {
+	return q.execute(n, nil)
+}
+
+// ExecuteTrace executes the query like Execute, additionally recording what the selector matched and what every
+// filter did in trace. Tracing copies the strings before every filter, so only use it when debugging or testing.
+func (q *Querier) ExecuteTrace(n *html.Node) (a []string, trace Trace, err error) {
+	a, err = q.execute(n, &trace)
+	return
+}
+
+// execute executes the query, recording its trace in trace if it is not nil.
+func (q *Querier) execute(n *html.Node, trace *Trace) (a []string, err error) {
+	if trace != nil {
+		trace.Selector = q.selector
+		defer func() {
+			trace.Result = a
+		}()
+	}
+
 	if n == nil {
 		a = []string{""}
 		err = fmt.Errorf("node is nil for selector %s", q.selector)
@@ -137,7 +180,7 @@ func (q *Querier) Execute(n *html.Node) (a []string, err error) {
 
 	if q.selectAll {
 		var res []*html.Node
-		res, err = htmlquery.QueryAll(n, q.selector)
+		res, err = QueryAll(n, q.selector)
 		if err != nil || res == nil || len(res) == 0 {
 			a = []string{""}
 			return
@@ -147,18 +190,26 @@ func (q *Querier) Execute(n *html.Node) (a []string, err error) {
 			strs = append(strs, htmlquery.InnerText(artistNode))
 		}
 		q.arr = strs
+		if trace != nil {
+			trace.Matches = len(res)
+		}
 	} else {
 		var res *html.Node
-		res, err = htmlquery.Query(n, q.selector)
+		res, err = Query(n, q.selector)
 		if err != nil || res == nil {
 			a = []string{""}
 			return
 		}
 		q.arr = []string{htmlquery.InnerText(res)}
+		if trace != nil {
+			// count every match, as a selector matching more than intended is a common mistake
+			all, _ := QueryAll(n, q.selector)
+			trace.Matches = len(all)
+		}
 
 		if q.endSelector != "" {
 			var end *html.Node
-			end, err = htmlquery.Query(res, q.endSelector)
+			end, err = Query(res, q.endSelector)
 			if err == nil && end != nil {
 				for i := range q.arr {
 					q.arr[i] = strings.Split(q.arr[i], htmlquery.InnerText(end))[0]
@@ -168,8 +219,22 @@ func (q *Querier) Execute(n *html.Node) (a []string, err error) {
 		}
 	}
 
-	for _, filter := range q.filters {
-		q.arr = filter(q.arr)
+	if trace != nil {
+		trace.Selected = slices.Clone(q.arr)
+	}
+	for _, f := range q.filters {
+		if trace == nil {
+			q.arr = f.fn(q.arr)
+			continue
+		}
+		// filters may modify the slice in place, so the input is copied before applying them
+		in := slices.Clone(q.arr)
+		q.arr = f.fn(q.arr)
+		trace.Steps = append(trace.Steps, TraceStep{
+			Filter: f.name,
+			In:     in,
+			Out:    slices.Clone(q.arr),
+		})
 	}
 
 	newArr := make([]string, 0)
@@ -202,7 +267,7 @@ func (q *Querier) AddFilter(fn func(string) string) *Querier {
 		}
 		return arr
 	})
-	return q
+	return q.describe("AddFilter")
 }
 
 // AddComplexFilter adds a filter that takes the full slice of strings, and returns a new slice.
@@ -210,7 +275,24 @@ func (q *Querier) AddFilter(fn func(string) string) *Querier {
 //
 // Make sure not to return an empty slice, at minimum return slice containing a single entry with empty string.
 func (q *Querier) AddComplexFilter(fn func([]string) []string) *Querier {
-	q.filters = append(q.filters, fn)
+	q.filters = append(q.filters, filter{name: "AddComplexFilter", fn: fn})
+	return q
+}
+
+// describe names the last filter added after the method that added it and its arguments, such as Split(" / ").
+func (q *Querier) describe(method string, args ...any) *Querier {
+	desc := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			desc[i] = strconv.Quote(v)
+		case rune:
+			desc[i] = strconv.QuoteRune(v)
+		default:
+			desc[i] = fmt.Sprint(v)
+		}
+	}
+	q.filters[len(q.filters)-1].name = method + "(" + strings.Join(desc, ", ") + ")"
 	return q
 }
 
@@ -218,7 +300,7 @@ func (q *Querier) AddComplexFilter(fn func([]string) []string) *Querier {
 func (q *Querier) Split(sep string) *Querier {
 	return q.AddSplitter(func(s string) []string {
 		return strings.Split(s, sep)
-	})
+	}).describe("Split", sep)
 }
 
 // SplitIgnoreWithin adds a splitter that splits using a given separator, while ignoring that separator if its within a set of left and right brackets.
@@ -276,7 +358,7 @@ func (q *Querier) SplitIgnoreWithin(sep string, l, r rune) *Querier {
 			}
 		}
 		return res
-	})
+	}).describe("SplitIgnoreWithin", sep, l, r)
 }
 
 // SplitRegex adds a splitter that splits using a regular expression exp.
@@ -287,7 +369,7 @@ func (q *Querier) SplitRegex(exp string) *Querier {
 			return []string{s}
 		}
 		return re.Split(s, -1)
-	})
+	}).describe("SplitRegex", exp)
 }
 
 // SplitIndex adds a splitter that splits using a string, but only returns the entry at index i, or empty string if index i doesnt exist.
@@ -298,7 +380,7 @@ func (q *Querier) SplitIndex(sep string, i int) *Querier {
 			return []string{arr[i]}
 		}
 		return []string{""}
-	})
+	}).describe("SplitIndex", sep, i)
 }
 
 // SplitRegexIndex works like SplitIndex, except using regex.
@@ -313,7 +395,7 @@ func (q *Querier) SplitRegexIndex(exp string, i int) *Querier {
 			return []string{arr[i]}
 		}
 		return []string{""}
-	})
+	}).describe("SplitRegexIndex", exp, i)
 }
 
 // AddSplitter adds a splitter filter for the Querier, which iterates over the slice, and may or may not turn the entry into multiple entries.
@@ -325,7 +407,7 @@ func (q *Querier) AddSplitter(fn func(string) []string) *Querier {
 		}
 		return newArr
 	})
-	return q
+	return q.describe("AddSplitter")
 }
 
 // After adds a filter that removes any text before and including the first instance of given separator sep.
@@ -336,7 +418,7 @@ func (q *Querier) After(sep string) *Querier {
 			return s
 		}
 		return arr[1]
-	})
+	}).describe("After", sep)
 }
 
 // Before adds a filter that removes any text after and including the first instance of given separator sep.
@@ -347,7 +429,7 @@ func (q *Querier) Before(sep string) *Querier {
 			return s
 		}
 		return arr[0]
-	})
+	}).describe("Before", sep)
 }
 
 // HalfWidth adds a filter that forces fullwidth alphanumeric characters to halfwidth characters.
@@ -355,14 +437,14 @@ func (q *Querier) Before(sep string) *Querier {
 func (q *Querier) HalfWidth() *Querier {
 	return q.AddFilter(func(s string) string {
 		return width.Narrow.String(s)
-	})
+	}).describe("HalfWidth")
 }
 
 // ReplaceAll adds a filter that replaces all instances of a string old with string new.
 func (q *Querier) ReplaceAll(old, new string) *Querier {
 	return q.AddFilter(func(s string) string {
 		return strings.ReplaceAll(s, old, new)
-	})
+	}).describe("ReplaceAll", old, new)
 }
 
 // ReplaceAll adds a filter that replaces all instances of a regular expression exp with string new.
@@ -374,14 +456,14 @@ func (q *Querier) ReplaceAllRegex(exp, new string) *Querier {
 			return s
 		}
 		return re.ReplaceAllString(s, new)
-	})
+	}).describe("ReplaceAllRegex", exp, new)
 }
 
 // Prefix adds a filter that adds a prefix p in front of string.
 func (q *Querier) Prefix(p string) *Querier {
 	return q.AddFilter(func(s string) string {
 		return fmt.Sprintf(p + s)
-	})
+	}).describe("Prefix", p)
 }
 
 // DeleteFrom adds a complex filter that deletes every item starting at an item with specific value
@@ -395,7 +477,7 @@ func (q *Querier) DeleteFrom(s string) *Querier {
 			new = append(new, cur)
 		}
 		return new
-	})
+	}).describe("DeleteFrom", s)
 }
 
 // DeleteUntil adds a complex filter that deletes every item until and including an item with specific value
@@ -413,7 +495,7 @@ func (q *Querier) DeleteUntil(s string) *Querier {
 			}
 		}
 		return new
-	})
+	}).describe("DeleteUntil", s)
 }
 
 func stringHasTitleIndicator(s string) bool {
@@ -493,7 +575,7 @@ func getArtist(a []string, exp string, i int) string {
 func (q *Querier) FilterTitle(exp string, i int) *Querier {
 	return q.AddComplexFilter(func(old []string) []string {
 		return []string{getTitle(old, exp, 1-i)}
-	})
+	}).describe("FilterTitle", exp, i)
 }
 
 // FilterArtist is meant to be run on a querier that has fetched title and artist, without knowing which.
@@ -505,7 +587,7 @@ func (q *Querier) FilterTitle(exp string, i int) *Querier {
 func (q *Querier) FilterArtist(exp string, i int) *Querier {
 	return q.AddComplexFilter(func(old []string) []string {
 		return []string{getArtist(old, exp, i)}
-	})
+	}).describe("FilterArtist", exp, i)
 }
 
 // KeepIndex keeps only the element at specific index, or empty string if does not exist. Negative index will get index starting from last index.
@@ -521,12 +603,12 @@ func (q *Querier) KeepIndex(i int) *Querier {
 			return []string{old[i]}
 		}
 		return []string{""}
-	})
+	}).describe("KeepIndex", i)
 }
 
 // Concat concatenates all the strings from the slice to one using a separator sep
 func (q *Querier) Join(sep string) *Querier {
 	return q.AddComplexFilter(func(old []string) []string {
 		return []string{strings.Join(old, sep)}
-	})
+	}).describe("Join", sep)
 }
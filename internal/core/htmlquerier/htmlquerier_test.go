@@ -310,3 +310,47 @@ func testStringSliceEquals(t *testing.T, expected, res []string) {
 		}
 	}
 }
+
+func TestExecuteTrace(t *testing.T) {
+	q, n := createQuerier(t, "//p[@id='splitter']")
+	arr, trace, err := q.Split(" - ").KeepIndex(1).Prefix("x").ExecuteTrace(n)
+	if err != nil {
+		t.Error(err)
+	}
+	testStringSliceEquals(t, []string{"xtwo"}, arr)
+	testStringSliceEquals(t, []string{"xtwo"}, trace.Result)
+	if trace.Matches != 1 {
+		t.Errorf("expected 1 match, got %d", trace.Matches)
+	}
+	if len(trace.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(trace.Steps))
+	}
+	if trace.Steps[0].Filter != `Split(" - ")` || trace.Steps[1].Filter != "KeepIndex(1)" {
+		t.Errorf("unexpected filter names %s, %s", trace.Steps[0].Filter, trace.Steps[1].Filter)
+	}
+	testStringSliceEquals(t, []string{"one", "two", "three"}, trace.Steps[1].In)
+	testStringSliceEquals(t, []string{"two"}, trace.Steps[1].Out)
+	// AddFilter modifies the slice in place, which must not affect the recorded input
+	testStringSliceEquals(t, []string{"two"}, trace.Steps[2].In)
+	testStringSliceEquals(t, []string{"xtwo"}, trace.Steps[2].Out)
+}
+
+func TestExecuteTraceMatches(t *testing.T) {
+	q, n := createQuerier(t, "//p")
+	_, trace, err := q.ExecuteTrace(n)
+	if err != nil {
+		t.Error(err)
+	}
+	if trace.Matches < 2 {
+		t.Errorf("expected all matches to be counted, got %d", trace.Matches)
+	}
+
+	q, n = createQuerier(t, "//p[@id='missing']")
+	_, trace, err = q.Trim().ExecuteTrace(n)
+	if err != nil {
+		t.Error(err)
+	}
+	if trace.Matches != 0 || len(trace.Steps) != 0 {
+		t.Errorf("expected no matches or steps, got %+v", trace)
+	}
+}
@@ -0,0 +1,63 @@
+package htmlquerier
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// CSSPrefix marks a selector as a CSS selector rather than an XPath expression, such as "css:article.schedule-item h2".
+//
+// Every selector in livefetcher, including those of Q, QAll and the selectors of fetchers.Simple, accepts both.
+// CSS selectors only match descendants of the node they are run against, like XPath selectors starting with //.
+const CSSPrefix = "css:"
+
+// compiled CSS selectors, as the same selectors are run against every live.
+// Selector groups such as "h1, h2" are supported. Unlike XPath unions, which list the nodes of each expression in turn,
+// they match in document order.
+var cssSelectors sync.Map
+
+func compileCSS(selector string) (sel cascadia.Matcher, err error) {
+	if cached, ok := cssSelectors.Load(selector); ok {
+		return cached.(cascadia.Matcher), nil
+	}
+	sel, err = cascadia.ParseGroup(selector)
+	if err != nil {
+		return
+	}
+	cssSelectors.Store(selector, sel)
+	return
+}
+
+// IsCSS reports whether selector is a CSS selector.
+func IsCSS(selector string) bool {
+	return strings.HasPrefix(selector, CSSPrefix)
+}
+
+// Query returns the first node matching selector, which is an XPath expression unless prefixed with CSSPrefix.
+// It returns nil if nothing matches.
+func Query(n *html.Node, selector string) (*html.Node, error) {
+	if !IsCSS(selector) {
+		return htmlquery.Query(n, selector)
+	}
+	sel, err := compileCSS(strings.TrimPrefix(selector, CSSPrefix))
+	if err != nil {
+		return nil, err
+	}
+	return cascadia.Query(n, sel), nil
+}
+
+// QueryAll returns all nodes matching selector, which is an XPath expression unless prefixed with CSSPrefix.
+func QueryAll(n *html.Node, selector string) ([]*html.Node, error) {
+	if !IsCSS(selector) {
+		return htmlquery.QueryAll(n, selector)
+	}
+	sel, err := compileCSS(strings.TrimPrefix(selector, CSSPrefix))
+	if err != nil {
+		return nil, err
+	}
+	return cascadia.QueryAll(n, sel), nil
+}
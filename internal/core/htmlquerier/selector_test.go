@@ -0,0 +1,117 @@
+package htmlquerier_test
+
+import (
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+)
+
+// cssParity pairs the XPath selectors used in the querier tests with equivalent CSS selectors.
+var cssParity = []struct {
+	xpath string
+	css   string
+}{
+	{"//p[@id='splitter']", "css:p#splitter"},
+	{"//p[@id='fullwidth']", "css:#fullwidth"},
+	{"//p[@id='wrapper']", "css:p[id=wrapper]"},
+	{"//p[@id='splitterlong']", "css:body > p#splitterlong"},
+	{"//p[@id='empty']", "css:p#empty"},
+	{"//p[@id='multisplit']", "css:p:nth-of-type(7)"},
+	{"//p[@id='splitignorewithin']", "css:p[id^=splitignore]"},
+	{"//p[@id='complex']", "css:p#complex"},
+	{"//p[@id='complex']/span", "css:#complex span"},
+	{"//p", "css:p"},
+	{"//p[@id='missing']", "css:p#missing"},
+	{"//p[@id='splitter'] | //p[@id='wrapper']", "css:p#wrapper, p#splitter"},
+}
+
+func TestCSSParity(t *testing.T) {
+	for _, test := range cssParity {
+		n, err := createBaseQuerier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		builders := map[string]func(selector string) *htmlquerier.Querier{
+			"Q": func(selector string) *htmlquerier.Querier {
+				return htmlquerier.Q(selector)
+			},
+			"QAll": func(selector string) *htmlquerier.Querier {
+				return htmlquerier.QAll(selector)
+			},
+			"QAll with filters": func(selector string) *htmlquerier.Querier {
+				return htmlquerier.QAll(selector).SplitRegex("[-/]").Trim().KeepIndex(-1)
+			},
+		}
+		for name, builder := range builders {
+			expected, err := builder(test.xpath).Execute(n)
+			if err != nil {
+				t.Error(err)
+			}
+			actual, err := builder(test.css).Execute(n)
+			if err != nil {
+				t.Error(err)
+			}
+			t.Run(name+" "+test.css, func(t *testing.T) {
+				testStringSliceEquals(t, expected, actual)
+			})
+		}
+
+		xpathNodes, err := htmlquerier.QueryAll(n, test.xpath)
+		if err != nil {
+			t.Error(err)
+		}
+		cssNodes, err := htmlquerier.QueryAll(n, test.css)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(xpathNodes) != len(cssNodes) {
+			t.Errorf("%s matched %d nodes, %s matched %d nodes", test.xpath, len(xpathNodes), test.css, len(cssNodes))
+			continue
+		}
+		for i := range xpathNodes {
+			if xpathNodes[i] != cssNodes[i] {
+				t.Errorf("%s and %s matched different nodes at index %d", test.xpath, test.css, i)
+			}
+		}
+	}
+}
+
+func TestCSSBeforeSelector(t *testing.T) {
+	q, n := createQuerier(t, "css:p#complex")
+	arr, err := q.BeforeSelector("css:span").Execute(n)
+	if err != nil {
+		t.Error(err)
+	}
+	testStringSliceEquals(t, []string{"onetwo"}, arr)
+}
+
+func TestCSSRelative(t *testing.T) {
+	n, err := createBaseQuerier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := htmlquerier.Query(n, "css:p#complex")
+	if err != nil || p == nil {
+		t.Fatalf("expected p#complex to match, got %v", err)
+	}
+	// like XPath, CSS selectors only match descendants of the node they are run against
+	self, err := htmlquerier.Query(p, "css:p")
+	if err != nil || self != nil {
+		t.Errorf("expected node itself not to match, got %v, %v", self, err)
+	}
+	span, err := htmlquerier.Query(p, "css:span")
+	if err != nil || span == nil {
+		t.Errorf("expected span to match, got %v", err)
+	}
+}
+
+func TestCSSInvalid(t *testing.T) {
+	n, err := createBaseQuerier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = htmlquerier.QueryAll(n, "css:p[")
+	if err == nil {
+		t.Error("expected invalid selector to return error")
+	}
+}
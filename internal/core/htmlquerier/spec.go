@@ -0,0 +1,360 @@
+package htmlquerier
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Spec is a declarative description of a Querier, used by connectors defined in connector files.
+//
+// Filters are applied in order, each one corresponding to the Querier method of the same name.
+// For instance, Q("//h2").Split(" / ").KeepIndex(0) is written as
+//
+//	selector = "//h2"
+//	filters = [
+//		{ op = "split", args = [" / "] },
+//		{ op = "keepIndex", args = [0] },
+//	]
+type Spec struct {
+	// Selector is the selector passed to Q or QAll.
+	Selector string `toml:"selector"`
+	// All specifies whether to select all matches like QAll, rather than only the first match like Q.
+	All bool `toml:"all"`
+	// PreserveWhitespace corresponds to Querier.PreserveWhitespace.
+	PreserveWhitespace bool `toml:"preserve-whitespace"`
+	// BeforeSelector corresponds to Querier.BeforeSelector.
+	BeforeSelector string `toml:"before-selector"`
+	// Filters are the filters applied to the result, in order.
+	Filters []FilterSpec `toml:"filters"`
+}
+
+// FilterSpec is a single filter of a Spec.
+type FilterSpec struct {
+	// Op is the name of the Querier method adding the filter, such as "split" or "replaceAllRegex".
+	//
+	// Use "named" with the name of a filter registered using RegisterFilter to apply a custom filter.
+	Op string `toml:"op"`
+	// Args are the arguments passed to the method, in order.
+	Args []any `toml:"args"`
+}
+
+var (
+	namedFiltersMu sync.RWMutex
+	namedFilters   = make(map[string]func([]string) []string)
+)
+
+// RegisterFilter registers a custom filter, which connector files can apply using the "named" op.
+//
+// This is how filters that cannot be expressed using the built in filters are made available to connector files.
+// Registering a filter under a name already in use replaces the existing filter.
+func RegisterFilter(name string, fn func([]string) []string) {
+	namedFiltersMu.Lock()
+	defer namedFiltersMu.Unlock()
+	namedFilters[name] = fn
+}
+
+// Named adds the filter registered under name using RegisterFilter.
+//
+// Named panics if no filter has been registered under name, use a Spec to get an error instead.
+func (q *Querier) Named(name string) *Querier {
+	fn, ok := lookupFilter(name)
+	if !ok {
+		panic("htmlquerier: no filter registered as " + name)
+	}
+	return q.AddComplexFilter(fn).describe("Named", name)
+}
+
+func lookupFilter(name string) (fn func([]string) []string, ok bool) {
+	namedFiltersMu.RLock()
+	defer namedFiltersMu.RUnlock()
+	fn, ok = namedFilters[name]
+	return
+}
+
+// filterArgs reads the arguments of a FilterSpec, converting them to the types expected by the Querier method.
+type filterArgs struct {
+	op   string
+	args []any
+	err  error
+}
+
+func (fa *filterArgs) string(i int) (s string) {
+	if fa.err != nil {
+		return
+	}
+	s, ok := fa.args[i].(string)
+	if !ok {
+		fa.err = fmt.Errorf("%s: argument %d must be a string, got %v", fa.op, i+1, fa.args[i])
+	}
+	return
+}
+
+func (fa *filterArgs) int(i int) (n int) {
+	if fa.err != nil {
+		return
+	}
+	switch v := fa.args[i].(type) {
+	case int64:
+		n = int(v)
+	case int:
+		n = v
+	default:
+		fa.err = fmt.Errorf("%s: argument %d must be an integer, got %v", fa.op, i+1, fa.args[i])
+	}
+	return
+}
+
+// regex reads a regular expression, which is compiled to catch invalid expressions when the spec is loaded, rather than
+// have the filter leave every value as is when it runs.
+func (fa *filterArgs) regex(i int) (exp string) {
+	exp = fa.string(i)
+	if fa.err != nil {
+		return
+	}
+	_, err := regexp.Compile(exp)
+	if err != nil {
+		fa.err = fmt.Errorf("%s: argument %d must be a valid regular expression: %w", fa.op, i+1, err)
+	}
+	return
+}
+
+func (fa *filterArgs) rune(i int) (r rune) {
+	s := fa.string(i)
+	if fa.err != nil {
+		return
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		fa.err = fmt.Errorf("%s: argument %d must be a single character, got %q", fa.op, i+1, s)
+		return
+	}
+	r, _ = utf8.DecodeRuneInString(s)
+	return
+}
+
+type filterOp struct {
+	arity int
+	apply func(q *Querier, fa *filterArgs)
+}
+
+var filterOps = map[string]filterOp{
+	"trim":                {0, func(q *Querier, fa *filterArgs) { q.Trim() }},
+	"normalizeWhitespace": {0, func(q *Querier, fa *filterArgs) { q.NormalizeWhitespace() }},
+	"halfWidth":           {0, func(q *Querier, fa *filterArgs) { q.HalfWidth() }},
+	"trimPrefix":          {1, func(q *Querier, fa *filterArgs) { q.TrimPrefix(fa.string(0)) }},
+	"trimSuffix":          {1, func(q *Querier, fa *filterArgs) { q.TrimSuffix(fa.string(0)) }},
+	"cutWrapper":          {2, func(q *Querier, fa *filterArgs) { q.CutWrapper(fa.string(0), fa.string(1)) }},
+	"split":               {1, func(q *Querier, fa *filterArgs) { q.Split(fa.string(0)) }},
+	"splitIgnoreWithin":   {3, func(q *Querier, fa *filterArgs) { q.SplitIgnoreWithin(fa.string(0), fa.rune(1), fa.rune(2)) }},
+	"splitRegex":          {1, func(q *Querier, fa *filterArgs) { q.SplitRegex(fa.regex(0)) }},
+	"splitIndex":          {2, func(q *Querier, fa *filterArgs) { q.SplitIndex(fa.string(0), fa.int(1)) }},
+	"splitRegexIndex":     {2, func(q *Querier, fa *filterArgs) { q.SplitRegexIndex(fa.regex(0), fa.int(1)) }},
+	"after":               {1, func(q *Querier, fa *filterArgs) { q.After(fa.string(0)) }},
+	"before":              {1, func(q *Querier, fa *filterArgs) { q.Before(fa.string(0)) }},
+	"replaceAll":          {2, func(q *Querier, fa *filterArgs) { q.ReplaceAll(fa.string(0), fa.string(1)) }},
+	"replaceAllRegex":     {2, func(q *Querier, fa *filterArgs) { q.ReplaceAllRegex(fa.regex(0), fa.string(1)) }},
+	"prefix":              {1, func(q *Querier, fa *filterArgs) { q.Prefix(fa.string(0)) }},
+	"deleteFrom":          {1, func(q *Querier, fa *filterArgs) { q.DeleteFrom(fa.string(0)) }},
+	"deleteUntil":         {1, func(q *Querier, fa *filterArgs) { q.DeleteUntil(fa.string(0)) }},
+	"filterTitle":         {2, func(q *Querier, fa *filterArgs) { q.FilterTitle(fa.string(0), fa.int(1)) }},
+	"filterArtist":        {2, func(q *Querier, fa *filterArgs) { q.FilterArtist(fa.string(0), fa.int(1)) }},
+	"keepIndex":           {1, func(q *Querier, fa *filterArgs) { q.KeepIndex(fa.int(0)) }},
+	"join":                {1, func(q *Querier, fa *filterArgs) { q.Join(fa.string(0)) }},
+	"capture":             {2, applyCapture},
+	"dateFrom":            {1, applyDateFrom},
+	"openTime":            {0, func(q *Querier, fa *filterArgs) { q.OpenTime() }},
+	"startTime":           {0, func(q *Querier, fa *filterArgs) { q.StartTime() }},
+	"named":               {1, applyNamed},
+}
+
+func applyNamed(q *Querier, fa *filterArgs) {
+	name := fa.string(0)
+	if fa.err != nil {
+		return
+	}
+	fn, ok := lookupFilter(name)
+	if !ok {
+		fa.err = fmt.Errorf("named: no filter registered as %s", name)
+		return
+	}
+	q.AddComplexFilter(fn).describe("Named", name)
+}
+
+func applyCapture(q *Querier, fa *filterArgs) {
+	exp, group := fa.string(0), fa.string(1)
+	if fa.err != nil {
+		return
+	}
+	_, err := compileCapture(exp, group)
+	if err != nil {
+		fa.err = fmt.Errorf("capture: %w", err)
+		return
+	}
+	q.Capture(exp, group)
+}
+
+func applyDateFrom(q *Querier, fa *filterArgs) {
+	exp := fa.string(0)
+	if fa.err != nil {
+		return
+	}
+	_, err := compileDate(exp)
+	if err != nil {
+		fa.err = fmt.Errorf("dateFrom: %w", err)
+		return
+	}
+	q.DateFrom(exp)
+}
+
+// Querier creates the Querier described by the spec.
+//
+// An empty spec creates an uninitialized Querier, the same as leaving the querier out of a connector.
+func (s Spec) Querier() (q Querier, err error) {
+	if s.Selector == "" {
+		if len(s.Filters) != 0 {
+			err = fmt.Errorf("querier with filters is missing selector")
+		}
+		return
+	}
+
+	var qp *Querier
+	if s.All {
+		qp = QAll(s.Selector)
+	} else {
+		qp = Q(s.Selector)
+	}
+	if s.PreserveWhitespace {
+		qp.PreserveWhitespace()
+	}
+	if s.BeforeSelector != "" {
+		qp.BeforeSelector(s.BeforeSelector)
+	}
+
+	for _, filter := range s.Filters {
+		op, ok := filterOps[filter.Op]
+		if !ok {
+			err = fmt.Errorf("unknown filter %s", filter.Op)
+			return
+		}
+		if len(filter.Args) != op.arity {
+			err = fmt.Errorf("%s: expected %d arguments, got %d", filter.Op, op.arity, len(filter.Args))
+			return
+		}
+		fa := filterArgs{op: filter.Op, args: filter.Args}
+		op.apply(qp, &fa)
+		if fa.err != nil {
+			err = fa.err
+			return
+		}
+	}
+	q = *qp
+	return
+}
+
+// ParseSpec parses a querier written on a single line, as used by the debug command, such as
+//
+//	//h2 | split " / " | keepIndex 0
+//
+// Every filter is an op followed by its arguments, which are integers, double quoted strings, or bare words.
+// The selector only needs to be quoted if it contains |.
+func ParseSpec(line string) (spec Spec, err error) {
+	segments, err := splitUnquoted(line, '|')
+	if err != nil {
+		return
+	}
+	spec.Selector = strings.TrimSpace(segments[0])
+	if strings.HasPrefix(spec.Selector, `"`) {
+		spec.Selector, err = strconv.Unquote(spec.Selector)
+		if err != nil {
+			err = fmt.Errorf("invalid selector %s: %w", segments[0], err)
+			return
+		}
+	}
+	if spec.Selector == "" {
+		err = fmt.Errorf("missing selector")
+		return
+	}
+
+	for _, segment := range segments[1:] {
+		var tokens []any
+		tokens, err = tokenize(segment)
+		if err != nil {
+			return
+		}
+		if len(tokens) == 0 {
+			err = fmt.Errorf("empty filter")
+			return
+		}
+		op, ok := tokens[0].(string)
+		if !ok {
+			err = fmt.Errorf("invalid filter %v", tokens[0])
+			return
+		}
+		spec.Filters = append(spec.Filters, FilterSpec{Op: op, Args: tokens[1:]})
+	}
+	return
+}
+
+// splitUnquoted splits s on every sep that is not within double quotes.
+func splitUnquoted(s string, sep rune) (segments []string, err error) {
+	start, quoted, escaped := 0, false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == sep:
+			segments = append(segments, s[start:i])
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	if quoted {
+		err = fmt.Errorf("unterminated quote in %s", s)
+		return
+	}
+	segments = append(segments, s[start:])
+	return
+}
+
+// tokenize splits s into whitespace separated tokens, unquoting double quoted strings and converting integers to int64.
+func tokenize(s string) (tokens []any, err error) {
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return
+		}
+		if s[0] == '"' {
+			var quoted string
+			quoted, err = strconv.QuotedPrefix(s)
+			if err != nil {
+				err = fmt.Errorf("invalid string %s", s)
+				return
+			}
+			s = s[len(quoted):]
+			quoted, err = strconv.Unquote(quoted)
+			if err != nil {
+				return
+			}
+			tokens = append(tokens, quoted)
+			continue
+		}
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end == -1 {
+			end = len(s)
+		}
+		word := s[:end]
+		s = s[end:]
+		if n, parseErr := strconv.ParseInt(word, 10, 64); parseErr == nil {
+			tokens = append(tokens, n)
+		} else {
+			tokens = append(tokens, word)
+		}
+	}
+}
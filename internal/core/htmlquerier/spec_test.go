@@ -0,0 +1,140 @@
+package htmlquerier_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/htmlquerier"
+)
+
+func executeSpec(t *testing.T, spec htmlquerier.Spec) []string {
+	t.Helper()
+	n, err := createBaseQuerier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := spec.Querier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr, err := q.Execute(n)
+	if err != nil {
+		t.Error(err)
+	}
+	return arr
+}
+
+func TestSpecMatchesBuilder(t *testing.T) {
+	tests := []struct {
+		spec    htmlquerier.Spec
+		builder *htmlquerier.Querier
+	}{
+		{
+			htmlquerier.Spec{Selector: "//p[@id='complex']/text()", All: true, Filters: []htmlquerier.FilterSpec{
+				{Op: "split", Args: []any{"-"}},
+				{Op: "trim"},
+			}},
+			htmlquerier.QAll("//p[@id='complex']/text()").Split("-").Trim(),
+		},
+		{
+			htmlquerier.Spec{Selector: "//p[@id='splitignorewithin']", Filters: []htmlquerier.FilterSpec{
+				{Op: "splitIgnoreWithin", Args: []any{" / ", "（", "）"}},
+			}},
+			htmlquerier.Q("//p[@id='splitignorewithin']").SplitIgnoreWithin(" / ", '（', '）'),
+		},
+		{
+			htmlquerier.Spec{Selector: "//p[@id='multisplit']", Filters: []htmlquerier.FilterSpec{
+				{Op: "splitRegexIndex", Args: []any{"[/-]", int64(2)}},
+			}},
+			htmlquerier.Q("//p[@id='multisplit']").SplitRegexIndex("[/-]", 2),
+		},
+		{
+			htmlquerier.Spec{Selector: "//p[@id='splitter']", Filters: []htmlquerier.FilterSpec{
+				{Op: "split", Args: []any{" - "}},
+				{Op: "replaceAllRegex", Args: []any{"o", "0"}},
+				{Op: "keepIndex", Args: []any{int64(1)}},
+			}},
+			htmlquerier.Q("//p[@id='splitter']").Split(" - ").ReplaceAllRegex("o", "0").KeepIndex(1),
+		},
+	}
+	for _, test := range tests {
+		n, err := createBaseQuerier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := test.builder.Execute(n)
+		if err != nil {
+			t.Error(err)
+		}
+		testStringSliceEquals(t, expected, executeSpec(t, test.spec))
+	}
+}
+
+func TestSpecNamedFilter(t *testing.T) {
+	htmlquerier.RegisterFilter("spec-test-upper", func(a []string) []string {
+		for i, s := range a {
+			a[i] = strings.ToUpper(s)
+		}
+		return a
+	})
+	arr := executeSpec(t, htmlquerier.Spec{Selector: "//p[@id='splitter']", Filters: []htmlquerier.FilterSpec{
+		{Op: "split", Args: []any{" - "}},
+		{Op: "named", Args: []any{"spec-test-upper"}},
+	}})
+	testStringSliceEquals(t, []string{"ONE", "TWO", "THREE"}, arr)
+}
+
+func TestSpecErrors(t *testing.T) {
+	tests := []htmlquerier.Spec{
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "splat"}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "split"}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "keepIndex", Args: []any{"1"}}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "splitIgnoreWithin", Args: []any{"/", "((", ")"}}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "named", Args: []any{"spec-test-missing"}}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "splitRegex", Args: []any{"[/-"}}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "splitRegexIndex", Args: []any{"(", int64(0)}}}},
+		{Selector: "//p", Filters: []htmlquerier.FilterSpec{{Op: "replaceAllRegex", Args: []any{"a**", ""}}}},
+		{Filters: []htmlquerier.FilterSpec{{Op: "trim"}}},
+	}
+	for _, spec := range tests {
+		_, err := spec.Querier()
+		if err == nil {
+			t.Errorf("expected error for %+v", spec)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected htmlquerier.Spec
+	}{
+		{"//h2", htmlquerier.Spec{Selector: "//h2"}},
+		{`//p[@id='a b'] | split " / " | keepIndex 0`, htmlquerier.Spec{Selector: "//p[@id='a b']", Filters: []htmlquerier.FilterSpec{
+			{Op: "split", Args: []any{" / "}},
+			{Op: "keepIndex", Args: []any{int64(0)}},
+		}}},
+		{`"//h2 | //h3" | after 開演 | replaceAll "\"" "|"`, htmlquerier.Spec{Selector: "//h2 | //h3", Filters: []htmlquerier.FilterSpec{
+			{Op: "after", Args: []any{"開演"}},
+			{Op: "replaceAll", Args: []any{`"`, "|"}},
+		}}},
+	}
+	for _, test := range tests {
+		spec, err := htmlquerier.ParseSpec(test.line)
+		if err != nil {
+			t.Errorf("%s: %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(spec, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.line, test.expected, spec)
+		}
+	}
+
+	for _, line := range []string{"", `//h2 | split "/`, "//h2 | | trim", "//h2 | 1"} {
+		_, err := htmlquerier.ParseSpec(line)
+		if err == nil {
+			t.Errorf("%s: expected error", line)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package htmlquerier
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Trace records how a Querier arrived at its result, see Querier.ExecuteTrace.
+type Trace struct {
+	// Selector is the selector of the querier.
+	Selector string
+	// Matches is the number of nodes matched by the selector, even if only the first match is used.
+	Matches int
+	// Selected are the strings selected, before any filter is applied.
+	Selected []string
+	// Steps are the filters applied, in order.
+	Steps []TraceStep
+	// Result is the result of the query, after whitespace is normalized and empty strings are removed.
+	Result []string
+}
+
+// TraceStep is a single filter applied by a Querier.
+type TraceStep struct {
+	// Filter describes the method that added the filter, such as Split(" / ").
+	Filter string
+	In     []string
+	Out    []string
+}
+
+// Changed reports whether the filter changed its input.
+func (s TraceStep) Changed() bool {
+	return !slices.Equal(s.In, s.Out)
+}
+
+// String formats the trace with one line per step, showing the strings after every filter.
+// Filters that did not change anything are marked as such instead.
+func (t Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d matches)\n", t.Selector, t.Matches)
+	if t.Matches == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\tselected  %q\n", t.Selected)
+	for _, step := range t.Steps {
+		if step.Changed() {
+			fmt.Fprintf(&b, "\t%s  %q\n", step.Filter, step.Out)
+		} else {
+			fmt.Fprintf(&b, "\t%s  unchanged\n", step.Filter)
+		}
+	}
+	fmt.Fprintf(&b, "\tresult  %q\n", t.Result)
+	return b.String()
+}
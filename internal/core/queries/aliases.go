@@ -2,11 +2,27 @@ package queries
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
 )
 
+var (
+	ErrInvalidAlias              = errors.New("invalid alias")
+	ErrAliasExists               = errors.New("artist already has this alias")
+	ErrAliasNotFound             = errors.New("artist has no such alias")
+	ErrAliasSuggestionPending    = errors.New("this alias has already been suggested")
+	ErrAliasSuggestionNotPending = errors.New("no pending alias suggestion with this id")
+)
+
+// maxAliasLength is the maximum length of aliases suggested by users, in runes.
+const maxAliasLength = 100
+
+// PushAliases adds machine generated aliases to an artist.
 func PushAliases(ctx context.Context, tx pgx.Tx, artist string, aliases []string) (n int, err error) {
 	for _, alias := range aliases {
 		var cmd pgconn.CommandTag
@@ -18,3 +34,240 @@ func PushAliases(ctx context.Context, tx pgx.Tx, artist string, aliases []string
 	}
 	return
 }
+
+// putAlias adds an alias added or approved by a person to an artist, taking over the alias if it was machine generated,
+// and indexes the lives of the artist again so that they are found by it right away.
+func putAlias(ctx context.Context, tx pgx.Tx, artist string, alias string, source datastructures.AliasSource, userID *int) (err error) {
+	_, err = tx.Exec(ctx, `INSERT INTO artistaliases (alias, artists_name, source, users_id) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (alias, artists_name) DO UPDATE SET (source, users_id, created_at) = (EXCLUDED.source, EXCLUDED.users_id, NOW())`, alias, artist, source, userID)
+	if err != nil {
+		return
+	}
+	// saved searches may match the artist by the alias now
+	err = queueArtistSimilarity(ctx, tx, []string{artist})
+	if err != nil {
+		return
+	}
+	liveIDs, err := getArtistLiveIDs(ctx, tx, artist)
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	return
+}
+
+// normalizeAlias collapses the spaces of an alias suggested by a user, and checks that it is not empty or too long.
+func normalizeAlias(alias string) (string, error) {
+	alias = strings.Join(strings.Fields(alias), " ")
+	if alias == "" || len([]rune(alias)) > maxAliasLength {
+		return "", ErrInvalidAlias
+	}
+	return alias, nil
+}
+
+// GetArtistAliases returns the aliases of an artist, where those added by people come first.
+func GetArtistAliases(ctx context.Context, artist string) (aliases []datastructures.ArtistAlias, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+	return getArtistAliases(ctx, tx, artist)
+}
+
+func getArtistAliases(ctx context.Context, tx pgx.Tx, artist string) (aliases []datastructures.ArtistAlias, err error) {
+	rows, err := tx.Query(ctx, `SELECT a.alias, a.source, COALESCE(u.username, ''), a.created_at FROM artistaliases a
+		LEFT JOIN users u ON u.id = a.users_id
+		WHERE a.artists_name = $1
+		ORDER BY a.source = $2, a.alias`, artist, datastructures.AliasSourceMachine)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	aliases = make([]datastructures.ArtistAlias, 0)
+	for rows.Next() {
+		var alias datastructures.ArtistAlias
+		err = rows.Scan(&alias.Alias, &alias.Source, &alias.Username, &alias.CreatedAt)
+		if err != nil {
+			return
+		}
+		aliases = append(aliases, alias)
+	}
+	err = rows.Err()
+	return
+}
+
+// SuggestAlias suggests an alias for an artist on behalf of a user, which is queued for moderation unless the user is
+// an admin, in which case it is added right away and added is set.
+func SuggestAlias(ctx context.Context, user datastructures.AuthUser, artist string, alias string) (added bool, err error) {
+	alias, err = normalizeAlias(alias)
+	if err != nil {
+		return
+	}
+
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = requireArtist(ctx, tx, artist)
+	if err != nil {
+		return
+	}
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM artistaliases WHERE artists_name = $1 AND lower(alias) = lower($2))", artist, alias).Scan(&exists)
+	if err != nil {
+		return
+	}
+	if exists {
+		err = ErrAliasExists
+		return
+	}
+
+	admin, err := isAdmin(ctx, tx, user.ID)
+	if err != nil {
+		return
+	}
+	if admin {
+		err = putAlias(ctx, tx, artist, alias, datastructures.AliasSourceAdmin, &user.ID)
+		if err != nil {
+			return
+		}
+		added = true
+	} else {
+		var cmd pgconn.CommandTag
+		cmd, err = tx.Exec(ctx, "INSERT INTO alias_suggestions (artists_name, alias, users_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", artist, alias, user.ID)
+		if err != nil {
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			err = ErrAliasSuggestionPending
+			return
+		}
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// DeleteAlias removes an alias from an artist. The name of the artist itself cannot be removed.
+func DeleteAlias(ctx context.Context, artist string, alias string) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	cmd, err := tx.Exec(ctx, "DELETE FROM artistaliases WHERE artists_name = $1 AND alias = $2 AND alias <> artists_name", artist, alias)
+	if err != nil {
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		err = ErrAliasNotFound
+		return
+	}
+	err = queueArtistSimilarity(ctx, tx, []string{artist})
+	if err != nil {
+		return
+	}
+	liveIDs, err := getArtistLiveIDs(ctx, tx, artist)
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// GetAliasSuggestions returns all alias suggestions with the given status, oldest first.
+func GetAliasSuggestions(ctx context.Context, status datastructures.AliasSuggestionStatus) (suggestions []datastructures.AliasSuggestion, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, `SELECT s.id, s.artists_name, s.alias, COALESCE(u.username, ''), s.status, s.created_at, s.resolved_at FROM alias_suggestions s
+		LEFT JOIN users u ON u.id = s.users_id
+		WHERE s.status = $1
+		ORDER BY s.created_at`, status)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	suggestions = make([]datastructures.AliasSuggestion, 0)
+	for rows.Next() {
+		var s datastructures.AliasSuggestion
+		err = rows.Scan(&s.ID, &s.Artist, &s.Alias, &s.Username, &s.Status, &s.CreatedAt, &s.ResolvedAt)
+		if err != nil {
+			return
+		}
+		suggestions = append(suggestions, s)
+	}
+	err = rows.Err()
+	return
+}
+
+// ApproveAliasSuggestion adds a suggested alias to its artist, crediting the user who suggested it. moderatorID is the
+// admin approving it, or 0 if approved from the command line.
+func ApproveAliasSuggestion(ctx context.Context, id int, moderatorID int) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	var artist, alias string
+	var userID *int
+	err = tx.QueryRow(ctx, "UPDATE alias_suggestions SET status=$1, resolved_at=NOW(), resolved_by=$2 WHERE id=$3 AND status=$4 RETURNING artists_name, alias, users_id",
+		datastructures.AliasSuggestionStatusApproved, nullableID(moderatorID), id, datastructures.AliasSuggestionStatusPending).Scan(&artist, &alias, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrAliasSuggestionNotPending
+	}
+	if err != nil {
+		return
+	}
+	err = putAlias(ctx, tx, artist, alias, datastructures.AliasSourceUser, userID)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// RejectAliasSuggestion discards a suggested alias. moderatorID is the admin rejecting it, or 0 if rejected from the
+// command line.
+func RejectAliasSuggestion(ctx context.Context, id int, moderatorID int) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	cmd, err := tx.Exec(ctx, "UPDATE alias_suggestions SET status=$1, resolved_at=NOW(), resolved_by=$2 WHERE id=$3 AND status=$4",
+		datastructures.AliasSuggestionStatusRejected, nullableID(moderatorID), id, datastructures.AliasSuggestionStatusPending)
+	if err != nil {
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		err = ErrAliasSuggestionNotPending
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// nullableID returns nil for the zero ID, so that it is stored as NULL.
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
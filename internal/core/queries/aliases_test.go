@@ -0,0 +1,19 @@
+package queries
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAlias(t *testing.T) {
+	alias, err := normalizeAlias("  ヨルシカ\t(n-buna)  ")
+	if err != nil || alias != "ヨルシカ (n-buna)" {
+		t.Errorf("expected spaces to be collapsed, got %q (%v)", alias, err)
+	}
+	for _, invalid := range []string{"", "   ", strings.Repeat("あ", maxAliasLength+1)} {
+		if _, err := normalizeAlias(invalid); !errors.Is(err, ErrInvalidAlias) {
+			t.Errorf("expected %q to be invalid, got %v", invalid, err)
+		}
+	}
+}
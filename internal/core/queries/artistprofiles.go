@@ -0,0 +1,212 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+var ErrInvalidArtistProfile = errors.New("invalid artist profile")
+
+const (
+	// maxArtistDescriptionLength is the maximum length of the description of an artist, in runes.
+	maxArtistDescriptionLength = 2000
+	// maxArtistSocials is the maximum number of social links of an artist.
+	maxArtistSocials = 10
+	// artistTopVenues is the number of venues and areas shown as those an artist plays most.
+	artistTopVenues = 5
+	// artistTopCoBilled is the number of artists shown as those most often co-billed with an artist.
+	artistTopCoBilled = 10
+)
+
+// GetArtist returns an artist along with where they play most and who they play with most. userID is the user viewing
+// the artist, or 0 if not logged in.
+func GetArtist(ctx context.Context, name string, userID int) (artist datastructures.Artist, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	var artistURL, description *string
+	err = tx.QueryRow(ctx, "SELECT name, url, description, COALESCE(socials, '{}') FROM artists WHERE name = $1", name).Scan(&artist.Name, &artistURL, &description, &artist.Socials)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = fmt.Errorf("%w: %q", ErrArtistNotFound, name)
+	}
+	if err != nil {
+		return
+	}
+	if artistURL != nil {
+		artist.URL = *artistURL
+	}
+	if description != nil {
+		artist.Description = *description
+	}
+
+	artist.Aliases, err = getArtistAliases(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	artist.Venues, err = getArtistVenues(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	artist.Areas, err = getArtistAreas(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	artist.CoBilled, err = getCoBilledArtists(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	if userID != 0 {
+		err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM saved_searches WHERE users_id = $1 AND lower(keyword) = lower($2))", userID, name).Scan(&artist.HasSavedSearch)
+	}
+	return
+}
+
+func getArtistVenues(ctx context.Context, tx pgx.Tx, name string) (venues []datastructures.ArtistVenue, err error) {
+	rows, err := tx.Query(ctx, `SELECT livehouse.id, area.id, area.prefecture, area.name, COUNT(*) AS lives FROM liveartists la
+		INNER JOIN lives live ON live.id = la.lives_id
+		INNER JOIN livehouses livehouse ON livehouse.id = live.livehouses_id
+		INNER JOIN areas area ON area.id = livehouse.areas_id
+		WHERE la.artists_name = $1
+		GROUP BY livehouse.id, area.id, area.prefecture, area.name
+		ORDER BY lives DESC, livehouse.id
+		LIMIT $2`, name, artistTopVenues)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	venues = make([]datastructures.ArtistVenue, 0)
+	for rows.Next() {
+		var venue datastructures.ArtistVenue
+		err = rows.Scan(&venue.ID, &venue.Area.ID, &venue.Area.Prefecture, &venue.Area.Area, &venue.Lives)
+		if err != nil {
+			return
+		}
+		venues = append(venues, venue)
+	}
+	err = rows.Err()
+	return
+}
+
+func getArtistAreas(ctx context.Context, tx pgx.Tx, name string) (areas []datastructures.ArtistArea, err error) {
+	rows, err := tx.Query(ctx, `SELECT area.id, area.prefecture, area.name, COUNT(*) AS lives FROM liveartists la
+		INNER JOIN lives live ON live.id = la.lives_id
+		INNER JOIN livehouses livehouse ON livehouse.id = live.livehouses_id
+		INNER JOIN areas area ON area.id = livehouse.areas_id
+		WHERE la.artists_name = $1
+		GROUP BY area.id, area.prefecture, area.name
+		ORDER BY lives DESC, area.id
+		LIMIT $2`, name, artistTopVenues)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	areas = make([]datastructures.ArtistArea, 0)
+	for rows.Next() {
+		var area datastructures.ArtistArea
+		err = rows.Scan(&area.Area.ID, &area.Area.Prefecture, &area.Area.Area, &area.Lives)
+		if err != nil {
+			return
+		}
+		areas = append(areas, area)
+	}
+	err = rows.Err()
+	return
+}
+
+// getCoBilledArtists returns the artists credited by the most lives that also credit the artist name.
+func getCoBilledArtists(ctx context.Context, tx pgx.Tx, name string) (artists []datastructures.CoBilledArtist, err error) {
+	rows, err := tx.Query(ctx, `SELECT other.artists_name, COUNT(*) AS lives FROM liveartists la
+		INNER JOIN liveartists other ON other.lives_id = la.lives_id AND other.artists_name <> la.artists_name
+		WHERE la.artists_name = $1
+		GROUP BY other.artists_name
+		ORDER BY lives DESC, other.artists_name
+		LIMIT $2`, name, artistTopCoBilled)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	artists = make([]datastructures.CoBilledArtist, 0)
+	for rows.Next() {
+		var artist datastructures.CoBilledArtist
+		err = rows.Scan(&artist.Name, &artist.Lives)
+		if err != nil {
+			return
+		}
+		artists = append(artists, artist)
+	}
+	err = rows.Err()
+	return
+}
+
+// UpdateArtistProfile replaces the link, description and social links of an artist.
+func UpdateArtistProfile(ctx context.Context, name string, profile datastructures.ArtistProfile) (err error) {
+	profile, err = normalizeArtistProfile(profile)
+	if err != nil {
+		return
+	}
+
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	cmd, err := tx.Exec(ctx, "UPDATE artists SET (url, description, socials) = (NULLIF($2, ''), NULLIF($3, ''), $4) WHERE name = $1",
+		name, profile.URL, profile.Description, profile.Socials)
+	if err != nil {
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("%w: %q", ErrArtistNotFound, name)
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// normalizeArtistProfile trims the fields of a profile and drops empty and repeated social links, and checks that every
+// link is a http(s) URL and that the profile is not too long.
+func normalizeArtistProfile(profile datastructures.ArtistProfile) (datastructures.ArtistProfile, error) {
+	profile.URL = strings.TrimSpace(profile.URL)
+	if profile.URL != "" && !isWebURL(profile.URL) {
+		return profile, fmt.Errorf("%w: %q is not a link", ErrInvalidArtistProfile, profile.URL)
+	}
+	profile.Description = strings.TrimSpace(profile.Description)
+	if len([]rune(profile.Description)) > maxArtistDescriptionLength {
+		return profile, fmt.Errorf("%w: description is longer than %d characters", ErrInvalidArtistProfile, maxArtistDescriptionLength)
+	}
+
+	socials := make([]string, 0, len(profile.Socials))
+	for _, social := range profile.Socials {
+		social = strings.TrimSpace(social)
+		if social == "" || slices.Contains(socials, social) {
+			continue
+		}
+		if !isWebURL(social) {
+			return profile, fmt.Errorf("%w: %q is not a link", ErrInvalidArtistProfile, social)
+		}
+		socials = append(socials, social)
+	}
+	if len(socials) > maxArtistSocials {
+		return profile, fmt.Errorf("%w: more than %d social links", ErrInvalidArtistProfile, maxArtistSocials)
+	}
+	profile.Socials = socials
+	return profile, nil
+}
+
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,56 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestNormalizeArtistProfile(t *testing.T) {
+	profile, err := normalizeArtistProfile(datastructures.ArtistProfile{
+		URL:         " https://yorushika.com ",
+		Description: "\n2017年結成。\n",
+		Socials:     []string{"https://x.com/yorushika_info", "", " https://x.com/yorushika_info", "https://www.youtube.com/@yorushika"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.URL != "https://yorushika.com" || profile.Description != "2017年結成。" {
+		t.Errorf("expected fields to be trimmed, got %+v", profile)
+	}
+	if expected := []string{"https://x.com/yorushika_info", "https://www.youtube.com/@yorushika"}; !slices.Equal(profile.Socials, expected) {
+		t.Errorf("expected socials %v, got %v", expected, profile.Socials)
+	}
+
+	tooManySocials := make([]string, maxArtistSocials+1)
+	for i := range tooManySocials {
+		tooManySocials[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	for _, invalid := range []datastructures.ArtistProfile{
+		{URL: "javascript:alert(1)"},
+		{URL: "yorushika.com"},
+		{Socials: []string{"@yorushika_info"}},
+		{Description: strings.Repeat("あ", maxArtistDescriptionLength+1)},
+		{Socials: tooManySocials},
+	} {
+		if _, err := normalizeArtistProfile(invalid); !errors.Is(err, ErrInvalidArtistProfile) {
+			t.Errorf("expected %+v to be invalid, got %v", invalid, err)
+		}
+	}
+}
+
+func TestSavedSearchKeyword(t *testing.T) {
+	for search, expected := range map[string]string{
+		`"ヨルシカ"`: "ヨルシカ",
+		"ヨルシカ":   "ヨルシカ%",
+		`"`:      `"%`,
+	} {
+		if res := savedSearchKeyword(search); res != expected {
+			t.Errorf("expected %q to be %q, got %q", search, expected, res)
+		}
+	}
+}
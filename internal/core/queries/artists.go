@@ -2,51 +2,637 @@ package queries
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gojp/kana"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5"
 	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/mecab"
 )
 
-func PostArtists(ctx context.Context, artists []string) (n int, err error) {
+var ErrArtistNotFound = errors.New("artist not found")
+
+// PostArtists resolves the given credits to canonical artists, creating the artists that are not known yet along with
+// their aliases, and returns the name of the artist of every credit.
+func PostArtists(ctx context.Context, credits []string) (artists map[string]string, n int, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	artists = make(map[string]string, len(credits))
+	for _, credit := range credits {
+		if _, ok := artists[credit]; ok {
+			continue
+		}
+		var name string
+		var created bool
+		name, created, err = resolveArtistCredit(ctx, tx, credit)
+		if err != nil {
+			return
+		}
+		artists[credit] = name
+		if created {
+			n++
+		}
+	}
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// resolveArtistCredit returns the artist credited by credit, creating the artist if there is none.
+func resolveArtistCredit(ctx context.Context, tx pgx.Tx, credit string) (name string, created bool, err error) {
+	name, credited, err := lookupArtistCredit(ctx, tx, credit)
+	if err != nil || credited {
+		return
+	}
+	if name == "" {
+		name = util.ArtistDisplayName(credit)
+		created, err = createArtist(ctx, tx, name)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO artistcredits (credit, artist_key, artists_name) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", credit, util.ArtistKey(credit), name)
+	if err != nil {
+		return
+	}
+	// the artist can be searched by how it is credited as well
+	_, err = PushAliases(ctx, tx, name, []string{credit})
+	return
+}
+
+// lookupArtistCredit returns the artist credit was credited to before, or else the artist of a credit with the same key,
+// and an empty name if there is none.
+func lookupArtistCredit(ctx context.Context, tx pgx.Tx, credit string) (name string, credited bool, err error) {
+	err = tx.QueryRow(ctx, "SELECT artists_name FROM artistcredits WHERE credit = $1", credit).Scan(&name)
+	if err == nil {
+		credited = true
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	err = tx.QueryRow(ctx, "SELECT artists_name FROM artistcredits WHERE artist_key = $1 ORDER BY credit LIMIT 1", util.ArtistKey(credit)).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	return
+}
+
+// previewArtists returns the artist every credit would be resolved to by PostArtists, without creating any artists.
+func previewArtists(ctx context.Context, credits []string) (artists map[string]string, err error) {
 	tx, err := counters.FetchTransaction(ctx)
 	if err != nil {
 		return
 	}
 	defer counters.RollbackTransaction(ctx, tx)
 
-	for _, artist := range artists {
-		var cmd pgconn.CommandTag
-		cmd, err = tx.Exec(ctx, "INSERT INTO artists (name) VALUES ($1) ON CONFLICT DO NOTHING", artist)
+	artists = make(map[string]string, len(credits))
+	for _, credit := range credits {
+		if _, ok := artists[credit]; ok {
+			continue
+		}
+		var name string
+		name, _, err = lookupArtistCredit(ctx, tx, credit)
 		if err != nil {
 			return
 		}
-		var katakana string
-		katakana, err = mecab.Mecab(artist)
+		if name == "" {
+			name = util.ArtistDisplayName(credit)
+		}
+		artists[credit] = name
+	}
+	return
+}
+
+// createArtist creates an artist along with the readings of its name as aliases, unless it already exists.
+func createArtist(ctx context.Context, tx pgx.Tx, name string) (created bool, err error) {
+	cmd, err := tx.Exec(ctx, "INSERT INTO artists (name) VALUES ($1) ON CONFLICT DO NOTHING", name)
+	if err != nil || cmd.RowsAffected() == 0 {
+		return
+	}
+	created = true
+	aliases, err := artistAliases(name)
+	if err != nil {
+		return
+	}
+	_, err = PushAliases(ctx, tx, name, aliases)
+	return
+}
+
+// artistAliases returns the name of an artist along with its reading in katakana, hiragana and romaji.
+func artistAliases(name string) (aliases []string, err error) {
+	katakana, err := mecab.Mecab(name)
+	if err != nil {
+		return
+	}
+	romaji := kana.KanaToRomaji(katakana)
+	romajiSingleN := strings.ReplaceAll(romaji, "nn", "n")
+	hiragana := kana.RomajiToHiragana(romaji)
+	for _, alias := range []string{name, katakana, romaji, romajiSingleN, hiragana} {
+		if !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return
+}
+
+// creditedArtists returns the artists of the given credits in order and without duplicates, given the artist of every
+// credit, along with the first credit of every artist.
+func creditedArtists(credits []string, artists map[string]string) (names []string, firstCredits map[string]string) {
+	names = make([]string, 0, len(credits))
+	firstCredits = make(map[string]string, len(credits))
+	for _, credit := range credits {
+		name, ok := artists[credit]
+		if !ok {
+			continue
+		}
+		if _, ok := firstCredits[name]; ok {
+			continue
+		}
+		firstCredits[name] = credit
+		names = append(names, name)
+	}
+	return
+}
+
+// GetArtistCredits returns the credits of an artist.
+func GetArtistCredits(ctx context.Context, name string) (credits []string, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, "SELECT credit FROM artistcredits WHERE artists_name = $1 ORDER BY credit", name)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var credit string
+		err = rows.Scan(&credit)
 		if err != nil {
 			return
 		}
-		romaji := kana.KanaToRomaji(katakana)
-		romajiSingleN := strings.ReplaceAll(romaji, "nn", "n")
-		hiragana := kana.RomajiToHiragana(romaji)
-		aliases := make(map[string]bool, 0)
-		aliases[artist] = true
-		aliases[katakana] = true
-		aliases[romaji] = true
-		aliases[romajiSingleN] = true
-		aliases[hiragana] = true
-		aliasSlice := make([]string, 0)
-		for alias := range aliases {
-			aliasSlice = append(aliasSlice, alias)
+		credits = append(credits, credit)
+	}
+	err = rows.Err()
+	return
+}
+
+// MergeArtists merges the artist source into target, which takes over its lives, credits and aliases, and the saved
+// searches for it. The name of source is kept as an alias of target.
+func MergeArtists(ctx context.Context, source string, target string) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = mergeArtists(ctx, tx, source, target)
+	if err != nil {
+		return
+	}
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+func mergeArtists(ctx context.Context, tx pgx.Tx, source string, target string) (err error) {
+	if source == target {
+		return fmt.Errorf("cannot merge artist %q into itself", source)
+	}
+	err = requireArtist(ctx, tx, source)
+	if err != nil {
+		return
+	}
+	err = requireArtist(ctx, tx, target)
+	if err != nil {
+		return
+	}
+	liveIDs, err := getArtistLiveIDs(ctx, tx, source)
+	if err != nil {
+		return
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE artists t SET (url, description, socials) = (COALESCE(t.url, s.url), COALESCE(t.description, s.description), COALESCE(t.socials, s.socials))
+		FROM artists s WHERE t.name = $2 AND s.name = $1`, source, target)
+	if err != nil {
+		return
+	}
+	// lives crediting both artists are left to be deleted along with source
+	_, err = tx.Exec(ctx, `UPDATE liveartists la SET artists_name = $2 WHERE artists_name = $1
+		AND NOT EXISTS (SELECT 1 FROM liveartists t WHERE t.lives_id = la.lives_id AND t.artists_name = $2)`, source, target)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "UPDATE artistcredits SET artists_name = $2 WHERE artists_name = $1", source, target)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO artistaliases (alias, artists_name, source, users_id, created_at) SELECT alias, $2, source, users_id, created_at FROM artistaliases
+		WHERE artists_name = $1 ON CONFLICT DO NOTHING`, source, target)
+	if err != nil {
+		return
+	}
+	// suggestions pending for both artists are left to be deleted along with source
+	_, err = tx.Exec(ctx, `UPDATE alias_suggestions s SET artists_name = $2 WHERE artists_name = $1
+		AND NOT (status = $3 AND EXISTS (SELECT 1 FROM alias_suggestions t WHERE t.artists_name = $2 AND t.status = $3 AND lower(t.alias) = lower(s.alias)))`,
+		source, target, datastructures.AliasSuggestionStatusPending)
+	if err != nil {
+		return
+	}
+	_, err = PushAliases(ctx, tx, target, []string{source})
+	if err != nil {
+		return
+	}
+	err = repointSavedSearches(ctx, tx, source, target)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM artists WHERE name = $1", source)
+	if err != nil {
+		return
+	}
+	// the similarities of source are deleted along with it, and those of target now include its lives and fans
+	err = queueArtistSimilarity(ctx, tx, []string{target})
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	return
+}
+
+// RenameArtist renames an artist, keeping the old name as an alias. Artists cannot be renamed to the name of another
+// artist, which should be merged using MergeArtists instead.
+func RenameArtist(ctx context.Context, name string, newName string) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = renameArtist(ctx, tx, name, newName)
+	if err != nil {
+		return
+	}
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+func renameArtist(ctx context.Context, tx pgx.Tx, name string, newName string) (err error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" || newName == name {
+		return fmt.Errorf("invalid name %q", newName)
+	}
+	err = requireArtist(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	exists, err := artistExists(ctx, tx, newName)
+	if err != nil {
+		return
+	}
+	if exists {
+		return fmt.Errorf("artist %q already exists, merge the artists instead", newName)
+	}
+
+	// the lives, credits and aliases of the artist follow along
+	_, err = tx.Exec(ctx, "UPDATE artists SET name = $2 WHERE name = $1", name, newName)
+	if err != nil {
+		return
+	}
+	aliases, err := artistAliases(newName)
+	if err != nil {
+		return
+	}
+	_, err = PushAliases(ctx, tx, newName, aliases)
+	if err != nil {
+		return
+	}
+	err = repointSavedSearches(ctx, tx, name, newName)
+	if err != nil {
+		return
+	}
+	liveIDs, err := getArtistLiveIDs(ctx, tx, newName)
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	return
+}
+
+// SplitArtist moves the given credits of an artist to a new artist named newName, along with the lives credited by
+// them, such as when two artists were taken to be the same because their credits share a key.
+func SplitArtist(ctx context.Context, name string, newName string, credits []string) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("invalid name %q", newName)
+	}
+	if len(credits) == 0 {
+		return fmt.Errorf("no credits to split from %q", name)
+	}
+	err = requireArtist(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	created, err := createArtist(ctx, tx, newName)
+	if err != nil {
+		return
+	}
+	if !created {
+		return fmt.Errorf("artist %q already exists, merge the artists instead", newName)
+	}
+
+	cmd, err := tx.Exec(ctx, "UPDATE artistcredits SET artists_name = $3 WHERE artists_name = $1 AND credit = ANY($2)", name, credits, newName)
+	if err != nil {
+		return
+	}
+	if int(cmd.RowsAffected()) != len(credits) {
+		return fmt.Errorf("not every credit is a credit of %q", name)
+	}
+	rows, err := tx.Query(ctx, "UPDATE liveartists SET artists_name = $3 WHERE artists_name = $1 AND credit = ANY($2) RETURNING lives_id", name, credits, newName)
+	if err != nil {
+		return
+	}
+	var liveIDs []int
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return
 		}
-		_, err = PushAliases(ctx, tx, artist, aliasSlice)
+		liveIDs = append(liveIDs, id)
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM artistaliases WHERE artists_name = $1 AND alias = ANY($2) AND alias <> $1", name, credits)
+	if err != nil {
+		return
+	}
+	_, err = PushAliases(ctx, tx, newName, credits)
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	if err != nil {
+		return
+	}
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// NormalizeArtists recomputes the key of every credit, such as after util.ArtistKey has changed or when credits were
+// saved before artists had keys, and returns the groups of artists whose credits share a key, which are likely the same
+// artist, with the artist crediting the most lives first.
+//
+// If merge is set, the artists of every group are merged into the first one, and every artist is renamed to its
+// display name where no other artist has it. Artists that were split on purpose are merged again as well.
+func NormalizeArtists(ctx context.Context, merge bool) (groups [][]string, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	keys, err := updateArtistKeys(ctx, tx)
+	if err != nil {
+		return
+	}
+	liveCounts, err := getArtistLiveCounts(ctx, tx)
+	if err != nil {
+		return
+	}
+	groups = groupArtists(keys, liveCounts)
+	if merge {
+		err = mergeArtistGroups(ctx, tx, groups)
 		if err != nil {
 			return
 		}
-		n += int(cmd.RowsAffected())
 	}
 	err = counters.CommitTransaction(ctx, tx)
 	return
 }
+
+// mergeArtistGroups merges the artists of every group into the first one, and renames every artist to its display name
+// where no other artist has it.
+func mergeArtistGroups(ctx context.Context, tx pgx.Tx, groups [][]string) (err error) {
+	for _, group := range groups {
+		for _, source := range group[1:] {
+			err = mergeArtists(ctx, tx, source, group[0])
+			if err != nil {
+				return
+			}
+		}
+	}
+	names, err := getArtistNames(ctx, tx)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		displayName := util.ArtistDisplayName(name)
+		if displayName == name || slices.Contains(names, displayName) {
+			continue
+		}
+		err = renameArtist(ctx, tx, name, displayName)
+		if err != nil {
+			return
+		}
+		names = append(names, displayName)
+	}
+	return
+}
+
+// updateArtistKeys recomputes the key of every credit, and returns the keys of the credits of every artist.
+func updateArtistKeys(ctx context.Context, tx pgx.Tx) (keys map[string][]string, err error) {
+	rows, err := tx.Query(ctx, "SELECT credit, artist_key, artists_name FROM artistcredits")
+	if err != nil {
+		return
+	}
+	keys = make(map[string][]string)
+	changed := make(map[string]string)
+	for rows.Next() {
+		var credit, key, name string
+		err = rows.Scan(&credit, &key, &name)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		newKey := util.ArtistKey(credit)
+		if newKey != key {
+			changed[credit] = newKey
+		}
+		if !slices.Contains(keys[name], newKey) {
+			keys[name] = append(keys[name], newKey)
+		}
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+
+	for credit, key := range changed {
+		_, err = tx.Exec(ctx, "UPDATE artistcredits SET artist_key = $2 WHERE credit = $1", credit, key)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// groupArtists groups artists whose credits share a key, directly or through other artists of the group, given the
+// keys of the credits of every artist. The artists of every group are sorted by the number of lives crediting them.
+func groupArtists(keys map[string][]string, liveCounts map[string]int) (groups [][]string) {
+	names := make([]string, 0, len(keys))
+	parent := make(map[string]string, len(keys))
+	for name := range keys {
+		names = append(names, name)
+		parent[name] = name
+	}
+	slices.Sort(names)
+	root := func(name string) string {
+		for parent[name] != name {
+			name = parent[name]
+		}
+		return name
+	}
+
+	keyArtists := make(map[string]string)
+	for _, name := range names {
+		for _, key := range keys[name] {
+			other, ok := keyArtists[key]
+			if !ok {
+				keyArtists[key] = name
+				continue
+			}
+			if a, b := root(name), root(other); a != b {
+				parent[a] = b
+			}
+		}
+	}
+
+	members := make(map[string][]string)
+	roots := make([]string, 0)
+	for _, name := range names {
+		r := root(name)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], name)
+	}
+	for _, r := range roots {
+		group := members[r]
+		if len(group) < 2 {
+			continue
+		}
+		slices.SortStableFunc(group, func(a, b string) int {
+			return liveCounts[b] - liveCounts[a]
+		})
+		groups = append(groups, group)
+	}
+	return
+}
+
+func getArtistLiveCounts(ctx context.Context, tx pgx.Tx) (liveCounts map[string]int, err error) {
+	rows, err := tx.Query(ctx, "SELECT artists_name, COUNT(*) FROM liveartists GROUP BY artists_name")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	liveCounts = make(map[string]int)
+	for rows.Next() {
+		var name string
+		var n int
+		err = rows.Scan(&name, &n)
+		if err != nil {
+			return
+		}
+		liveCounts[name] = n
+	}
+	err = rows.Err()
+	return
+}
+
+func getArtistNames(ctx context.Context, tx pgx.Tx) (names []string, err error) {
+	rows, err := tx.Query(ctx, "SELECT name FROM artists ORDER BY name")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return
+		}
+		names = append(names, name)
+	}
+	err = rows.Err()
+	return
+}
+
+func getArtistLiveIDs(ctx context.Context, tx pgx.Tx, name string) (liveIDs []int, err error) {
+	rows, err := tx.Query(ctx, "SELECT lives_id FROM liveartists WHERE artists_name = $1", name)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return
+		}
+		liveIDs = append(liveIDs, id)
+	}
+	err = rows.Err()
+	return
+}
+
+func artistExists(ctx context.Context, tx pgx.Tx, name string) (exists bool, err error) {
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM artists WHERE name = $1)", name).Scan(&exists)
+	return
+}
+
+func requireArtist(ctx context.Context, tx pgx.Tx, name string) (err error) {
+	exists, err := artistExists(ctx, tx, name)
+	if err == nil && !exists {
+		err = fmt.Errorf("%w: %q", ErrArtistNotFound, name)
+	}
+	return
+}
+
+// repointSavedSearches points the saved searches for the artist name to newName, dropping those of users who already
+// saved a search for newName. Keywords are matched regardless of case, so nothing changes if only the case differs.
+func repointSavedSearches(ctx context.Context, tx pgx.Tx, name string, newName string) (err error) {
+	if strings.EqualFold(name, newName) {
+		return
+	}
+	_, err = tx.Exec(ctx, `UPDATE saved_searches ss SET keyword = $2 WHERE lower(keyword) = lower($1)
+		AND NOT EXISTS (SELECT 1 FROM saved_searches o WHERE o.users_id = ss.users_id AND lower(o.keyword) = lower($2))`, name, newName)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM saved_searches WHERE lower(keyword) = lower($1)", name)
+	return
+}
@@ -0,0 +1,42 @@
+package queries
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestCreditedArtists(t *testing.T) {
+	artists := map[string]string{
+		"yonige":        "yonige",
+		"YONIGE (ワンマン)": "yonige",
+		"ズーカラデル":        "ズーカラデル",
+	}
+	names, credits := creditedArtists([]string{"YONIGE (ワンマン)", "ズーカラデル", "yonige", "unknown"}, artists)
+	if !slices.Equal(names, []string{"yonige", "ズーカラデル"}) {
+		t.Errorf("unexpected artists %v", names)
+	}
+	expected := map[string]string{"yonige": "YONIGE (ワンマン)", "ズーカラデル": "ズーカラデル"}
+	if !maps.Equal(credits, expected) {
+		t.Errorf("expected first credits %v, got %v", expected, credits)
+	}
+}
+
+func TestGroupArtists(t *testing.T) {
+	keys := map[string][]string{
+		"yonige":         {"yonige"},
+		"YONIGE":         {"yonige"},
+		"yonige (ワンマン)":  {"yonige", "yonige (one man)"},
+		"yonige one man": {"yonige (one man)"},
+		"ズーカラデル":         {"ズーカラデル"},
+	}
+	liveCounts := map[string]int{"YONIGE": 3, "yonige": 10, "yonige one man": 1}
+	groups := groupArtists(keys, liveCounts)
+	if len(groups) != 1 {
+		t.Fatalf("expected a single group, got %v", groups)
+	}
+	expected := []string{"yonige", "YONIGE", "yonige one man", "yonige (ワンマン)"}
+	if !slices.Equal(groups[0], expected) {
+		t.Errorf("expected %v, got %v", expected, groups[0])
+	}
+}
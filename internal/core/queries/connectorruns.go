@@ -0,0 +1,148 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+// ConnectorHealthWindow is the number of runs considered when determining the health of a connector.
+const ConnectorHealthWindow = 5
+
+const connectorRunColumns = "id, connector_id, started_at, finished_at, duration_ms, pages_fetched, lives_parsed, added, modified, deleted, cache_hits, cache_misses, error_message, error_kind"
+
+func PostConnectorRun(ctx context.Context, run datastructures.ConnectorRun) (id int, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO connector_runs (connector_id, started_at, finished_at, duration_ms, pages_fetched, lives_parsed, added, modified, deleted, cache_hits, cache_misses, error_message, error_kind) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id",
+		run.ConnectorID, run.StartedAt, run.FinishedAt, run.Duration.Milliseconds(), run.PagesFetched, run.LivesParsed, run.Added, run.Modified, run.Deleted, run.CacheHits, run.CacheMisses, run.Error, run.ErrorKind,
+	).Scan(&id)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+func scanConnectorRun(rows pgx.Rows) (run datastructures.ConnectorRun, err error) {
+	var durationMs int64
+	err = rows.Scan(&run.ID, &run.ConnectorID, &run.StartedAt, &run.FinishedAt, &durationMs, &run.PagesFetched, &run.LivesParsed, &run.Added, &run.Modified, &run.Deleted, &run.CacheHits, &run.CacheMisses, &run.Error, &run.ErrorKind)
+	run.Duration = time.Duration(durationMs) * time.Millisecond
+	return
+}
+
+// GetConnectorRuns returns the latest runs of a connector, newest first.
+func GetConnectorRuns(ctx context.Context, connectorID string, limit int) (runs []datastructures.ConnectorRun, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, "SELECT "+connectorRunColumns+" FROM connector_runs WHERE connector_id=$1 ORDER BY started_at DESC LIMIT $2", connectorID, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var run datastructures.ConnectorRun
+		run, err = scanConnectorRun(rows)
+		if err != nil {
+			return
+		}
+		runs = append(runs, run)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetRecentConnectorRuns returns the latest runs of every connector that has been run, keyed by connector ID, newest first.
+func GetRecentConnectorRuns(ctx context.Context, limit int) (runs map[string][]datastructures.ConnectorRun, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT `+connectorRunColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY connector_id ORDER BY started_at DESC) AS run_number FROM connector_runs
+		) AS numbered_runs WHERE run_number <= $1 ORDER BY connector_id, started_at DESC`,
+		limit,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	runs = make(map[string][]datastructures.ConnectorRun)
+	for rows.Next() {
+		var run datastructures.ConnectorRun
+		run, err = scanConnectorRun(rows)
+		if err != nil {
+			return
+		}
+		runs[run.ConnectorID] = append(runs[run.ConnectorID], run)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetConnectorHealth returns the health of every given connector.
+// venues maps the ID of every connector to its venue ID.
+func GetConnectorHealth(ctx context.Context, venues map[string]string) (health []datastructures.ConnectorHealth, err error) {
+	runs, err := GetRecentConnectorRuns(ctx, ConnectorHealthWindow)
+	if err != nil {
+		return
+	}
+	for connectorID, venueID := range venues {
+		h := datastructures.ConnectorHealth{
+			ConnectorID: connectorID,
+			VenueID:     venueID,
+			Status:      EvaluateConnectorHealth(runs[connectorID]),
+			RecentRuns:  runs[connectorID],
+		}
+		if len(h.RecentRuns) > 0 {
+			h.LastRun = &h.RecentRuns[0]
+		}
+		health = append(health, h)
+	}
+	return
+}
+
+// EvaluateConnectorHealth determines the status of a connector from its most recent runs, ordered newest first.
+//
+// A connector is broken if its latest run failed,
+// and degraded if a recent run failed or the latest run parsed less than half the usual number of lives.
+func EvaluateConnectorHealth(runs []datastructures.ConnectorRun) datastructures.ConnectorStatus {
+	if len(runs) == 0 {
+		return datastructures.ConnectorStatusUnknown
+	}
+	if runs[0].Failed() {
+		return datastructures.ConnectorStatusBroken
+	}
+
+	successful := 0
+	totalLives := 0
+	for _, run := range runs[1:] {
+		if run.Failed() {
+			return datastructures.ConnectorStatusDegraded
+		}
+		successful++
+		totalLives += run.LivesParsed
+	}
+	if successful > 0 && runs[0].LivesParsed*2*successful < totalLives {
+		return datastructures.ConnectorStatusDegraded
+	}
+	return datastructures.ConnectorStatusHealthy
+}
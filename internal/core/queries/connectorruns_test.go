@@ -0,0 +1,33 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestEvaluateConnectorHealth(t *testing.T) {
+	ok := func(lives int) datastructures.ConnectorRun {
+		return datastructures.ConnectorRun{LivesParsed: lives}
+	}
+	failed := datastructures.ConnectorRun{Error: "timeout"}
+
+	tests := []struct {
+		name     string
+		runs     []datastructures.ConnectorRun
+		expected datastructures.ConnectorStatus
+	}{
+		{"no runs", nil, datastructures.ConnectorStatusUnknown},
+		{"single successful run", []datastructures.ConnectorRun{ok(10)}, datastructures.ConnectorStatusHealthy},
+		{"stable runs", []datastructures.ConnectorRun{ok(58), ok(60), ok(61)}, datastructures.ConnectorStatusHealthy},
+		{"latest run failed", []datastructures.ConnectorRun{failed, ok(60)}, datastructures.ConnectorStatusBroken},
+		{"latest run empty", []datastructures.ConnectorRun{ok(0), ok(60)}, datastructures.ConnectorStatusBroken},
+		{"earlier run failed", []datastructures.ConnectorRun{ok(60), failed, ok(60)}, datastructures.ConnectorStatusDegraded},
+		{"lives dropped", []datastructures.ConnectorRun{ok(2), ok(60), ok(62)}, datastructures.ConnectorStatusDegraded},
+	}
+	for _, test := range tests {
+		if status := EvaluateConnectorHealth(test.runs); status != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, status)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package queries
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+// DiffLives returns the changes PostLives would make for the given lives, without touching the database or notifying anyone.
+func DiffLives(ctx context.Context, lives []datastructures.Live, r *http.Request) (changeset datastructures.Changeset, err error) {
+	venues := make([]datastructures.LiveHouse, 0)
+	for _, live := range lives {
+		venues = append(venues, live.Venue)
+	}
+
+	oldLives, err := GetLives(ctx, LiveQuery{
+		IncludeOldLives: true,
+		LiveHouses:      util.GetUniqueVenueIDs(venues),
+	}, datastructures.AuthUser{}, r)
+	if err != nil {
+		return
+	}
+
+	// stored lives are credited to canonical artists
+	credits := make([]string, 0)
+	for _, live := range lives {
+		credits = append(credits, live.Artists...)
+	}
+	artists, err := previewArtists(ctx, credits)
+	if err != nil {
+		return
+	}
+	lives = slices.Clone(lives)
+	for i := range lives {
+		lives[i].Artists, _ = creditedArtists(lives[i].Artists, artists)
+	}
+
+	changeset, err = buildChangeset(lives, oldLives.Lives)
+	if err != nil {
+		return
+	}
+	changeset.Anomalies = DefaultAnomalyGuard.Check(lives, oldLives.Lives, time.Now())
+	return
+}
+
+func buildChangeset(lives []datastructures.Live, oldLives []datastructures.Live) (changeset datastructures.Changeset, err error) {
+	matches, unmatched := matchLives(lives, oldLives)
+	for _, match := range matches {
+		if !match.found {
+			changeset.Added = append(changeset.Added, match.live)
+			continue
+		}
+		if !shouldUpdateLive(match.live, match.oldLive) {
+			changeset.Unchanged++
+			continue
+		}
+
+		var fields []datastructures.NotificationField
+		fields, err = getNotificationFields(match.live, match.oldLive)
+		if err != nil {
+			return
+		}
+		change := datastructures.LiveChange{
+			Old: match.oldLive,
+			New: match.live,
+		}
+		for _, field := range fields {
+			if field.OldValue != field.NewValue {
+				change.Fields = append(change.Fields, field)
+			}
+		}
+		changeset.Modified = append(changeset.Modified, change)
+	}
+	changeset.Deleted = unmatched
+	return
+}
@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestBuildChangeset(t *testing.T) {
+	day := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	unchanged := datastructures.Live{ID: 1, Title: "unchanged", StartTime: day, Artists: []string{"a"}}
+	modified := datastructures.Live{ID: 2, Title: "modified", StartTime: day.AddDate(0, 0, 1), Price: "2000", Artists: []string{"b"}}
+	deleted := datastructures.Live{ID: 3, Title: "deleted", StartTime: day.AddDate(0, 0, 2)}
+	oldLives := []datastructures.Live{unchanged, modified, deleted}
+
+	newModified := modified
+	newModified.ID = 0
+	newModified.Price = "2500"
+	newUnchanged := unchanged
+	newUnchanged.ID = 0
+	added := datastructures.Live{Title: "added", StartTime: day.AddDate(0, 0, 3)}
+	lives := []datastructures.Live{newUnchanged, newModified, added}
+
+	changeset, err := buildChangeset(lives, oldLives)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changeset.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged live, got %d", changeset.Unchanged)
+	}
+	if len(changeset.Added) != 1 || changeset.Added[0].Title != "added" {
+		t.Errorf("expected live \"added\" to be added, got %v", changeset.Added)
+	}
+	if len(changeset.Deleted) != 1 || changeset.Deleted[0].ID != 3 {
+		t.Errorf("expected live 3 to be deleted, got %v", changeset.Deleted)
+	}
+	if len(changeset.Modified) != 1 {
+		t.Fatalf("expected 1 modified live, got %d", len(changeset.Modified))
+	}
+	fields := changeset.Modified[0].Fields
+	if len(fields) != 1 || fields[0].Type != datastructures.NotificationFieldPrice || fields[0].OldValue != "2000" || fields[0].NewValue != "2500" {
+		t.Errorf("expected only price to change from 2000 to 2500, got %v", fields)
+	}
+}
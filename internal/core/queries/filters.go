@@ -0,0 +1,114 @@
+package queries
+
+import (
+	"fmt"
+	"slices"
+)
+
+// nightStartExp is the start time of a live in Japan, moved back 6 hours so that lives starting past midnight, which are
+// written in 24+ hour notation, count towards the night before.
+const nightStartExp = `((starttime AT TIME ZONE 'Asia/Tokyo') - INTERVAL '6 hours')`
+
+// nightWeekdayExp is the weekday of the night of a live, where lives with an unknown start time are on their date.
+// It is indexed by idx_lives_night_weekday, so it must be kept in sync with the index.
+const nightWeekdayExp = `EXTRACT(DOW FROM CASE WHEN starttime_unknown THEN starttime AT TIME ZONE 'Asia/Tokyo' ELSE ` + nightStartExp + ` END)`
+
+// dayStartMinutes is the time of day lives starting before are part of the night before, in minutes.
+const dayStartMinutes = 6 * 60
+
+// NightFilters are the filters describing what the night of a live looks like, which are saved along with saved searches.
+type NightFilters struct {
+	// MinPrice and MaxPrice are the range in yen a ticket of the live must be sold within, counting the same tiers as
+	// datastructures.PriceTiers.Range
+	MinPrice *int `form:"minPrice"`
+	MaxPrice *int `form:"maxPrice"`
+	// Weekdays are the weekdays of the night the live is on, where 0 is Sunday
+	Weekdays map[int]bool `form:"weekdays"`
+	// StartAfter and StartBefore are the range the start time of the live must be within, formatted as hh:mm.
+	// Times before 6:00 are the end of the night, so 19:00 to 2:00 includes lives starting past midnight.
+	StartAfter  string `form:"startAfter"`
+	StartBefore string `form:"startBefore"`
+}
+
+// Validate checks that the filters can be used in a query.
+func (f NightFilters) Validate() (err error) {
+	if f.MinPrice != nil && *f.MinPrice < 0 || f.MaxPrice != nil && *f.MaxPrice < 0 {
+		return fmt.Errorf("price range must not be negative")
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return fmt.Errorf("minimum price must not be above maximum price")
+	}
+	for weekday := range f.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return fmt.Errorf("invalid weekday %d", weekday)
+		}
+	}
+	_, _, err = f.startRange()
+	return
+}
+
+// weekdays returns the selected weekdays in order.
+func (f NightFilters) weekdays() (weekdays []int) {
+	for weekday, isActive := range f.Weekdays {
+		if isActive {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	slices.Sort(weekdays)
+	return
+}
+
+// startRange returns the start time range in minutes past midnight, which are nil if not set.
+func (f NightFilters) startRange() (after *int, before *int, err error) {
+	if f.StartAfter != "" {
+		var minutes int
+		minutes, err = parseTimeOfDay(f.StartAfter)
+		if err != nil {
+			return
+		}
+		after = &minutes
+	}
+	if f.StartBefore != "" {
+		var minutes int
+		minutes, err = parseTimeOfDay(f.StartBefore)
+		if err != nil {
+			return
+		}
+		before = &minutes
+	}
+	return
+}
+
+// parseTimeOfDay parses a time formatted as hh:mm into minutes past midnight, allowing 24+ hour notation.
+func parseTimeOfDay(s string) (minutes int, err error) {
+	var hour, minute int
+	_, err = fmt.Sscanf(s, "%d:%d", &hour, &minute)
+	if err != nil || hour < 0 || hour >= 30 || minute < 0 || minute >= 60 {
+		err = fmt.Errorf("invalid time of day %q", s)
+		return
+	}
+	minutes = hour*60 + minute
+	return
+}
+
+// startTimeCondition returns a condition comparing the start time of the night of a live to the time of day minutes,
+// an SQL expression of minutes past midnight. It is indexed by idx_lives_night_start.
+func startTimeCondition(operator string, minutes string) string {
+	return fmt.Sprintf("(NOT starttime_unknown AND %s::time %s TIME '00:00' + make_interval(mins => %s - %d))", nightStartExp, operator, minutes, dayStartMinutes)
+}
+
+// ticketAmountsExp selects the amounts of the price tiers of the live aliased live that count towards its price range.
+// It must be kept in sync with datastructures.PriceRangeExcludedTiers.
+const ticketAmountsExp = `SELECT p.amount FROM live_prices p WHERE p.lives_id = live.id AND p.tier_type NOT IN ('drink', 'streaming', 'student') AND NOT p.unknown`
+
+// savedSearchNightConditions matches lives to the night filters of the saved search aliased ss, where amounts selects
+// the ticket prices of the live.
+func savedSearchNightConditions(amounts string) string {
+	return `(cardinality(ss.weekdays) = 0 OR ` + nightWeekdayExp + ` = ANY(ss.weekdays))
+		AND (ss.start_after IS NULL OR ` + startTimeCondition(">=", "ss.start_after") + `)
+		AND (ss.start_before IS NULL OR ` + startTimeCondition("<=", "ss.start_before") + `)
+		AND (
+			(ss.min_price IS NULL AND ss.max_price IS NULL) OR
+			EXISTS (SELECT 1 FROM (` + amounts + `) a(amount) WHERE (ss.min_price IS NULL OR a.amount >= ss.min_price) AND (ss.max_price IS NULL OR a.amount <= ss.max_price))
+		)`
+}
@@ -0,0 +1,90 @@
+package queries
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected int
+		valid    bool
+	}{
+		{"19:00", 19 * 60, true},
+		{"09:30", 9*60 + 30, true},
+		{"25:30", 25*60 + 30, true},
+		{"30:00", 0, false},
+		{"19:60", 0, false},
+		{"evening", 0, false},
+	}
+	for _, test := range tests {
+		minutes, err := parseTimeOfDay(test.s)
+		if (err == nil) != test.valid {
+			t.Errorf("parseTimeOfDay(%q): expected valid %t, got error %v", test.s, test.valid, err)
+			continue
+		}
+		if minutes != test.expected {
+			t.Errorf("parseTimeOfDay(%q): expected %d, got %d", test.s, test.expected, minutes)
+		}
+	}
+}
+
+func TestNightFiltersValidate(t *testing.T) {
+	valid := []NightFilters{
+		{},
+		{MinPrice: util.Pointer(0), MaxPrice: util.Pointer(3000)},
+		{Weekdays: map[int]bool{0: true, 6: true}, StartBefore: "17:00"},
+		{StartAfter: "19:00", StartBefore: "26:00"},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", f, err)
+		}
+	}
+
+	invalid := []NightFilters{
+		{MaxPrice: util.Pointer(-1)},
+		{MinPrice: util.Pointer(3000), MaxPrice: util.Pointer(2000)},
+		{Weekdays: map[int]bool{7: true}},
+		{StartAfter: "7pm"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", f)
+		}
+	}
+}
+
+func TestTicketAmountsExpExcludedTiers(t *testing.T) {
+	excluded := make([]string, 0)
+	for _, tierType := range datastructures.PriceRangeExcludedTiers {
+		excluded = append(excluded, fmt.Sprintf("'%s'", tierType))
+	}
+	expected := "tier_type NOT IN (" + strings.Join(excluded, ", ") + ") AND NOT "
+	if !strings.Contains(ticketAmountsExp, "p."+expected+"p.unknown") {
+		t.Errorf("expected ticketAmountsExp to contain %q, got %q", "p."+expected+"p.unknown", ticketAmountsExp)
+	}
+
+	// the partial index on price amounts is only used if its predicate matches
+	migration, err := os.ReadFile("../../../migrations/20261017170000-live-prices.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(migration), "WHERE "+expected+"unknown;") {
+		t.Errorf("expected idx_live_prices_amount to be filtered by %q", expected+"unknown")
+	}
+}
+
+func TestNightFiltersWeekdays(t *testing.T) {
+	f := NightFilters{Weekdays: map[int]bool{6: true, 0: true, 3: false}}
+	if weekdays := f.weekdays(); !slices.Equal(weekdays, []int{0, 6}) {
+		t.Errorf("expected weekdays [0 6], got %v", weekdays)
+	}
+}
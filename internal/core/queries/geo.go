@@ -0,0 +1,87 @@
+package queries
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the earth, used to measure distances between coordinates.
+const earthRadiusKm = 6371
+
+// GeoFilters are the filters locating the venue of a live, as used by the map and searches near a point.
+type GeoFilters struct {
+	// Lat and Lng are the point lives are searched around, which the distance of every live is measured from
+	Lat *float64 `form:"lat"`
+	Lng *float64 `form:"lng"`
+	// Radius is the distance in km the venue must be within from the point, or unlimited if zero
+	Radius float64 `form:"radius"`
+	// BBox is the area the venue must be within, formatted as west,south,east,north like Leaflet's toBBoxString
+	BBox string `form:"bbox"`
+	// SortByDistance sorts lives by their distance from the point instead of by date
+	SortByDistance bool `form:"sortByDistance"`
+}
+
+// BoundingBox is an area between two latitudes and two longitudes.
+type BoundingBox struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+// ParseBoundingBox parses a bounding box formatted as west,south,east,north.
+func ParseBoundingBox(s string) (b BoundingBox, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		err = fmt.Errorf("invalid bounding box %q", s)
+		return
+	}
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		values[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			err = fmt.Errorf("invalid bounding box %q: %w", s, err)
+			return
+		}
+	}
+	b = BoundingBox{West: values[0], South: values[1], East: values[2], North: values[3]}
+	if b.South > b.North || b.West > b.East || b.South < -90 || b.North > 90 || b.West < -180 || b.East > 180 {
+		err = fmt.Errorf("invalid bounding box %q", s)
+	}
+	return
+}
+
+// HasPoint reports whether lives are searched around a point.
+func (f GeoFilters) HasPoint() bool {
+	return f.Lat != nil && f.Lng != nil
+}
+
+// Validate checks that the filters can be used in a query.
+func (f GeoFilters) Validate() (err error) {
+	if (f.Lat == nil) != (f.Lng == nil) {
+		return fmt.Errorf("lat and lng must be given together")
+	}
+	if f.HasPoint() && (*f.Lat < -90 || *f.Lat > 90 || *f.Lng < -180 || *f.Lng > 180) {
+		return fmt.Errorf("invalid point %f,%f", *f.Lat, *f.Lng)
+	}
+	if f.Radius < 0 {
+		return fmt.Errorf("radius must not be negative")
+	}
+	if (f.Radius != 0 || f.SortByDistance) && !f.HasPoint() {
+		return fmt.Errorf("radius and sortByDistance require lat and lng")
+	}
+	if f.BBox != "" {
+		_, err = ParseBoundingBox(f.BBox)
+	}
+	return
+}
+
+// distanceExp returns the great-circle distance in km between the venue aliased livehouse and the point whose
+// coordinates are the query arguments lat and lng.
+func distanceExp(lat, lng int) string {
+	return fmt.Sprintf(
+		"(%d * 2 * asin(least(1, sqrt(power(sin(radians(livehouse.latitude - $%d::float8) / 2), 2) + cos(radians($%d::float8)) * cos(radians(livehouse.latitude)) * power(sin(radians(livehouse.longitude - $%d::float8) / 2), 2)))))",
+		earthRadiusKm, lat, lat, lng,
+	)
+}
@@ -0,0 +1,51 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	b, err := ParseBoundingBox("135.4,34.6, 135.6,34.8")
+	if err != nil {
+		t.Fatalf("expected valid bounding box, got %v", err)
+	}
+	expected := BoundingBox{West: 135.4, South: 34.6, East: 135.6, North: 34.8}
+	if b != expected {
+		t.Errorf("expected %+v, got %+v", expected, b)
+	}
+
+	for _, s := range []string{"", "135.4,34.6,135.6", "135.6,34.6,135.4,34.8", "135.4,34.6,135.6,north", "135.4,-91,135.6,34.8"} {
+		if _, err := ParseBoundingBox(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestGeoFiltersValidate(t *testing.T) {
+	valid := []GeoFilters{
+		{},
+		{Lat: util.Pointer(34.67), Lng: util.Pointer(135.5), Radius: 2, SortByDistance: true},
+		{BBox: "135.4,34.6,135.6,34.8"},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", f, err)
+		}
+	}
+
+	invalid := []GeoFilters{
+		{Lat: util.Pointer(34.67)},
+		{Lat: util.Pointer(95.0), Lng: util.Pointer(135.5)},
+		{Lat: util.Pointer(34.67), Lng: util.Pointer(135.5), Radius: -1},
+		{Radius: 2},
+		{SortByDistance: true},
+		{BBox: "osaka"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", f)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+// AnomalyGuard specifies the thresholds used to detect scrapes that differ suspiciously from the existing lives of a venue.
+//
+// This usually means that the venue has redesigned its site and the connector is broken,
+// in which case applying the scrape would delete the schedule of the venue and notify every user who favorited its lives.
+//
+// Only upcoming lives are compared, as lives in the past are not expected to be scraped.
+type AnomalyGuard struct {
+	// MinimumLives is the number of existing upcoming lives below which no checks are done.
+	// Small venues fluctuate too much for the ratios to be meaningful.
+	MinimumLives int
+
+	// MaxCountDrop is the largest allowed relative drop in the number of upcoming lives, 0-1.
+	MaxCountDrop float64
+
+	// MinCoverage is the smallest allowed share of the existing schedule length covered by the scrape, 0-1.
+	// For instance, if the existing lives extend 6 months into the future and MinCoverage is 0.5,
+	// the scraped lives must extend at least 3 months into the future.
+	MinCoverage float64
+
+	// MaxUnmatchedShare is the largest allowed share of existing upcoming lives not matched by any scraped live, 0-1.
+	MaxUnmatchedShare float64
+}
+
+var DefaultAnomalyGuard = AnomalyGuard{
+	MinimumLives:      10,
+	MaxCountDrop:      0.5,
+	MinCoverage:       0.5,
+	MaxUnmatchedShare: 0.5,
+}
+
+func upcomingLives(lives []datastructures.Live, now time.Time) (upcoming []datastructures.Live, last time.Time) {
+	for _, live := range lives {
+		if !live.StartTime.After(now) {
+			continue
+		}
+		upcoming = append(upcoming, live)
+		if live.StartTime.After(last) {
+			last = live.StartTime
+		}
+	}
+	return
+}
+
+// Check compares scraped lives with the existing lives of the same venues,
+// returning a description of every anomaly found.
+func (g AnomalyGuard) Check(lives []datastructures.Live, oldLives []datastructures.Live, now time.Time) (anomalies []string) {
+	oldUpcoming, oldLast := upcomingLives(oldLives, now)
+	if len(oldUpcoming) < g.MinimumLives {
+		return
+	}
+	newUpcoming, newLast := upcomingLives(lives, now)
+
+	if float64(len(newUpcoming)) < float64(len(oldUpcoming))*(1-g.MaxCountDrop) {
+		anomalies = append(anomalies, fmt.Sprintf("upcoming lives dropped from %d to %d", len(oldUpcoming), len(newUpcoming)))
+	}
+
+	if len(newUpcoming) != 0 && float64(newLast.Sub(now)) < float64(oldLast.Sub(now))*g.MinCoverage {
+		anomalies = append(anomalies, fmt.Sprintf("schedule now ends %s, previously ended %s", newLast.Format(time.DateOnly), oldLast.Format(time.DateOnly)))
+	}
+
+	_, unmatched := matchLives(lives, oldLives)
+	unmatchedUpcoming, _ := upcomingLives(unmatched, now)
+	if float64(len(unmatchedUpcoming)) > float64(len(oldUpcoming))*g.MaxUnmatchedShare {
+		anomalies = append(anomalies, fmt.Sprintf("%d of %d upcoming lives would be deleted", len(unmatchedUpcoming), len(oldUpcoming)))
+	}
+	return
+}
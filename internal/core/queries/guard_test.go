@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func createWeeklyLives(now time.Time, firstID int, count int) (lives []datastructures.Live) {
+	for i := 0; i < count; i++ {
+		lives = append(lives, datastructures.Live{
+			ID:        firstID + i,
+			Title:     "live",
+			StartTime: now.AddDate(0, 0, 7*(i+1)),
+		})
+	}
+	return
+}
+
+func TestAnomalyGuard(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldLives := createWeeklyLives(now, 1, 20)
+	newLives := createWeeklyLives(now, 0, 20)
+	for i := range newLives {
+		newLives[i].ID = 0
+	}
+
+	if anomalies := DefaultAnomalyGuard.Check(newLives, oldLives, now); len(anomalies) != 0 {
+		t.Errorf("expected identical scrape to pass, got %v", anomalies)
+	}
+
+	if anomalies := DefaultAnomalyGuard.Check(newLives[:2], oldLives, now); len(anomalies) != 3 {
+		t.Errorf("expected broken scrape to fail all checks, got %v", anomalies)
+	}
+
+	if anomalies := DefaultAnomalyGuard.Check(newLives[:15], oldLives, now); len(anomalies) != 0 {
+		t.Errorf("expected slightly shorter scrape to pass, got %v", anomalies)
+	}
+
+	shifted := createWeeklyLives(now.Add(time.Hour), 0, 20)
+	for i := range shifted {
+		shifted[i].Title = "another live"
+	}
+	if anomalies := DefaultAnomalyGuard.Check(shifted, oldLives, now); len(anomalies) != 1 {
+		t.Errorf("expected scrape matching no lives to fail, got %v", anomalies)
+	}
+
+	if anomalies := DefaultAnomalyGuard.Check(newLives[:2], oldLives[:5], now); len(anomalies) != 0 {
+		t.Errorf("expected small venue to be ignored, got %v", anomalies)
+	}
+}
@@ -64,6 +64,11 @@ func deleteLives(tx pgx.Tx, ctx context.Context, lives []datastructures.Live) (d
 		}
 	}
 
+	err = queueLiveArtistSimilarity(ctx, tx, liveIds)
+	if err != nil {
+		return
+	}
+
 	cmd, err := tx.Exec(ctx, "DELETE FROM lives WHERE id=ANY($1)", liveIds)
 	if err != nil {
 		return
@@ -72,14 +77,18 @@ func deleteLives(tx pgx.Tx, ctx context.Context, lives []datastructures.Live) (d
 	return
 }
 
-func addLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, artists *map[string]bool, liveartists *[][]interface{}) (added int, err error) {
+func addLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, artists map[string]string, liveartists *[][]interface{}) (added int, err error) {
+	var credits map[string]string
+	live.Artists, credits = creditedArtists(live.Artists, artists)
 	var liveid int
 	err = tx.QueryRow(
 		ctx,
-		"INSERT INTO lives (title, opentime, starttime, url, price, price_en, livehouses_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		"INSERT INTO lives (title, opentime, starttime, opentime_unknown, starttime_unknown, url, price, price_en, livehouses_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
 		live.Title,
 		live.OpenTime,
 		live.StartTime,
+		live.OpenTimeUnknown,
+		live.StartTimeUnknown,
 		live.URL,
 		live.Price,
 		live.PriceEnglish,
@@ -91,14 +100,14 @@ func addLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, artists *
 	}
 	live.ID = liveid
 
+	err = putPriceTiers(ctx, tx, liveid, live.Price)
+	if err != nil {
+		return
+	}
+
 	added++
-	liveartistmap := make(map[string]bool)
 	for _, artist := range live.Artists {
-		liveartistmap[artist] = true
-		(*artists)[artist] = true
-	}
-	for k := range liveartistmap {
-		*liveartists = append(*liveartists, []interface{}{liveid, k})
+		*liveartists = append(*liveartists, []interface{}{liveid, artist, credits[artist]})
 	}
 
 	err = notifyNewLive(ctx, tx, live)
@@ -129,6 +138,9 @@ func shouldUpdateLive(live datastructures.Live, oldLive datastructures.Live) boo
 	if live.StartTime != oldLive.StartTime {
 		return true
 	}
+	if live.OpenTimeUnknown != oldLive.OpenTimeUnknown || live.StartTimeUnknown != oldLive.StartTimeUnknown {
+		return true
+	}
 	if live.Price != oldLive.Price {
 		return true
 	}
@@ -148,6 +160,14 @@ func shouldUpdateLive(live datastructures.Live, oldLive datastructures.Live) boo
 	return false
 }
 
+// formatFieldTime formats a live time for a notification field, leaving out the time of day if it is unknown.
+func formatFieldTime(t time.Time, unknown bool) string {
+	if unknown {
+		return datastructures.GetLiveDate(t)
+	}
+	return t.Format(time.RFC3339)
+}
+
 func getNotificationFields(live, oldLive datastructures.Live) (fields []datastructures.NotificationField, err error) {
 	oldLiveArtists, err := json.Marshal(oldLive.Artists)
 	if err != nil {
@@ -164,12 +184,12 @@ func getNotificationFields(live, oldLive datastructures.Live) (fields []datastru
 		NewValue: live.Title,
 	}, {
 		Type:     datastructures.NotificationFieldOpenTime,
-		OldValue: oldLive.OpenTime.Format(time.RFC3339),
-		NewValue: live.OpenTime.Format(time.RFC3339),
+		OldValue: formatFieldTime(oldLive.OpenTime, oldLive.OpenTimeUnknown),
+		NewValue: formatFieldTime(live.OpenTime, live.OpenTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldStartTime,
-		OldValue: oldLive.StartTime.Format(time.RFC3339),
-		NewValue: live.StartTime.Format(time.RFC3339),
+		OldValue: formatFieldTime(oldLive.StartTime, oldLive.StartTimeUnknown),
+		NewValue: formatFieldTime(live.StartTime, live.StartTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldPrice,
 		OldValue: oldLive.Price,
@@ -193,17 +213,26 @@ func getNotificationFields(live, oldLive datastructures.Live) (fields []datastru
 	}}, nil
 }
 
-func tryUpdateLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, oldLive datastructures.Live, artists *map[string]bool, liveartists *[][]interface{}) (modified int, err error) {
+func tryUpdateLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, oldLive datastructures.Live, artists map[string]string, liveartists *[][]interface{}) (modified int, err error) {
+	var credits map[string]string
+	live.Artists, credits = creditedArtists(live.Artists, artists)
 	if !shouldUpdateLive(live, oldLive) {
 		return
 	}
 
-	_, err = tx.Exec(ctx, "UPDATE lives SET (title, opentime, starttime, url, price, price_en, livehouses_id) = ($1, $2, $3, $4, $5, $6, $7) WHERE id=$8", live.Title, live.OpenTime, live.StartTime, live.URL, live.Price, live.PriceEnglish, live.Venue.ID, oldLive.ID)
+	_, err = tx.Exec(ctx, "UPDATE lives SET (title, opentime, starttime, opentime_unknown, starttime_unknown, url, price, price_en, livehouses_id) = ($1, $2, $3, $4, $5, $6, $7, $8, $9) WHERE id=$10", live.Title, live.OpenTime, live.StartTime, live.OpenTimeUnknown, live.StartTimeUnknown, live.URL, live.Price, live.PriceEnglish, live.Venue.ID, oldLive.ID)
 	if err != nil {
 		return
 	}
 	modified = 1
 
+	if live.Price != oldLive.Price {
+		err = putPriceTiers(ctx, tx, oldLive.ID, live.Price)
+		if err != nil {
+			return
+		}
+	}
+
 	err = notifyUpdates(ctx, tx, oldLive, live)
 	if err != nil {
 		// ignore and log
@@ -211,14 +240,8 @@ func tryUpdateLive(tx pgx.Tx, ctx context.Context, live datastructures.Live, old
 		err = nil
 	}
 
-	liveartistmap := make(map[string]bool)
 	for _, artist := range live.Artists {
-		liveartistmap[artist] = true
-		(*artists)[artist] = true
-	}
-
-	for k := range liveartistmap {
-		*liveartists = append(*liveartists, []interface{}{oldLive.ID, k})
+		*liveartists = append(*liveartists, []interface{}{oldLive.ID, artist, credits[artist]})
 	}
 
 	err = notifyChangedLive(ctx, tx, live)
@@ -273,45 +296,73 @@ func notifyUpdates(ctx context.Context, tx pgx.Tx, oldLive, live datastructures.
 	return
 }
 
-func updateAndAddLives(tx pgx.Tx, ctx context.Context, lives []datastructures.Live, oldLives []datastructures.Live) (artists map[string]bool, liveartists [][]interface{}, added int, modified int, deleted int, err error) {
-	oldLiveFoundIds := make(map[int]bool)
-	liveartists = make([][]interface{}, 0)
-	artists = make(map[string]bool)
+// liveMatch is a scraped live, along with the existing live it corresponds to, if any.
+type liveMatch struct {
+	live    datastructures.Live
+	oldLive datastructures.Live
+	found   bool
+}
 
+// matchLives pairs every scraped live with the existing live it corresponds to,
+// and returns the existing lives that were not matched by any scraped live.
+func matchLives(lives []datastructures.Live, oldLives []datastructures.Live) (matches []liveMatch, unmatched []datastructures.Live) {
+	oldLiveFoundIds := make(map[int]bool)
 	for _, live := range lives {
-		foundLive := false
+		match := liveMatch{live: live}
 		for _, oldLive := range oldLives {
 			if !isSameLive(live, oldLive, oldLives, lives) {
 				continue
 			}
-			foundLive = true
+			match.oldLive = oldLive
+			match.found = true
 			oldLiveFoundIds[oldLive.ID] = true
-			m, _ := tryUpdateLive(tx, ctx, live, oldLive, &artists, &liveartists)
-			modified += m
 			break
 		}
+		matches = append(matches, match)
+	}
 
-		if foundLive {
+	unmatched = make([]datastructures.Live, 0)
+	for _, oldLive := range oldLives {
+		if !oldLiveFoundIds[oldLive.ID] {
+			unmatched = append(unmatched, oldLive)
+		}
+	}
+	return
+}
+
+// updateAndAddLives saves the given lives, where artists is the artist of every credit of the lives.
+func updateAndAddLives(tx pgx.Tx, ctx context.Context, lives []datastructures.Live, oldLives []datastructures.Live, artists map[string]string) (liveartists [][]interface{}, added int, modified int, deleted int, err error) {
+	liveartists = make([][]interface{}, 0)
+
+	matches, oldLivesToDelete := matchLives(lives, oldLives)
+	for _, match := range matches {
+		if match.found {
+			m, _ := tryUpdateLive(tx, ctx, match.live, match.oldLive, artists, &liveartists)
+			modified += m
 			continue
 		}
 
-		a, err := addLive(tx, ctx, live, &artists, &liveartists)
+		a, err := addLive(tx, ctx, match.live, artists, &liveartists)
 		if err == nil {
 			added += a
 		}
 	}
-	oldLivesToDelete := make([]datastructures.Live, 0)
-	for _, oldLive := range oldLives {
-		if !oldLiveFoundIds[oldLive.ID] {
-			oldLivesToDelete = append(oldLivesToDelete, oldLive)
-		}
-	}
 	deleted, err = deleteLives(tx, ctx, oldLivesToDelete)
 
 	return
 }
 
+// PostLives updates the lives of the venues of the given lives to match them.
+//
+// Scrapes that differ suspiciously from the existing lives are quarantined for manual approval instead of applied,
+// returning ErrLivesQuarantined. See AnomalyGuard for details.
 func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request) (deleted int, added int, modified int, addedArtists int, err error) {
+	return postLives(ctx, lives, r, 0)
+}
+
+// postLives saves the lives of a scrape. quarantineID is the quarantined scrape being approved, which is marked as
+// approved in the same transaction, or 0 to check the lives using the anomaly guard instead.
+func postLives(ctx context.Context, lives []datastructures.Live, r *http.Request, quarantineID int) (deleted int, added int, modified int, addedArtists int, err error) {
 	venues := make([]datastructures.LiveHouse, 0)
 	for _, live := range lives {
 		venues = append(venues, live.Venue)
@@ -347,29 +398,55 @@ func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request
 		return
 	}
 
+	if quarantineID == 0 {
+		anomalies := DefaultAnomalyGuard.Check(lives, oldLives.Lives, time.Now())
+		if len(anomalies) != 0 {
+			var id int
+			id, err = quarantineLives(ctx, livehouses, lives, anomalies)
+			if err != nil {
+				return
+			}
+			err = fmt.Errorf("%w as scrape %d: %s", ErrLivesQuarantined, id, strings.Join(anomalies, "; "))
+			return
+		}
+	}
+
+	newartists := 0
+	credits := make([]string, 0)
+	for _, live := range lives {
+		credits = append(credits, live.Artists...)
+	}
+	artists, addedArtists, err := PostArtists(ctx, credits)
+	if err != nil {
+		return
+	}
+	logging.AddArtists(newartists)
+
 	tx, err := counters.FetchTransaction(ctx)
 	if err != nil {
 		return
 	}
 	defer counters.RollbackTransaction(ctx, tx)
 
-	artists, liveartists, added, modified, d, err := updateAndAddLives(tx, ctx, lives, oldLives.Lives)
+	if quarantineID != 0 {
+		// claiming the scrape first locks it, so that approving it twice at once applies it only once
+		err = setQuarantineStatus(ctx, tx, quarantineID, datastructures.QuarantineStatusApproved)
+		if err != nil {
+			return
+		}
+	}
+	// pending scrapes of these live houses are older than these lives, and approving them would undo them
+	err = supersedeQuarantinedScrapes(ctx, tx, livehouses)
 	if err != nil {
-		fmt.Println("updateandaddlives: ", err)
 		return
 	}
-	deleted += d
 
-	newartists := 0
-	artistSlice := make([]string, 0)
-	for artist := range artists {
-		artistSlice = append(artistSlice, artist)
-	}
-	addedArtists, err = PostArtists(ctx, artistSlice)
+	liveartists, added, modified, d, err := updateAndAddLives(tx, ctx, lives, oldLives.Lives, artists)
 	if err != nil {
+		fmt.Println("updateandaddlives: ", err)
 		return
 	}
-	logging.AddArtists(newartists)
+	deleted += d
 
 	fmt.Println(liveartists)
 
@@ -381,7 +458,7 @@ func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request
 	_, err = tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"tmp_liveartists"},
-		[]string{"lives_id", "artists_name"},
+		[]string{"lives_id", "artists_name", "credit"},
 		pgx.CopyFromRows(liveartists),
 	)
 	if err != nil {
@@ -393,6 +470,12 @@ func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request
 		liveids = append(liveids, a[0].(int))
 	}
 
+	// artists that no longer play the changed lives are queued along with those that now do
+	err = queueLiveArtistSimilarity(ctx, tx, liveids)
+	if err != nil {
+		return
+	}
+
 	_, err = tx.Exec(ctx, "DELETE FROM liveartists WHERE lives_id=ANY($1)", liveids)
 	if err != nil {
 		return
@@ -403,6 +486,21 @@ func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request
 		return
 	}
 
+	err = queueLiveArtistSimilarity(ctx, tx, liveids)
+	if err != nil {
+		return
+	}
+
+	// lives without artists are not in liveartists, so they are indexed when they have not been yet
+	unindexed, err := getSearchLiveIDs(ctx, tx, livehouses, false)
+	if err != nil {
+		return
+	}
+	err = putSearchDocuments(ctx, tx, append(liveids, unindexed...))
+	if err != nil {
+		return
+	}
+
 	err = counters.CommitTransaction(ctx, tx)
 	if err != nil {
 		return
@@ -412,21 +510,43 @@ func PostLives(ctx context.Context, lives []datastructures.Live, r *http.Request
 	return
 }
 
+// upcomingLiveCondition matches the lives that have not started yet, where lives with an unknown start time are
+// upcoming for the whole of their date.
+const upcomingLiveCondition = "(starttime > NOW() OR (starttime_unknown AND starttime > NOW() - INTERVAL '1 day'))"
+
 type LiveQuery struct {
 	Areas             map[int]bool    `form:"areas"`
 	Artist            string          `form:"artist"`
+	ArtistName        string          `form:"-"`
+	Search            string          `form:"q"`
 	From              time.Time       `form:"from"`
 	To                time.Time       `form:"to"`
 	Id                int             `form:"id"`
+	Ids               []int           `form:"-"`
 	IncludeOldLives   bool            `form:"includeOldLives"`
 	LiveHouses        []string        `form:"livehouses"`
 	UserFavoritesId   int             `form:"userFavoritesId"`
 	LiveListId        int             `form:"liveListId"`
 	SavedSearchUserId int             `form:"savedSearchUserId"`
-	Limit             int             `form:"limit"`
-	Offset            int             `form:"offset"`
 	AdditionalArtists map[string]bool `form:"additionalArtists"`
 	AllowAllLocations bool            `form:"allowAllLocations"`
+	NightFilters
+	GeoFilters
+	Pagination
+}
+
+// Validate checks that the filters of the query can be used.
+func (query LiveQuery) Validate() (err error) {
+	err = query.NightFilters.Validate()
+	if err != nil {
+		return
+	}
+	err = query.GeoFilters.Validate()
+	if err != nil {
+		return
+	}
+	err = query.validatePagination()
+	return
 }
 
 func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser, r *http.Request) (lives datastructures.Lives, err error) {
@@ -445,7 +565,47 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 	}
 	args := []any{}
 
-	queryStr := `WITH queriedlives AS ( SELECT live.id, array_agg(DISTINCT liveartists.artists_name) AS matching_artists, live.title AS live_title, opentime, starttime, COALESCE(live.price,'') AS price, COALESCE(live.price_en,'') AS price_en, livehouses_id, COALESCE(livehouse.url,'') AS livehouse_url, COALESCE(livehouse.description,'') AS livehouse_description, livehouse.areas_id AS areas_id, area.prefecture AS prefecture, area.name AS name, COALESCE(live.url,'') AS live_url, longitude, latitude, COALESCE(event.open_id, '') AS open_id, COALESCE(event.start_id, '') AS start_id, COUNT(*) OVER() AS count`
+	distance := "NULL::float8"
+	if query.HasPoint() {
+		distance = distanceExp(incIndex(), incIndex())
+		args = append(args, *query.Lat, *query.Lng)
+	}
+
+	rank := "NULL::real"
+	var search textSearch
+	var searchCondition string
+	if query.Search != "" {
+		search, err = parseTextSearch(query.Search)
+		if err != nil {
+			return
+		}
+		tsquery, like, text := incIndex(), incIndex(), incIndex()
+		args = append(args, search.tsquery, "%"+escapeLike(search.text)+"%", search.text)
+		// lives are found by their words, or by the search text anywhere in them when mecab splits it differently
+		searchCondition = fmt.Sprintf("(doc.tokens @@ to_tsquery('simple', $%d) OR doc.document ILIKE $%d)", tsquery, like)
+		rank = fmt.Sprintf("(ts_rank(doc.tokens, to_tsquery('simple', $%d)) + word_similarity($%d, doc.document))", tsquery, text)
+	}
+
+	sort := query.sort()
+	keys := sortKeys(sort, distance, rank)
+	var cursor liveCursor
+	if query.Cursor != "" {
+		cursor, err = decodeLiveCursor(query.Cursor)
+		if err != nil {
+			return
+		}
+	}
+	sortColumns := make([]string, len(keys))
+	sortExps := make([]string, len(keys))
+	for i, key := range keys {
+		sortColumns[i] = fmt.Sprintf("sort_%d", i)
+		sortExps[i] = key.exp
+	}
+
+	queryStr := `WITH queriedlives AS ( SELECT live.id, array_agg(DISTINCT liveartists.artists_name) AS matching_artists, live.title AS live_title, opentime, starttime, opentime_unknown, starttime_unknown, COALESCE(live.price,'') AS price, COALESCE(live.price_en,'') AS price_en, livehouses_id, COALESCE(livehouse.url,'') AS livehouse_url, COALESCE(livehouse.description,'') AS livehouse_description, livehouse.areas_id AS areas_id, area.prefecture AS prefecture, area.name AS name, COALESCE(live.url,'') AS live_url, longitude, latitude, COALESCE(event.open_id, '') AS open_id, COALESCE(event.start_id, '') AS start_id, ` + distance + ` AS distance, ` + rank + ` AS rank`
+	for i, exp := range sortExps {
+		queryStr += fmt.Sprintf(", %s AS %s", exp, sortColumns[i])
+	}
 	if query.LiveListId != 0 {
 		queryStr += ", livelistlive.id AS livelistlive_id, livelist.users_id AS livelist_owner_id, live_description"
 	}
@@ -458,6 +618,11 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		LEFT JOIN artistaliases alias ON (alias.artists_name = liveartists.artists_name)
 		`
 
+	if query.Search != "" {
+		queryStr += `INNER JOIN live_search_documents doc ON (doc.lives_id = live.id)
+		`
+	}
+
 	if query.UserFavoritesId != 0 {
 		queryStr += `INNER JOIN userfavorites uf ON (uf.lives_id = live.id)
 		`
@@ -474,9 +639,10 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		queryStr += fmt.Sprintf(`
 		INNER JOIN saved_searches ss ON (
 			ss.users_id = $%d AND
-			alias.alias ILIKE ss.keyword
+			alias.alias ILIKE ss.keyword AND
+			%s
 		)
-		`, incIndex())
+		`, incIndex(), savedSearchNightConditions(ticketAmountsExp))
 		args = append(args, query.SavedSearchUserId)
 	}
 	queryStr += `LEFT JOIN calendarevents event ON (event.lives_id = live.id)`
@@ -496,7 +662,7 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 	}
 
 	if !query.IncludeOldLives {
-		addCondition("starttime > NOW()")
+		addCondition(upcomingLiveCondition)
 	}
 
 	if len(query.Areas) != 0 {
@@ -517,6 +683,11 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		}
 	}
 
+	// unlike Artist, which matches aliases shared by other artists, ArtistName matches only the lives of that artist
+	if query.ArtistName != "" {
+		addCondition("liveartists.artists_name = $%d", query.ArtistName)
+	}
+
 	if !query.From.IsZero() {
 		addCondition("live.starttime >= $%d", query.From)
 	}
@@ -525,10 +696,43 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		addCondition("live.starttime <= $%d", query.To)
 	}
 
+	if query.MinPrice != nil || query.MaxPrice != nil {
+		condition := "EXISTS (SELECT 1 FROM (" + ticketAmountsExp + ") a(amount) WHERE TRUE"
+		conditionArgs := make([]any, 0)
+		if query.MinPrice != nil {
+			condition += " AND a.amount >= $%d"
+			conditionArgs = append(conditionArgs, *query.MinPrice)
+		}
+		if query.MaxPrice != nil {
+			condition += " AND a.amount <= $%d"
+			conditionArgs = append(conditionArgs, *query.MaxPrice)
+		}
+		addCondition(condition+")", conditionArgs...)
+	}
+
+	if weekdays := query.weekdays(); len(weekdays) != 0 {
+		addCondition(nightWeekdayExp+" = ANY($%d)", weekdays)
+	}
+
+	startAfter, startBefore, err := query.startRange()
+	if err != nil {
+		return
+	}
+	if startAfter != nil {
+		addCondition(startTimeCondition(">=", "$%d::int"), *startAfter)
+	}
+	if startBefore != nil {
+		addCondition(startTimeCondition("<=", "$%d::int"), *startBefore)
+	}
+
 	if query.Id != 0 {
 		addCondition("live.id = $%d", query.Id)
 	}
 
+	if len(query.Ids) != 0 {
+		addCondition("live.id = ANY($%d)", query.Ids)
+	}
+
 	if len(query.LiveHouses) != 0 {
 		addCondition("livehouses_id=ANY($%d)", query.LiveHouses)
 	}
@@ -537,20 +741,50 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		addCondition("uf.users_id=$%d", query.UserFavoritesId)
 	}
 
-	queryStr += `
-		GROUP BY live.id, live_title, opentime, starttime, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, latitude, longitude, open_id, start_id`
+	if query.Search != "" {
+		addCondition(searchCondition)
+	}
+
+	if query.Radius != 0 {
+		addCondition(distance+" <= $%d", query.Radius)
+	}
+
+	if query.BBox != "" {
+		var bbox BoundingBox
+		bbox, err = ParseBoundingBox(query.BBox)
+		if err != nil {
+			return
+		}
+		addCondition("livehouse.latitude BETWEEN $%d AND $%d AND livehouse.longitude BETWEEN $%d AND $%d", bbox.South, bbox.North, bbox.West, bbox.East)
+	}
+
+	// pages continue after the last live of the previous page, so they do not shift when lives are added in between
+	if query.Cursor != "" {
+		params := make([]int, len(keys))
+		for i, key := range cursor.Keys {
+			params[i] = incIndex()
+			args = append(args, key)
+		}
+		addCondition(keysetCondition(keys, params, cursor.Backwards))
+	}
 
-	if query.Offset != 0 {
-		queryStr += fmt.Sprintf(" OFFSET $%d", incIndex())
-		args = append(args, query.Offset)
+	queryStr += `
+		GROUP BY live.id, live_title, opentime, starttime, opentime_unknown, starttime_unknown, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, latitude, longitude, open_id, start_id`
+	if query.Search != "" {
+		queryStr += ", doc.lives_id"
 	}
+	// pages before the cursor are fetched in reverse, and an extra live is fetched to know if there are more pages
+	queryStr += orderBy(keys, sortExps, cursor.Backwards)
 	if query.Limit != 0 {
 		queryStr += fmt.Sprintf(" LIMIT $%d", incIndex())
-		args = append(args, query.Limit)
+		args = append(args, query.Limit+1)
 	}
 
 	queryStr += `)
-		SELECT live.id, matching_artists, array_agg(DISTINCT liveartists.artists_name) AS artists, live_title, opentime, starttime, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, longitude, latitude, open_id, start_id, count`
+		SELECT live.id, matching_artists, array_agg(DISTINCT liveartists.artists_name) AS artists, live_title, opentime, starttime, opentime_unknown, starttime_unknown, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, longitude, latitude, open_id, start_id, distance`
+	for _, column := range sortColumns {
+		queryStr += fmt.Sprintf(", %s::text", column)
+	}
 	if query.LiveListId != 0 {
 		queryStr += ", livelistlive.id AS livelistlive_id, livelist.users_id AS livelist_owner_id, live_description"
 	}
@@ -558,25 +792,29 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		FROM queriedlives AS live
 		LEFT JOIN liveartists ON (liveartists.lives_id = live.id)
 		LEFT JOIN artistaliases alias ON (alias.artists_name = liveartists.artists_name)
-		GROUP BY live.id, matching_artists, live_title, opentime, starttime, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, latitude, longitude, open_id, start_id, count`
+		GROUP BY live.id, matching_artists, live_title, opentime, starttime, opentime_unknown, starttime_unknown, price, price_en, livehouses_id, livehouse_url, livehouse_description, areas_id, prefecture, name, live_url, latitude, longitude, open_id, start_id, distance, rank, ` + strings.Join(sortColumns, ", ")
 
 	if query.LiveListId != 0 {
 		queryStr += `, livelistlive_id, livelist_owner_id, live_description`
 	}
-	queryStr += `
-	ORDER BY starttime, id`
+	queryStr += orderBy(keys, sortColumns, cursor.Backwards)
 
 	rows, err := tx.Query(ctx, queryStr, args...)
 	if err != nil {
 		return
 	}
 	defer rows.Close()
+	positions := make([][]string, 0)
 	for rows.Next() {
 		var l datastructures.Live
+		position := make([]string, len(keys))
 		var artists []*string
 		var matchingArtists []*string
 		scans := make([]any, 0)
-		scans = append(scans, &l.ID, &matchingArtists, &artists, &l.Title, &l.OpenTime, &l.StartTime, &l.Price, &l.PriceEnglish, &l.Venue.ID, &l.Venue.Url, &l.Venue.Description, &l.Venue.Area.ID, &l.Venue.Area.Prefecture, &l.Venue.Area.Area, &l.URL, &l.Venue.Longitude, &l.Venue.Latitude, &l.CalendarOpenEventId, &l.CalendarStartEventId, &lives.Paginator.Total)
+		scans = append(scans, &l.ID, &matchingArtists, &artists, &l.Title, &l.OpenTime, &l.StartTime, &l.OpenTimeUnknown, &l.StartTimeUnknown, &l.Price, &l.PriceEnglish, &l.Venue.ID, &l.Venue.Url, &l.Venue.Description, &l.Venue.Area.ID, &l.Venue.Area.Prefecture, &l.Venue.Area.Area, &l.URL, &l.Venue.Longitude, &l.Venue.Latitude, &l.CalendarOpenEventId, &l.CalendarStartEventId, &l.Distance)
+		for i := range position {
+			scans = append(scans, &position[i])
+		}
 		if query.LiveListId != 0 {
 			scans = append(scans, &l.LiveListLiveID, &l.LiveListOwnerID, &l.Desc)
 		}
@@ -595,22 +833,23 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 			}
 		}
 		lives.Lives = append(lives.Lives, l)
+		positions = append(positions, position)
 	}
-	lives.Paginator.Offset = query.Offset
-	lives.Paginator.Limit = query.Limit
-	if query.Limit != 0 {
-		lives.Paginator.Page = (query.Offset / query.Limit) + 1
-	} else {
-		lives.Paginator.Page = 1
-	}
+	lives.Paginator = paginate(&lives.Lives, positions, query.Limit, sort, cursor, query.Cursor != "")
 
-	if lives.Paginator.Total == 0 || lives.Paginator.Limit == 0 {
-		lives.Paginator.TotalPages = 1
-	} else {
-		lives.Paginator.TotalPages = ((lives.Paginator.Total - 1) / lives.Paginator.Limit) + 1
+	liveIDs := make([]int, 0, len(lives.Lives))
+	for _, l := range lives.Lives {
+		liveIDs = append(liveIDs, l.ID)
+	}
+	priceTiers, err := getPriceTiers(ctx, tx, liveIDs)
+	if err != nil {
+		return
 	}
 
 	for i, l := range lives.Lives {
+		lives.Lives[i].PriceTiers = priceTiers[l.ID]
+		setPriceRange(&lives.Lives[i])
+
 		isFavorited, favoriteCount, err := getFavoriteAndCount(ctx, tx, user.ID, l.ID)
 		if err == nil {
 			lives.Lives[i].FavoriteCount = int(favoriteCount)
@@ -619,7 +858,10 @@ func GetLives(ctx context.Context, query LiveQuery, user datastructures.AuthUser
 		err = nil
 
 		lives.Lives[i].Venue.Name = localizer.Localize("livehouse." + lives.Lives[i].Venue.ID)
-		lives.Lives[i].LocalizedTime = i18nloader.FormatOpenStartTime(lives.Lives[i].OpenTime, lives.Lives[i].StartTime, i18nloader.GetLanguages(r))
+		if query.Search != "" {
+			highlightLive(&lives.Lives[i], search.terms, query.Artist == "")
+		}
+		lives.Lives[i].LocalizedTime = i18nloader.FormatOpenStartTime(lives.Lives[i].OpenTime, lives.Lives[i].OpenTimeUnknown, lives.Lives[i].StartTime, lives.Lives[i].StartTimeUnknown, i18nloader.GetLanguages(r))
 		lives.Lives[i].LocalizedPrice = lives.Lives[i].PriceEnglish
 		for _, lang := range i18nloader.GetLanguages(r) {
 			if strings.HasPrefix(lang, "ja") {
@@ -664,10 +906,10 @@ func createNewLiveNotificationFields(live datastructures.Live) (fields []datastr
 		NewValue: live.Title,
 	}, {
 		Type:     datastructures.NotificationFieldOpenTime,
-		NewValue: live.OpenTime.Format(time.RFC3339),
+		NewValue: formatFieldTime(live.OpenTime, live.OpenTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldStartTime,
-		NewValue: live.StartTime.Format(time.RFC3339),
+		NewValue: formatFieldTime(live.StartTime, live.StartTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldPrice,
 		NewValue: live.Price,
@@ -697,10 +939,10 @@ func createOldLiveNotificationFields(live datastructures.Live) (fields []datastr
 		OldValue: live.Title,
 	}, {
 		Type:     datastructures.NotificationFieldOpenTime,
-		OldValue: live.OpenTime.Format(time.RFC3339),
+		OldValue: formatFieldTime(live.OpenTime, live.OpenTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldStartTime,
-		OldValue: live.StartTime.Format(time.RFC3339),
+		OldValue: formatFieldTime(live.StartTime, live.StartTimeUnknown),
 	}, {
 		Type:     datastructures.NotificationFieldPrice,
 		OldValue: live.Price,
@@ -821,13 +1063,21 @@ func notifyDeletedLive(ctx context.Context, tx pgx.Tx, live datastructures.Live)
 }
 
 func getMatchingSavedSearches(ctx context.Context, tx pgx.Tx, live datastructures.Live) (savedSearches []datastructures.SavedSearch, err error) {
+	ticketAmounts := make([]int, 0)
+	for _, tier := range util.ParsePriceTiers(live.Price) {
+		if tier.Type != datastructures.PriceTierDrink && !tier.Unknown {
+			ticketAmounts = append(ticketAmounts, tier.Amount)
+		}
+	}
+
 	for _, artist := range live.Artists {
 		var rows pgx.Rows
 		rows, err = tx.Query(ctx, `
-			SELECT s.users_id, keyword FROM saved_searches s
-			LEFT JOIN user_saved_search_areas a ON s.users_id = a.users_id
-			WHERE $1 ILIKE s.keyword AND (a.areas_id IS NULL OR a.areas_id = $2 OR s.allow_all_locations IS TRUE)
-		`, artist, live.Venue.Area.ID)
+			SELECT ss.users_id, keyword FROM saved_searches ss
+			LEFT JOIN user_saved_search_areas a ON ss.users_id = a.users_id
+			CROSS JOIN (SELECT $3::timestamptz AS starttime, $4::boolean AS starttime_unknown) live
+			WHERE ($1 ILIKE ss.keyword OR EXISTS (SELECT 1 FROM artistaliases alias WHERE alias.artists_name = $1 AND alias.alias ILIKE ss.keyword)) AND (a.areas_id IS NULL OR a.areas_id = $2 OR ss.allow_all_locations IS TRUE) AND
+			`+savedSearchNightConditions("SELECT unnest($5::int[])"), artist, live.Venue.Area.ID, live.StartTime, live.StartTimeUnknown, ticketAmounts)
 		if err != nil {
 			return
 		}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
 	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
 )
@@ -83,20 +84,16 @@ func GetNotification(ctx context.Context, notificationID int, userID int, langs
 			fallthrough
 		case datastructures.NotificationFieldStartTime:
 			if notification.Type == datastructures.NotificationTypeDeleted || notification.Type == datastructures.NotificationTypeEdited {
-				var ot time.Time
-				ot, err = time.Parse(time.RFC3339, f.OldValue)
+				f.OldValue, err = formatFieldTimeValue(f.OldValue, langs)
 				if err != nil {
 					return
 				}
-				f.OldValue = i18nloader.FormatDate(ot, langs, true)
 			}
 			if notification.Type == datastructures.NotificationTypeAdded || notification.Type == datastructures.NotificationTypeEdited {
-				var nt time.Time
-				nt, err = time.Parse(time.RFC3339, f.NewValue)
+				f.NewValue, err = formatFieldTimeValue(f.NewValue, langs)
 				if err != nil {
 					return
 				}
-				f.NewValue = i18nloader.FormatDate(nt, langs, true)
 			}
 
 		case datastructures.NotificationFieldVenue:
@@ -119,3 +116,18 @@ func GetNotification(ctx context.Context, notificationID int, userID int, langs
 	err = counters.CommitTransaction(ctx, tx)
 	return
 }
+
+// formatFieldTimeValue localizes a time stored in a notification field by formatFieldTime.
+func formatFieldTimeValue(value string, langs []string) (formatted string, err error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		formatted = i18nloader.FormatDate(t, langs, true)
+		return
+	}
+	t, err = time.ParseInLocation(time.DateOnly, value, util.JapanTime)
+	if err != nil {
+		return
+	}
+	formatted = i18nloader.FormatDate(t, langs, false)
+	return
+}
@@ -0,0 +1,112 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+// putPriceTiers replaces the price tiers of a live with those parsed from its price, and marks its price as parsed.
+func putPriceTiers(ctx context.Context, tx pgx.Tx, liveID int, price string) (err error) {
+	_, err = tx.Exec(ctx, "DELETE FROM live_prices WHERE lives_id=$1", liveID)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "UPDATE lives SET price_parsed_at = NOW() WHERE id=$1", liveID)
+	if err != nil {
+		return
+	}
+
+	rows := make([][]any, 0)
+	for i, tier := range util.ParsePriceTiers(price) {
+		rows = append(rows, []any{liveID, i, tier.Type, tier.Amount, tier.DrinkRequired, tier.Free, tier.Unknown})
+	}
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"live_prices"},
+		[]string{"lives_id", "position", "tier_type", "amount", "drink_required", "free", "unknown"},
+		pgx.CopyFromRows(rows),
+	)
+	return
+}
+
+// getPriceTiers returns the price tiers of the given lives, keyed by live ID.
+func getPriceTiers(ctx context.Context, tx pgx.Tx, liveIDs []int) (tiers map[int]datastructures.PriceTiers, err error) {
+	tiers = make(map[int]datastructures.PriceTiers)
+	rows, err := tx.Query(ctx, "SELECT lives_id, tier_type, amount, drink_required, free, unknown FROM live_prices WHERE lives_id=ANY($1) ORDER BY lives_id, position", liveIDs)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var liveID int
+		var tier datastructures.PriceTier
+		err = rows.Scan(&liveID, &tier.Type, &tier.Amount, &tier.DrinkRequired, &tier.Free, &tier.Unknown)
+		if err != nil {
+			return
+		}
+		tiers[liveID] = append(tiers[liveID], tier)
+	}
+	err = rows.Err()
+	return
+}
+
+// setPriceRange sets the lowest and highest ticket price of a live from its price tiers.
+func setPriceRange(live *datastructures.Live) {
+	min, max, ok := live.PriceTiers.Range()
+	if !ok {
+		return
+	}
+	live.MinPrice = &min
+	live.MaxPrice = &max
+}
+
+// BackfillPriceTiers parses the price tiers of every live whose price has not been parsed, or of every live if all is
+// set, such as after the parser has been improved.
+func BackfillPriceTiers(ctx context.Context, all bool) (n int, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	query := "SELECT id, COALESCE(price,'') FROM lives"
+	if !all {
+		// lives whose price parses to no tiers have none, so they are told apart by price_parsed_at
+		query += " WHERE price_parsed_at IS NULL"
+	}
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return
+	}
+	prices := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var price string
+		err = rows.Scan(&id, &price)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		prices[id] = price
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+
+	for id, price := range prices {
+		err = putPriceTiers(ctx, tx, id, price)
+		if err != nil {
+			return
+		}
+		n++
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
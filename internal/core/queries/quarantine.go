@@ -0,0 +1,139 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+var (
+	ErrLivesQuarantined     = errors.New("lives quarantined")
+	ErrScrapeNotQuarantined = errors.New("no pending quarantined scrape with this id")
+)
+
+// quarantineLives holds back the lives of a scrape until an admin approves them. Older pending scrapes of the same live
+// houses are superseded by it, so that only the latest scrape can be approved.
+func quarantineLives(ctx context.Context, livehouses []string, lives []datastructures.Live, anomalies []string) (id int, err error) {
+	livesJSON, err := json.Marshal(lives)
+	if err != nil {
+		return
+	}
+
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = supersedeQuarantinedScrapes(ctx, tx, livehouses)
+	if err != nil {
+		return
+	}
+	err = tx.QueryRow(ctx, "INSERT INTO quarantined_scrapes (livehouses_ids, anomalies, lives) VALUES ($1, $2, $3) RETURNING id", livehouses, anomalies, livesJSON).Scan(&id)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// GetQuarantinedScrapes returns all quarantined scrapes with the given status, oldest first.
+func GetQuarantinedScrapes(ctx context.Context, status datastructures.QuarantineStatus) (scrapes []datastructures.QuarantinedScrape, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, "SELECT id, livehouses_ids, anomalies, lives, status, created_at, resolved_at FROM quarantined_scrapes WHERE status=$1 ORDER BY created_at", status)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var scrape datastructures.QuarantinedScrape
+		var livesJSON []byte
+		err = rows.Scan(&scrape.ID, &scrape.LiveHouses, &scrape.Anomalies, &livesJSON, &scrape.Status, &scrape.CreatedAt, &scrape.ResolvedAt)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal(livesJSON, &scrape.Lives)
+		if err != nil {
+			return
+		}
+		scrapes = append(scrapes, scrape)
+	}
+	err = rows.Err()
+	return
+}
+
+func getPendingQuarantinedLives(ctx context.Context, id int) (lives []datastructures.Live, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	var livesJSON []byte
+	err = tx.QueryRow(ctx, "SELECT lives FROM quarantined_scrapes WHERE id=$1 AND status=$2", id, datastructures.QuarantineStatusPending).Scan(&livesJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrScrapeNotQuarantined
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(livesJSON, &lives)
+	return
+}
+
+// setQuarantineStatus resolves a pending scrape, failing with ErrScrapeNotQuarantined if it is no longer pending.
+func setQuarantineStatus(ctx context.Context, tx pgx.Tx, id int, status datastructures.QuarantineStatus) (err error) {
+	cmd, err := tx.Exec(ctx, "UPDATE quarantined_scrapes SET status=$1, resolved_at=NOW() WHERE id=$2 AND status=$3", status, id, datastructures.QuarantineStatusPending)
+	if err != nil {
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		err = ErrScrapeNotQuarantined
+	}
+	return
+}
+
+// supersedeQuarantinedScrapes resolves the pending scrapes of any of the live houses as superseded.
+func supersedeQuarantinedScrapes(ctx context.Context, tx pgx.Tx, livehouses []string) (err error) {
+	_, err = tx.Exec(ctx, "UPDATE quarantined_scrapes SET status=$1, resolved_at=NOW() WHERE status=$2 AND livehouses_ids && $3", datastructures.QuarantineStatusSuperseded, datastructures.QuarantineStatusPending, livehouses)
+	return
+}
+
+// ApproveQuarantinedScrape applies a quarantined scrape as if it had passed the anomaly guard. The lives are applied
+// and the scrape is marked as approved in one transaction.
+func ApproveQuarantinedScrape(ctx context.Context, id int, r *http.Request) (deleted int, added int, modified int, addedArtists int, err error) {
+	lives, err := getPendingQuarantinedLives(ctx, id)
+	if err != nil {
+		return
+	}
+	deleted, added, modified, addedArtists, err = postLives(ctx, lives, r, id)
+	return
+}
+
+// RejectQuarantinedScrape discards a quarantined scrape, leaving the existing lives untouched.
+func RejectQuarantinedScrape(ctx context.Context, id int) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	err = setQuarantineStatus(ctx, tx, id, datastructures.QuarantineStatusRejected)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
@@ -0,0 +1,173 @@
+package queries
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+const (
+	// maxRecommendations is the number of lives recommended to a user.
+	maxRecommendations = 12
+	// maxRecommendationReasons is the number of reasons given for every recommended live.
+	maxRecommendationReasons = 2
+)
+
+// recommendationMatch is an artist playing an upcoming live who is similar to an artist the user follows.
+type recommendationMatch struct {
+	liveID   int
+	artist   string
+	followed string
+	score    float64
+	coLives  int
+	coFans   int
+}
+
+// liveRecommendation is a live recommended to a user, before the live itself is fetched.
+type liveRecommendation struct {
+	liveID  int
+	score   float64
+	reasons []datastructures.RecommendationReason
+}
+
+// GetRecommendations returns the upcoming lives the user is most likely to like based on the artists they follow, along
+// with why. Lives the user has favorited already are not recommended.
+func GetRecommendations(ctx context.Context, user datastructures.AuthUser, r *http.Request) (recommendations []datastructures.Recommendation, err error) {
+	recommendations = make([]datastructures.Recommendation, 0)
+	if user.ID == 0 {
+		return
+	}
+	ranked, err := getLiveRecommendations(ctx, user.ID)
+	if err != nil || len(ranked) == 0 {
+		return
+	}
+
+	ids := make([]int, len(ranked))
+	for i, recommendation := range ranked {
+		ids[i] = recommendation.liveID
+	}
+	lives, err := GetLives(ctx, LiveQuery{Ids: ids}, user, r)
+	if err != nil {
+		return
+	}
+	livesByID := make(map[int]datastructures.Live, len(lives.Lives))
+	for _, live := range lives.Lives {
+		livesByID[live.ID] = live
+	}
+	for _, recommendation := range ranked {
+		live, ok := livesByID[recommendation.liveID]
+		if !ok {
+			continue
+		}
+		recommendations = append(recommendations, datastructures.Recommendation{
+			Live:    live,
+			Score:   recommendation.score,
+			Reasons: recommendation.reasons,
+		})
+	}
+	return
+}
+
+// getLiveRecommendations returns the IDs of the lives recommended to a user, best first, along with why.
+func getLiveRecommendations(ctx context.Context, userID int) (recommendations []liveRecommendation, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, `WITH followed AS (
+			SELECT DISTINCT artists_name FROM (`+artistFollowsExp+`) f WHERE users_id = $1
+		)
+		SELECT la.lives_id, la.artists_name, s.similar_artists_name, s.score, s.co_lives, s.co_fans FROM liveartists la
+		INNER JOIN lives live ON live.id = la.lives_id
+		INNER JOIN artist_similarity s ON s.artists_name = la.artists_name
+		WHERE s.similar_artists_name IN (SELECT artists_name FROM followed)
+		AND la.artists_name NOT IN (SELECT artists_name FROM followed)
+		AND `+upcomingLiveCondition+`
+		AND NOT EXISTS (SELECT 1 FROM userfavorites uf WHERE uf.lives_id = live.id AND uf.users_id = $1)`, userID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	matches := make([]recommendationMatch, 0)
+	for rows.Next() {
+		var match recommendationMatch
+		err = rows.Scan(&match.liveID, &match.artist, &match.followed, &match.score, &match.coLives, &match.coFans)
+		if err != nil {
+			return
+		}
+		matches = append(matches, match)
+	}
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+	recommendations = rankRecommendations(matches, maxRecommendations)
+	return
+}
+
+// rankRecommendations scores every live by how similar its artists are to the artists the user follows, and returns the
+// n best lives along with the reasons of the artists that count the most towards them.
+func rankRecommendations(matches []recommendationMatch, n int) []liveRecommendation {
+	byLive := make(map[int]map[string][]recommendationMatch)
+	for _, match := range matches {
+		if byLive[match.liveID] == nil {
+			byLive[match.liveID] = make(map[string][]recommendationMatch)
+		}
+		byLive[match.liveID][match.artist] = append(byLive[match.liveID][match.artist], match)
+	}
+
+	recommendations := make([]liveRecommendation, 0, len(byLive))
+	for liveID, artists := range byLive {
+		recommendation := liveRecommendation{liveID: liveID}
+		scores := make(map[string]float64, len(artists))
+		for artist, artistMatches := range artists {
+			for _, match := range artistMatches {
+				scores[artist] += match.score
+			}
+			recommendation.score += scores[artist]
+			recommendation.reasons = append(recommendation.reasons, recommendationReason(artist, artistMatches))
+		}
+		slices.SortFunc(recommendation.reasons, func(a, b datastructures.RecommendationReason) int {
+			return cmp.Or(cmp.Compare(scores[b.Artist], scores[a.Artist]), cmp.Compare(a.Artist, b.Artist))
+		})
+		recommendation.reasons = recommendation.reasons[:min(len(recommendation.reasons), maxRecommendationReasons)]
+		recommendations = append(recommendations, recommendation)
+	}
+
+	slices.SortFunc(recommendations, func(a, b liveRecommendation) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.liveID, b.liveID))
+	})
+	return recommendations[:min(len(recommendations), n)]
+}
+
+// recommendationReason explains why an artist is recommended from their matches with the artists the user follows,
+// which is that they have played with them if they have, and that they share fans with them otherwise.
+func recommendationReason(artist string, matches []recommendationMatch) datastructures.RecommendationReason {
+	slices.SortFunc(matches, func(a, b recommendationMatch) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.followed, b.followed))
+	})
+	reason := datastructures.RecommendationReason{
+		Kind:     datastructures.RecommendationReasonCoBilled,
+		Artist:   artist,
+		Followed: make([]string, 0),
+	}
+	for _, match := range matches {
+		if match.coLives > 0 {
+			reason.Followed = append(reason.Followed, match.followed)
+		}
+	}
+	if len(reason.Followed) != 0 {
+		return reason
+	}
+	reason.Kind = datastructures.RecommendationReasonCoFollowed
+	for _, match := range matches {
+		reason.Followed = append(reason.Followed, match.followed)
+	}
+	return reason
+}
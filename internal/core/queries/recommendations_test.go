@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestRankRecommendations(t *testing.T) {
+	matches := []recommendationMatch{
+		{liveID: 1, artist: "yonige", followed: "SHISHAMO", score: 0.2, coLives: 2},
+		{liveID: 1, artist: "yonige", followed: "リーガルリリー", score: 0.3, coLives: 1},
+		{liveID: 1, artist: "yonige", followed: "ヨルシカ", score: 0.1, coFans: 4},
+		{liveID: 1, artist: "the peggies", followed: "ヨルシカ", score: 0.05, coFans: 2},
+		{liveID: 1, artist: "ハンブレッダーズ", followed: "SHISHAMO", score: 0.01, coFans: 1},
+		{liveID: 2, artist: "the peggies", followed: "ヨルシカ", score: 0.4, coFans: 2},
+		{liveID: 3, artist: "Hump Back", followed: "SHISHAMO", score: 0.1, coLives: 1},
+	}
+	ranked := rankRecommendations(matches, 2)
+	if len(ranked) != 2 || ranked[0].liveID != 1 || ranked[1].liveID != 2 {
+		t.Fatalf("expected lives 1 and 2, got %+v", ranked)
+	}
+
+	reasons := ranked[0].reasons
+	if len(reasons) != maxRecommendationReasons {
+		t.Fatalf("expected %d reasons, got %+v", maxRecommendationReasons, reasons)
+	}
+	// artists that have played with followed artists are explained by them only, most similar first
+	if reasons[0].Artist != "yonige" || reasons[0].Kind != datastructures.RecommendationReasonCoBilled || !slices.Equal(reasons[0].Followed, []string{"リーガルリリー", "SHISHAMO"}) {
+		t.Errorf("unexpected first reason %+v", reasons[0])
+	}
+	if reasons[1].Artist != "the peggies" || reasons[1].Kind != datastructures.RecommendationReasonCoFollowed || !slices.Equal(reasons[1].Followed, []string{"ヨルシカ"}) {
+		t.Errorf("unexpected second reason %+v", reasons[1])
+	}
+	if key := reasons[1].LocalizationKey(); key != "recommendation.co-followed-one" {
+		t.Errorf("unexpected localization key %s", key)
+	}
+
+	if ranked := rankRecommendations(nil, 2); len(ranked) != 0 {
+		t.Errorf("expected no recommendations, got %+v", ranked)
+	}
+}
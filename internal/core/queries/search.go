@@ -0,0 +1,188 @@
+package queries
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
+)
+
+// searchDocument returns the text a live is searched by, made up of its title, artists, artist aliases and the name of
+// its venue in every language, normalized and one per line.
+func searchDocument(title string, venueID string, artists []string, aliases []string) string {
+	parts := []string{title}
+	parts = append(parts, artists...)
+	parts = append(parts, aliases...)
+	parts = append(parts, i18nloader.LocalizeAll("livehouse."+venueID)...)
+
+	seen := make(map[string]bool)
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = util.NormalizeSearchText(strings.ReplaceAll(part, "\n", " "))
+		if part == "" || seen[part] {
+			continue
+		}
+		seen[part] = true
+		lines = append(lines, part)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// putSearchDocuments indexes the given lives for full-text search, replacing their previous search documents.
+func putSearchDocuments(ctx context.Context, tx pgx.Tx, liveIDs []int) (err error) {
+	rows, err := tx.Query(ctx, `SELECT live.id, COALESCE(live.title, ''), live.livehouses_id, array_remove(array_agg(DISTINCT liveartists.artists_name), NULL), array_remove(array_agg(DISTINCT alias.alias), NULL)
+		FROM lives AS live
+		LEFT JOIN liveartists ON (liveartists.lives_id = live.id)
+		LEFT JOIN artistaliases alias ON (alias.artists_name = liveartists.artists_name)
+		WHERE live.id = ANY($1)
+		GROUP BY live.id`, liveIDs)
+	if err != nil {
+		return
+	}
+	documents := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var title, venueID string
+		var artists, aliases []string
+		err = rows.Scan(&id, &title, &venueID, &artists, &aliases)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		documents[id] = searchDocument(title, venueID, artists, aliases)
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+
+	for id, document := range documents {
+		var tokens []string
+		tokens, err = util.SearchTokens(document)
+		if err != nil {
+			return
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO live_search_documents (lives_id, document, tokens) VALUES ($1, $2, to_tsvector('simple', $3))
+			ON CONFLICT (lives_id) DO UPDATE SET document = EXCLUDED.document, tokens = EXCLUDED.tokens`, id, document, strings.Join(tokens, " "))
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// getSearchLiveIDs returns the IDs of the lives to index, which are those without a search document unless all is set,
+// at the given venues or at any venue if nil.
+func getSearchLiveIDs(ctx context.Context, tx pgx.Tx, livehouses []string, all bool) (liveIDs []int, err error) {
+	query := "SELECT id FROM lives WHERE TRUE"
+	args := []any{}
+	if !all {
+		query += " AND NOT EXISTS (SELECT 1 FROM live_search_documents d WHERE d.lives_id = lives.id)"
+	}
+	if livehouses != nil {
+		query += " AND livehouses_id = ANY($1)"
+		args = append(args, livehouses)
+	}
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return
+		}
+		liveIDs = append(liveIDs, id)
+	}
+	err = rows.Err()
+	return
+}
+
+// ReindexSearchDocuments indexes every live without a search document for full-text search, or every live if all is
+// set, such as after the aliases of artists have changed.
+func ReindexSearchDocuments(ctx context.Context, all bool) (n int, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	liveIDs, err := getSearchLiveIDs(ctx, tx, nil, all)
+	if err != nil {
+		return
+	}
+
+	err = putSearchDocuments(ctx, tx, liveIDs)
+	if err != nil {
+		return
+	}
+	n = len(liveIDs)
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// textSearch is the full-text search of a LiveQuery, parsed into what is needed to query and highlight it.
+type textSearch struct {
+	// text is the normalized search text, matched as a substring when its words are not found
+	text string
+	// tsquery matches documents containing every word of the search, which may be incomplete
+	tsquery string
+	// terms are highlighted in the results
+	terms []string
+}
+
+func parseTextSearch(s string) (search textSearch, err error) {
+	search.text = util.NormalizeSearchText(s)
+	tokens, err := util.SearchTokens(search.text)
+	if err != nil {
+		return
+	}
+	search.tsquery = tsqueryFromTokens(tokens)
+	search.terms = util.SearchTerms(search.text, tokens)
+	return
+}
+
+// tsqueryFromTokens builds a tsquery matching every token as a prefix, quoting them so that they are never parsed as
+// operators.
+func tsqueryFromTokens(tokens []string) string {
+	quoted := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		token = strings.ReplaceAll(token, `\`, `\\`)
+		token = strings.ReplaceAll(token, `'`, `''`)
+		quoted = append(quoted, "'"+token+"':*")
+	}
+	return strings.Join(quoted, " & ")
+}
+
+// highlightLive highlights the terms of a search in the title and venue name of a live, and if matchArtists is set,
+// marks the artists matching them.
+func highlightLive(live *datastructures.Live, terms []string, matchArtists bool) {
+	if h := util.HighlightMatches(live.Title, terms); h.HasMatch() {
+		live.TitleHighlight = h
+	}
+	if h := util.HighlightMatches(live.Venue.Name, terms); h.HasMatch() {
+		live.VenueHighlight = h
+	}
+	if !matchArtists {
+		return
+	}
+	live.MatchingArtists = nil
+	for _, artist := range live.Artists {
+		if util.HighlightMatches(artist, terms).HasMatch() {
+			live.MatchingArtists = append(live.MatchingArtists, artist)
+		}
+	}
+}
+
+// escapeLike escapes the wildcards of s, so that it is matched literally by LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,20 @@
+package queries
+
+import "testing"
+
+func TestTsqueryFromTokens(t *testing.T) {
+	res := tsqueryFromTokens([]string{"ワンマン", "rock'n'roll", `a\b`})
+	expected := `'ワンマン':* & 'rock''n''roll':* & 'a\\b':*`
+	if res != expected {
+		t.Errorf("expected %s, got %s", expected, res)
+	}
+	if res := tsqueryFromTokens(nil); res != "" {
+		t.Errorf("expected empty tsquery, got %s", res)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if res := escapeLike(`100%_\`); res != `100\%\_\\` {
+		t.Errorf("unexpected escaped pattern %s", res)
+	}
+}
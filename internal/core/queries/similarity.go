@@ -0,0 +1,303 @@
+package queries
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yayuyokitano/livefetcher/internal/core/counters"
+)
+
+const (
+	// coLiveShare and coFanShare are how much sharing lives and sharing fans count towards the similarity of artists.
+	coLiveShare = 0.6
+	coFanShare  = 0.4
+	// maxSimilarArtists is the number of most similar artists kept for every artist computed.
+	maxSimilarArtists = 50
+	// similarityBatchSize is the number of queued artists computed in every transaction.
+	similarityBatchSize = 100
+)
+
+// artistFollowsExp lists the artists every user follows as (users_id, artists_name), which are the artists of the lives
+// they have favorited and the artists matched by their saved searches. It is computed into the artist_follows temporary
+// table once for every batch of artists, rather than for every artist.
+const artistFollowsExp = `SELECT uf.users_id, la.artists_name FROM userfavorites uf INNER JOIN liveartists la ON la.lives_id = uf.lives_id
+	UNION SELECT ss.users_id, alias.artists_name FROM saved_searches ss INNER JOIN artistaliases alias ON alias.alias ILIKE ss.keyword`
+
+// artistPair is what an artist shares with another artist.
+type artistPair struct {
+	artist  string
+	coLives int
+	// coLiveWeight is coLives where every live counts less the more artists play it, so that festivals do not make
+	// every artist playing them similar
+	coLiveWeight float64
+	coFans       int
+	score        float64
+}
+
+// similarityScore returns how similar two artists are from 0 to 1, given what they share and how many lives and fans
+// each of them has.
+func similarityScore(pair artistPair, lives, otherLives, fans, otherFans int) (score float64) {
+	if lives > 0 && otherLives > 0 {
+		score += coLiveShare * pair.coLiveWeight / math.Sqrt(float64(lives)*float64(otherLives))
+	}
+	if fans > 0 && otherFans > 0 {
+		score += coFanShare * float64(pair.coFans) / math.Sqrt(float64(fans)*float64(otherFans))
+	}
+	return
+}
+
+// topArtistPairs sorts pairs by score, most similar first, and keeps the n most similar.
+func topArtistPairs(pairs []artistPair, n int) []artistPair {
+	slices.SortFunc(pairs, func(a, b artistPair) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		if a.artist < b.artist {
+			return -1
+		} else if a.artist > b.artist {
+			return 1
+		}
+		return 0
+	})
+	return pairs[:min(len(pairs), n)]
+}
+
+// UpdateArtistSimilarities computes the similarity of queued artists to other artists, and returns the number of
+// artists computed. Artists are queued when their lives or fans change, so this is run after every crawl.
+//
+// At most limit artists are computed, or every queued artist if limit is 0, so that a long queue can be spread over
+// several crawls. Artists not computed before ctx is done stay queued as well.
+func UpdateArtistSimilarities(ctx context.Context, limit int) (n int, err error) {
+	for limit == 0 || n < limit {
+		if ctx.Err() != nil {
+			return
+		}
+		size := similarityBatchSize
+		if limit != 0 {
+			size = min(size, limit-n)
+		}
+		var computed int
+		computed, err = updateArtistSimilarityBatch(ctx, size)
+		n += computed
+		if err != nil || computed == 0 {
+			return
+		}
+	}
+	return
+}
+
+// QueueAllArtistSimilarities queues every artist to have their similarity computed again.
+func QueueAllArtistSimilarities(ctx context.Context) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	_, err = tx.Exec(ctx, "INSERT INTO artist_similarity_queue (artists_name) SELECT name FROM artists ON CONFLICT DO NOTHING")
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// updateArtistSimilarityBatch computes the similarities of the size artists queued first, skipping those being computed
+// by another crawl.
+func updateArtistSimilarityBatch(ctx context.Context, size int) (n int, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	rows, err := tx.Query(ctx, `DELETE FROM artist_similarity_queue WHERE artists_name IN (
+			SELECT artists_name FROM artist_similarity_queue ORDER BY queued_at LIMIT $1 FOR UPDATE SKIP LOCKED
+		) RETURNING artists_name`, size)
+	if err != nil {
+		return
+	}
+	artists := make([]string, 0)
+	for rows.Next() {
+		var artist string
+		err = rows.Scan(&artist)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		artists = append(artists, artist)
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil || len(artists) == 0 {
+		return
+	}
+
+	err = createArtistFollows(ctx, tx)
+	if err != nil {
+		return
+	}
+
+	for _, artist := range artists {
+		err = updateArtistSimilarity(ctx, tx, artist)
+		if err != nil {
+			return
+		}
+	}
+	n = len(artists)
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// createArtistFollows computes artistFollowsExp into the artist_follows temporary table, which is dropped when tx ends.
+func createArtistFollows(ctx context.Context, tx pgx.Tx) (err error) {
+	_, err = tx.Exec(ctx, "CREATE TEMPORARY TABLE artist_follows ON COMMIT DROP AS "+artistFollowsExp)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "CREATE INDEX ON artist_follows (artists_name)")
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, "CREATE INDEX ON artist_follows (users_id)")
+	if err != nil {
+		return
+	}
+	// temporary tables are not analyzed automatically
+	_, err = tx.Exec(ctx, "ANALYZE artist_follows")
+	return
+}
+
+// updateArtistSimilarity replaces the similarities of an artist with those of the artists most similar to them now. The
+// similarity of other pairs of artists is left as is until they are computed themselves. It requires artist_follows.
+func updateArtistSimilarity(ctx context.Context, tx pgx.Tx, artist string) (err error) {
+	pairs, err := getArtistPairs(ctx, tx, artist)
+	if err != nil {
+		return
+	}
+
+	names := make([]string, 0, len(pairs)+1)
+	names = append(names, artist)
+	for _, pair := range pairs {
+		names = append(names, pair.artist)
+	}
+	lives, err := getArtistCounts(ctx, tx, "SELECT artists_name, COUNT(*) FROM liveartists WHERE artists_name = ANY($1) GROUP BY artists_name", names)
+	if err != nil {
+		return
+	}
+	fans, err := getArtistCounts(ctx, tx, "SELECT artists_name, COUNT(*) FROM artist_follows WHERE artists_name = ANY($1) GROUP BY artists_name", names)
+	if err != nil {
+		return
+	}
+	for i, pair := range pairs {
+		pairs[i].score = similarityScore(pair, lives[artist], lives[pair.artist], fans[artist], fans[pair.artist])
+	}
+	pairs = topArtistPairs(pairs, maxSimilarArtists)
+
+	_, err = tx.Exec(ctx, "DELETE FROM artist_similarity WHERE artists_name = $1 OR similar_artists_name = $1", artist)
+	if err != nil {
+		return
+	}
+	if len(pairs) == 0 {
+		return
+	}
+	// similarity goes both ways, so that the artist is found from the artists similar to them as well
+	from := make([]string, 0, 2*len(pairs))
+	to := make([]string, 0, 2*len(pairs))
+	scores := make([]float64, 0, 2*len(pairs))
+	coLives := make([]int, 0, 2*len(pairs))
+	coFans := make([]int, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		from = append(from, artist, pair.artist)
+		to = append(to, pair.artist, artist)
+		scores = append(scores, pair.score, pair.score)
+		coLives = append(coLives, pair.coLives, pair.coLives)
+		coFans = append(coFans, pair.coFans, pair.coFans)
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO artist_similarity (artists_name, similar_artists_name, score, co_lives, co_fans)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::real[], $4::int[], $5::int[])
+		ON CONFLICT (artists_name, similar_artists_name) DO UPDATE SET (score, co_lives, co_fans) = (EXCLUDED.score, EXCLUDED.co_lives, EXCLUDED.co_fans)`,
+		from, to, scores, coLives, coFans)
+	return
+}
+
+// getArtistPairs returns every artist that shares lives or fans with the artist, along with what they share. It requires
+// artist_follows.
+func getArtistPairs(ctx context.Context, tx pgx.Tx, artist string) (pairs []artistPair, err error) {
+	rows, err := tx.Query(ctx, `WITH colives AS (
+			SELECT other.artists_name, COUNT(*) AS lives, SUM(1.0 / GREATEST(bill.artists - 1, 1)) AS weight FROM liveartists la
+			INNER JOIN liveartists other ON other.lives_id = la.lives_id AND other.artists_name <> la.artists_name
+			CROSS JOIN LATERAL (SELECT COUNT(*) AS artists FROM liveartists b WHERE b.lives_id = la.lives_id) bill
+			WHERE la.artists_name = $1
+			GROUP BY other.artists_name
+		), cofans AS (
+			SELECT other.artists_name, COUNT(DISTINCT other.users_id) AS fans FROM artist_follows fan
+			INNER JOIN artist_follows other ON other.users_id = fan.users_id AND other.artists_name <> fan.artists_name
+			WHERE fan.artists_name = $1
+			GROUP BY other.artists_name
+		)
+		SELECT COALESCE(l.artists_name, f.artists_name), COALESCE(l.lives, 0), COALESCE(l.weight, 0)::float8, COALESCE(f.fans, 0)
+		FROM colives l FULL OUTER JOIN cofans f ON f.artists_name = l.artists_name`, artist)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	pairs = make([]artistPair, 0)
+	for rows.Next() {
+		var pair artistPair
+		err = rows.Scan(&pair.artist, &pair.coLives, &pair.coLiveWeight, &pair.coFans)
+		if err != nil {
+			return
+		}
+		pairs = append(pairs, pair)
+	}
+	err = rows.Err()
+	return
+}
+
+// getArtistCounts returns the counts of a query selecting artists and a count of each of them, given the artists as $1.
+func getArtistCounts(ctx context.Context, tx pgx.Tx, query string, artists []string) (counts map[string]int, err error) {
+	rows, err := tx.Query(ctx, query, artists)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	counts = make(map[string]int, len(artists))
+	for rows.Next() {
+		var artist string
+		var count int
+		err = rows.Scan(&artist, &count)
+		if err != nil {
+			return
+		}
+		counts[artist] = count
+	}
+	err = rows.Err()
+	return
+}
+
+// queueArtistSimilarity queues artists to have their similarity computed after the next crawl.
+func queueArtistSimilarity(ctx context.Context, tx pgx.Tx, artists []string) (err error) {
+	_, err = tx.Exec(ctx, "INSERT INTO artist_similarity_queue (artists_name) SELECT unnest($1::text[]) ON CONFLICT DO NOTHING", artists)
+	return
+}
+
+// queueLiveArtistSimilarity queues the artists of lives to have their similarity computed after the next crawl.
+func queueLiveArtistSimilarity(ctx context.Context, tx pgx.Tx, liveIDs []int) (err error) {
+	_, err = tx.Exec(ctx, "INSERT INTO artist_similarity_queue (artists_name) SELECT DISTINCT artists_name FROM liveartists WHERE lives_id = ANY($1) ON CONFLICT DO NOTHING", liveIDs)
+	return
+}
+
+// queueSavedSearchArtistSimilarity queues the artists matched by a saved search keyword to have their similarity
+// computed after the next crawl.
+func queueSavedSearchArtistSimilarity(ctx context.Context, tx pgx.Tx, keyword string) (err error) {
+	_, err = tx.Exec(ctx, "INSERT INTO artist_similarity_queue (artists_name) SELECT DISTINCT artists_name FROM artistaliases WHERE alias ILIKE $1 ON CONFLICT DO NOTHING", keyword)
+	return
+}
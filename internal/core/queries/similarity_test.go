@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarityScore(t *testing.T) {
+	// artists that only ever play together and share every fan are as similar as can be
+	if score := similarityScore(artistPair{coLives: 4, coLiveWeight: 4, coFans: 3}, 4, 4, 3, 3); math.Abs(score-1) > 1e-9 {
+		t.Errorf("expected 1, got %f", score)
+	}
+	if score := similarityScore(artistPair{coLives: 1, coLiveWeight: 1}, 0, 4, 0, 0); score != 0 {
+		t.Errorf("expected artists without lives or fans to score 0, got %f", score)
+	}
+
+	// playing a festival together counts less than playing a two-man together
+	festival := similarityScore(artistPair{coLives: 1, coLiveWeight: 1.0 / 19}, 10, 10, 0, 0)
+	twoMan := similarityScore(artistPair{coLives: 1, coLiveWeight: 1}, 10, 10, 0, 0)
+	if festival >= twoMan {
+		t.Errorf("expected festival (%f) to score less than two-man (%f)", festival, twoMan)
+	}
+	// as does sharing a live with an artist that plays a lot
+	busy := similarityScore(artistPair{coLives: 1, coLiveWeight: 1}, 10, 200, 0, 0)
+	if busy >= twoMan {
+		t.Errorf("expected busy artist (%f) to score less than %f", busy, twoMan)
+	}
+}
+
+func TestTopArtistPairs(t *testing.T) {
+	pairs := []artistPair{{artist: "c", score: 0.1}, {artist: "b", score: 0.5}, {artist: "a", score: 0.5}, {artist: "d", score: 0.9}}
+	top := topArtistPairs(pairs, 3)
+	if len(top) != 3 || top[0].artist != "d" || top[1].artist != "a" || top[2].artist != "b" {
+		t.Errorf("unexpected top pairs %+v", top)
+	}
+	if top := topArtistPairs(nil, 3); len(top) != 0 {
+		t.Errorf("expected no pairs, got %+v", top)
+	}
+}
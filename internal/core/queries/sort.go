@@ -0,0 +1,233 @@
+package queries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+// LiveSort is an order lives can be listed in.
+type LiveSort string
+
+const (
+	// LiveSortStart lists lives by their start time, where lives with an unknown start time come after the other lives
+	// of their date
+	LiveSortStart LiveSort = "start"
+	// LiveSortLatest lists lives in the reverse of LiveSortStart, which is mostly useful together with past lives
+	LiveSortLatest LiveSort = "latest"
+	// LiveSortAdded lists the lives that were added most recently first
+	LiveSortAdded LiveSort = "added"
+	// LiveSortPopular lists the lives favorited by the most users first
+	LiveSortPopular LiveSort = "popular"
+	// LiveSortPrice lists lives by their cheapest ticket, where lives without a known price come last
+	LiveSortPrice LiveSort = "price"
+	// LiveSortDistance lists the lives nearest to the point searched around first
+	LiveSortDistance LiveSort = "distance"
+	// LiveSortRelevance lists the lives matching the text search best first
+	LiveSortRelevance LiveSort = "relevance"
+)
+
+// LiveSorts are the orders lives can be listed in, in the order they are shown in.
+var LiveSorts = []LiveSort{LiveSortStart, LiveSortLatest, LiveSortAdded, LiveSortPopular, LiveSortPrice, LiveSortDistance, LiveSortRelevance}
+
+// favoriteCountExp is the number of users that have favorited the live aliased live.
+const favoriteCountExp = `(SELECT COUNT(*) FROM userfavorites f WHERE f.lives_id = live.id)`
+
+// minPriceExp is the cheapest ticket of the live aliased live, or the largest int if it is not known so that it sorts last.
+const minPriceExp = `COALESCE((SELECT MIN(a.amount) FROM (` + ticketAmountsExp + `) a(amount)), 2147483647)`
+
+// sortKey is an expression lives are sorted by, the value of which is stored in cursors as text.
+type sortKey struct {
+	exp     string
+	sqlType string
+	desc    bool
+}
+
+// sortKeys returns the keys lives are sorted by in order, given the expressions of their distance and search rank. The
+// last key is always the ID of the live, so that every live has a distinct position.
+func sortKeys(sort LiveSort, distance string, rank string) (keys []sortKey) {
+	switch sort {
+	case LiveSortAdded:
+		return []sortKey{{"live.id", "bigint", true}}
+	case LiveSortLatest:
+		keys = startSortKeys()
+		for i := range keys {
+			keys[i].desc = true
+		}
+		return
+	case LiveSortPopular:
+		keys = []sortKey{{favoriteCountExp, "bigint", true}}
+	case LiveSortPrice:
+		keys = []sortKey{{minPriceExp, "int", false}}
+	case LiveSortDistance:
+		keys = []sortKey{{distance, "float8", false}}
+	case LiveSortRelevance:
+		keys = []sortKey{{rank, "real", true}}
+	default:
+		return startSortKeys()
+	}
+	return append(keys, sortKey{"starttime", "timestamptz", false}, sortKey{"live.id", "bigint", false})
+}
+
+// startSortKeys returns the keys of LiveSortStart.
+func startSortKeys() []sortKey {
+	return []sortKey{
+		{"(starttime AT TIME ZONE 'Asia/Tokyo')::date", "date", false},
+		{"starttime_unknown", "boolean", false},
+		{"starttime", "timestamptz", false},
+		{"live.id", "bigint", false},
+	}
+}
+
+// orderBy returns an ORDER BY clause sorting by the keys, named by the given columns, in reverse if backwards is set.
+func orderBy(keys []sortKey, columns []string, backwards bool) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = columns[i]
+		if key.desc != backwards {
+			parts[i] += " DESC"
+		}
+	}
+	return "\n\t\tORDER BY " + strings.Join(parts, ", ")
+}
+
+// keysetCondition returns a condition matching the lives sorted after the position whose key values are the given query
+// arguments, or before it if backwards is set.
+func keysetCondition(keys []sortKey, params []int, backwards bool) string {
+	clauses := make([]string, len(keys))
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = $%d::text::%s", keys[j].exp, params[j], keys[j].sqlType))
+		}
+		operator := ">"
+		if key.desc != backwards {
+			operator = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s $%d::text::%s", key.exp, operator, params[i], key.sqlType))
+		clauses[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}
+
+// liveCursor is the position a page of lives starts after, or ends before if Backwards is set, as the values of the
+// sort keys of the live at that position.
+type liveCursor struct {
+	Sort      LiveSort `json:"s"`
+	Keys      []string `json:"k"`
+	Backwards bool     `json:"b,omitempty"`
+}
+
+func (c liveCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeLiveCursor(s string) (c liveCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		err = fmt.Errorf("invalid cursor: %w", err)
+		return
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		err = fmt.Errorf("invalid cursor: %w", err)
+	}
+	return
+}
+
+// Pagination is how lives are sorted and which page of them is listed.
+type Pagination struct {
+	Sort LiveSort `form:"sort"`
+	// Cursor is the position of the page, from the paginator of the previous or next page. The first page if empty.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// sort returns the order to list lives in, which is by distance when searching around a point and by relevance when
+// searching by text unless set.
+func (query LiveQuery) sort() LiveSort {
+	if query.Sort != "" {
+		return query.Sort
+	}
+	if query.SortByDistance {
+		return LiveSortDistance
+	}
+	if query.Search != "" {
+		return LiveSortRelevance
+	}
+	return LiveSortStart
+}
+
+// validatePagination checks that the lives can be sorted as requested, and that the cursor is a position in that order.
+func (query LiveQuery) validatePagination() (err error) {
+	sort := query.sort()
+	switch sort {
+	case LiveSortStart, LiveSortLatest, LiveSortAdded, LiveSortPopular, LiveSortPrice:
+	case LiveSortDistance:
+		if !query.HasPoint() {
+			return fmt.Errorf("sorting by distance requires lat and lng")
+		}
+	case LiveSortRelevance:
+		if query.Search == "" {
+			return fmt.Errorf("sorting by relevance requires q")
+		}
+	default:
+		return fmt.Errorf("invalid sort %q", sort)
+	}
+	if query.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if query.Cursor == "" {
+		return
+	}
+	cursor, err := decodeLiveCursor(query.Cursor)
+	if err != nil {
+		return
+	}
+	if cursor.Sort != sort || len(cursor.Keys) != len(sortKeys(sort, "", "")) {
+		return fmt.Errorf("cursor is not a position in the %s order", sort)
+	}
+	return
+}
+
+// paginate trims the lives fetched for a page, which are in reverse when fetched before the cursor and include an extra
+// live if there are more pages, and returns the paginator linking to the pages before and after it.
+func paginate(lives *[]datastructures.Live, positions [][]string, limit int, sort LiveSort, cursor liveCursor, hasCursor bool) (paginator datastructures.Paginator) {
+	paginator.Limit = limit
+	paginator.Sort = string(sort)
+	hasMore := limit != 0 && len(*lives) > limit
+	if hasMore {
+		*lives = (*lives)[:limit]
+		positions = positions[:limit]
+	}
+	if cursor.Backwards {
+		slices.Reverse(*lives)
+		slices.Reverse(positions)
+	}
+	if len(positions) == 0 {
+		return
+	}
+
+	first := liveCursor{Sort: sort, Keys: positions[0], Backwards: true}
+	last := liveCursor{Sort: sort, Keys: positions[len(positions)-1]}
+	if cursor.Backwards {
+		// the page after is the one the cursor came from
+		paginator.NextCursor = last.encode()
+		if hasMore {
+			paginator.PrevCursor = first.encode()
+		}
+		return
+	}
+	if hasMore {
+		paginator.NextCursor = last.encode()
+	}
+	if hasCursor {
+		paginator.PrevCursor = first.encode()
+	}
+	return
+}
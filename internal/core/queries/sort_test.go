@@ -0,0 +1,121 @@
+package queries
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	keys := []sortKey{{"rank", "real", true}, {"live.id", "bigint", false}}
+	expected := "((rank < $3::text::real) OR (rank = $3::text::real AND live.id > $4::text::bigint))"
+	if res := keysetCondition(keys, []int{3, 4}, false); res != expected {
+		t.Errorf("expected %s, got %s", expected, res)
+	}
+	expected = "((rank > $3::text::real) OR (rank = $3::text::real AND live.id < $4::text::bigint))"
+	if res := keysetCondition(keys, []int{3, 4}, true); res != expected {
+		t.Errorf("expected %s backwards, got %s", expected, res)
+	}
+	if res := orderBy(keys, []string{"sort_0", "sort_1"}, true); res != "\n\t\tORDER BY sort_0, sort_1 DESC" {
+		t.Errorf("unexpected reversed order %q", res)
+	}
+}
+
+func TestLatestSortKeys(t *testing.T) {
+	start, latest := sortKeys(LiveSortStart, "", ""), sortKeys(LiveSortLatest, "", "")
+	if len(start) != len(latest) {
+		t.Fatalf("expected %d keys, got %d", len(start), len(latest))
+	}
+	for i := range start {
+		if latest[i].exp != start[i].exp || latest[i].desc == start[i].desc {
+			t.Errorf("expected key %d to be %s reversed, got %+v", i, start[i].exp, latest[i])
+		}
+	}
+}
+
+func TestLiveCursor(t *testing.T) {
+	cursor := liveCursor{Sort: LiveSortPrice, Keys: []string{"2500", "2026-10-17 19:00:00+09", "42"}, Backwards: true}
+	res, err := decodeLiveCursor(cursor.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Sort != cursor.Sort || !slices.Equal(res.Keys, cursor.Keys) || !res.Backwards {
+		t.Errorf("expected %+v, got %+v", cursor, res)
+	}
+	if _, err := decodeLiveCursor("not a cursor"); err == nil {
+		t.Error("expected invalid cursor to fail")
+	}
+}
+
+func TestValidatePagination(t *testing.T) {
+	valid := []LiveQuery{
+		{},
+		{Pagination: Pagination{Sort: LiveSortPopular}},
+		{GeoFilters: GeoFilters{Lat: util.Pointer(34.67), Lng: util.Pointer(135.5)}, Pagination: Pagination{Sort: LiveSortDistance}},
+		{Search: "ワンマン"},
+		{Pagination: Pagination{Sort: LiveSortAdded, Cursor: liveCursor{Sort: LiveSortAdded, Keys: []string{"42"}}.encode()}},
+	}
+	for _, query := range valid {
+		if err := query.validatePagination(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", query, err)
+		}
+	}
+
+	invalid := []LiveQuery{
+		{Pagination: Pagination{Sort: "cheapest"}},
+		{Pagination: Pagination{Sort: LiveSortDistance}},
+		{Pagination: Pagination{Sort: LiveSortRelevance}},
+		{Pagination: Pagination{Limit: -1}},
+		{Pagination: Pagination{Cursor: liveCursor{Sort: LiveSortAdded, Keys: []string{"42"}}.encode(), Sort: LiveSortPrice}},
+	}
+	for _, query := range invalid {
+		if err := query.validatePagination(); err == nil {
+			t.Errorf("expected %+v to be invalid", query)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	fetched := func(ids ...int) ([]datastructures.Live, [][]string) {
+		lives := make([]datastructures.Live, 0, len(ids))
+		positions := make([][]string, 0, len(ids))
+		for _, id := range ids {
+			lives = append(lives, datastructures.Live{ID: id})
+			positions = append(positions, []string{string(rune('0' + id))})
+		}
+		return lives, positions
+	}
+	ids := func(lives []datastructures.Live) (res []int) {
+		for _, l := range lives {
+			res = append(res, l.ID)
+		}
+		return
+	}
+
+	// the first page, with more after it
+	lives, positions := fetched(1, 2, 3)
+	p := paginate(&lives, positions, 2, LiveSortAdded, liveCursor{}, false)
+	if !slices.Equal(ids(lives), []int{1, 2}) || p.PrevCursor != "" || p.NextCursor == "" {
+		t.Errorf("unexpected first page %v %+v", ids(lives), p)
+	}
+	next, _ := decodeLiveCursor(p.NextCursor)
+	if !slices.Equal(next.Keys, []string{"2"}) || next.Backwards {
+		t.Errorf("unexpected next cursor %+v", next)
+	}
+
+	// a page before a cursor, fetched in reverse, with no more before it
+	lives, positions = fetched(4, 3)
+	p = paginate(&lives, positions, 2, LiveSortAdded, liveCursor{Backwards: true}, true)
+	if !slices.Equal(ids(lives), []int{3, 4}) || p.PrevCursor != "" || p.NextCursor == "" {
+		t.Errorf("unexpected previous page %v %+v", ids(lives), p)
+	}
+
+	// the last page
+	lives, positions = fetched(5)
+	p = paginate(&lives, positions, 2, LiveSortAdded, liveCursor{}, true)
+	if p.NextCursor != "" || p.PrevCursor == "" {
+		t.Errorf("unexpected last page %+v", p)
+	}
+}
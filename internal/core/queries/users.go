@@ -150,6 +150,10 @@ func FavoriteLive(ctx context.Context, userid int, liveid int) (favoriteButtonIn
 	if err != nil {
 		return
 	}
+	err = queueLiveArtistSimilarity(ctx, tx, []int{liveid})
+	if err != nil {
+		return
+	}
 	isFavorited, favoriteCount, err := getFavoriteAndCount(ctx, tx, userid, liveid)
 	if err != nil {
 		return
@@ -175,6 +179,10 @@ func UnfavoriteLive(ctx context.Context, userid int, liveid int) (favoriteButton
 	if err != nil {
 		return
 	}
+	err = queueLiveArtistSimilarity(ctx, tx, []int{liveid})
+	if err != nil {
+		return
+	}
 	isFavorited, favoriteCount, err := getFavoriteAndCount(ctx, tx, userid, liveid)
 	if err != nil {
 		return
@@ -189,7 +197,13 @@ func UnfavoriteLive(ctx context.Context, userid int, liveid int) (favoriteButton
 	return
 }
 
-func PostSavedSearch(ctx context.Context, search string, allowAllLocations bool, user datastructures.AuthUser, r *http.Request) (err error) {
+// PostSavedSearch saves a search for lives by an artist matching search, which are further narrowed down by filters.
+func PostSavedSearch(ctx context.Context, search string, allowAllLocations bool, filters NightFilters, user datastructures.AuthUser, r *http.Request) (err error) {
+	startAfter, startBefore, err := filters.startRange()
+	if err != nil {
+		return
+	}
+
 	tx, err := counters.FetchTransaction(ctx)
 	if err != nil {
 		return
@@ -197,17 +211,99 @@ func PostSavedSearch(ctx context.Context, search string, allowAllLocations bool,
 
 	defer counters.RollbackTransaction(ctx, tx)
 
-	searchText := search
-	if search[0] == '"' && search[len(search)-1] == '"' {
-		searchText = search[1 : len(search)-1]
-	} else {
-		searchText = search + "%"
+	searchText := savedSearchKeyword(search)
+
+	weekdays := filters.weekdays()
+	if weekdays == nil {
+		weekdays = make([]int, 0)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO saved_searches (users_id, keyword, allow_all_locations, min_price, max_price, weekdays, start_after, start_before) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (users_id, keyword) DO UPDATE SET (allow_all_locations, min_price, max_price, weekdays, start_after, start_before) = (EXCLUDED.allow_all_locations, EXCLUDED.min_price, EXCLUDED.max_price, EXCLUDED.weekdays, EXCLUDED.start_after, EXCLUDED.start_before)`,
+		user.ID, searchText, allowAllLocations, filters.MinPrice, filters.MaxPrice, weekdays, startAfter, startBefore,
+	)
+	if err != nil {
+		return
+	}
+	err = queueSavedSearchArtistSimilarity(ctx, tx, searchText)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// DeleteSavedSearch deletes the saved search of a user for lives by an artist matching search.
+func DeleteSavedSearch(ctx context.Context, search string, user datastructures.AuthUser) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
 	}
+	defer counters.RollbackTransaction(ctx, tx)
 
-	_, err = tx.Exec(ctx, "INSERT INTO saved_searches (users_id, keyword, allow_all_locations) VALUES ($1, $2, $3) ON CONFLICT (users_id, keyword) DO UPDATE SET allow_all_locations=EXCLUDED.allow_all_locations", user.ID, searchText, allowAllLocations)
+	keyword := savedSearchKeyword(search)
+	_, err = tx.Exec(ctx, "DELETE FROM saved_searches WHERE users_id = $1 AND lower(keyword) = lower($2)", user.ID, keyword)
 	if err != nil {
 		return
 	}
+	err = queueSavedSearchArtistSimilarity(ctx, tx, keyword)
+	if err != nil {
+		return
+	}
+
+	err = counters.CommitTransaction(ctx, tx)
+	return
+}
+
+// savedSearchKeyword returns the pattern aliases are matched against by a saved search, which is the exact text of a
+// quoted search and a prefix otherwise.
+func savedSearchKeyword(search string) string {
+	if len(search) >= 2 && search[0] == '"' && search[len(search)-1] == '"' {
+		return search[1 : len(search)-1]
+	}
+	return search + "%"
+}
+
+// IsAdmin returns whether a user is an admin, who can moderate what other users suggest.
+func IsAdmin(ctx context.Context, userID int) (admin bool, err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+	return isAdmin(ctx, tx, userID)
+}
+
+func isAdmin(ctx context.Context, tx pgx.Tx, userID int) (admin bool, err error) {
+	if userID == 0 {
+		return
+	}
+	err = tx.QueryRow(ctx, "SELECT is_admin FROM users WHERE id=$1", userID).Scan(&admin)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	return
+}
+
+// SetAdmin makes a user an admin, or revokes it if admin is not set.
+func SetAdmin(ctx context.Context, username string, admin bool) (err error) {
+	tx, err := counters.FetchTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer counters.RollbackTransaction(ctx, tx)
+
+	cmd, err := tx.Exec(ctx, "UPDATE users SET is_admin=$1 WHERE username=$2", admin, username)
+	if err != nil {
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+		return
+	}
 
 	err = counters.CommitTransaction(ctx, tx)
 	return
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	coreconnectors "github.com/yayuyokitano/livefetcher/internal/core/connectors"
 	"github.com/yayuyokitano/livefetcher/internal/core/queries"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 )
 
 func RunConnector(ctx context.Context, connectorID string) (err error) {
@@ -15,29 +18,104 @@ func RunConnector(ctx context.Context, connectorID string) (err error) {
 		err = errors.New("Connector not found: " + connectorID)
 		return
 	}
+	run := datastructures.ConnectorRun{
+		ConnectorID: connectorID,
+		StartedAt:   time.Now(),
+	}
+	defer func() {
+		recordRun(ctx, run, err)
+	}()
+
 	fetcher := coreconnectors.Connectors[connectorID]
-	err = fetcher.Fetch()
+	var cacheStats httpclient.CacheStats
+	err = fetcher.Fetch(httpclient.WithCacheStats(ctx, &cacheStats))
+	run.PagesFetched = fetcher.PagesFetched
+	run.CacheHits = int(cacheStats.Hits.Load())
+	run.CacheMisses = int(cacheStats.Misses.Load())
+	run.LivesParsed = len(fetcher.Lives)
+	// a cancelled fetch has only seen some of the lives, so posting them would delete the rest
+	if ctx.Err() != nil {
+		err = fmt.Errorf("fetch aborted after %d pages and %d lives: %w", run.PagesFetched, run.LivesParsed, context.Cause(ctx))
+		fmt.Println(err)
+		return
+	}
+	// the same goes for a fetch that was stopped by robots.txt
+	var robotsErr *httpclient.RobotsError
+	if errors.As(err, &robotsErr) {
+		fmt.Println(err)
+		return
+	}
 	if len(fetcher.Lives) == 0 {
 		fmt.Println(err)
+		if err == nil {
+			err = errors.New("no lives fetched")
+		}
 		return
 	}
 
-	deleted, added, modified, addedArtists, err := queries.PostLives(ctx, fetcher.Lives, &http.Request{})
+	var addedArtists int
+	run.Deleted, run.Added, run.Modified, addedArtists, err = queries.PostLives(ctx, fetcher.Lives, &http.Request{})
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Printf("Lives: deleted %d, added %d, modified %d, added %d artists\n", deleted, added, modified, addedArtists)
+	fmt.Printf("Lives: deleted %d, added %d, modified %d, added %d artists\n", run.Deleted, run.Added, run.Modified, addedArtists)
 	return
 }
 
-func RunConnectorTest(connectorID string) (err error) {
+// artistSimilarityLimit is the number of queued artists UpdateArtistSimilarities computes after every crawl, so that a
+// long queue, such as every artist after the similarity tables were added, is spread over several crawls.
+const artistSimilarityLimit = 2000
+
+// UpdateArtistSimilarities computes the similarity of the artists queued by connector runs for recommendations. It is
+// run once after every batch of connector runs rather than by every run, so that it does not count towards their time.
+func UpdateArtistSimilarities(ctx context.Context) (err error) {
+	computed, err := queries.UpdateArtistSimilarities(ctx, artistSimilarityLimit)
+	if computed != 0 {
+		fmt.Printf("Artist similarities: computed %d artists\n", computed)
+	}
+	return
+}
+
+// recordRun saves the result of a connector run to the run history.
+func recordRun(ctx context.Context, run datastructures.ConnectorRun, err error) {
+	run.FinishedAt = time.Now()
+	run.Duration = run.FinishedAt.Sub(run.StartedAt)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	run.ErrorKind = classifyRunError(err)
+	// the run should be recorded even if it was cancelled
+	_, dbErr := queries.PostConnectorRun(context.WithoutCancel(ctx), run)
+	if dbErr != nil {
+		fmt.Println(dbErr)
+	}
+}
+
+// classifyRunError returns the kind of error that made a run fail.
+func classifyRunError(err error) datastructures.RunErrorKind {
+	var robotsErr *httpclient.RobotsError
+	switch {
+	case err == nil:
+		return datastructures.RunErrorKindNone
+	case errors.As(err, &robotsErr):
+		return datastructures.RunErrorKindRobots
+	case errors.Is(err, context.DeadlineExceeded):
+		return datastructures.RunErrorKindTimeout
+	case errors.Is(err, queries.ErrLivesQuarantined):
+		return datastructures.RunErrorKindQuarantined
+	default:
+		return datastructures.RunErrorKindOther
+	}
+}
+
+func RunConnectorTest(ctx context.Context, connectorID string) (err error) {
 	if _, ok := coreconnectors.Connectors[connectorID]; !ok {
 		err = errors.New("Connector not found: " + connectorID)
 		return
 	}
 	fetcher := coreconnectors.Connectors[connectorID]
-	err = fetcher.Fetch()
+	err = fetcher.Fetch(ctx)
 	if len(fetcher.Lives) == 0 {
 		fmt.Println(err)
 		return
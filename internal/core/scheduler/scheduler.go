@@ -0,0 +1,287 @@
+// Package scheduler periodically runs connectors, keeping the live database up to date.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RunFunc runs the connector with the given ID.
+type RunFunc func(ctx context.Context, connectorID string) error
+
+// JobFunc runs work that is not specific to a connector, such as processing what connector runs have queued.
+type JobFunc func(ctx context.Context) error
+
+// Config specifies how often and how many connectors are run.
+type Config struct {
+	// Interval is the default time between two runs of the same connector.
+	Interval time.Duration
+
+	// Intervals overrides Interval for specific connectors, keyed by connector ID.
+	Intervals map[string]time.Duration
+
+	// Jitter is the maximum random delay added to every scheduled run.
+	// This spreads the load so that all connectors do not fetch at the same time.
+	Jitter time.Duration
+
+	// Workers is the maximum number of connectors running at the same time.
+	Workers int
+
+	// Timeout is the maximum duration of a single connector run, after which it is cancelled.
+	// This keeps an unresponsive site from occupying a worker forever.
+	Timeout time.Duration
+
+	// BatchTimeout is the maximum duration of the job run after every batch of connector runs, after which it is
+	// cancelled. It does not count towards Timeout.
+	BatchTimeout time.Duration
+
+	// PollInterval specifies how often the scheduler checks for connectors due to run.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns the default scheduler configuration.
+func DefaultConfig() Config {
+	return Config{
+		Interval:     6 * time.Hour,
+		Intervals:    make(map[string]time.Duration),
+		Jitter:       15 * time.Minute,
+		Workers:      4,
+		Timeout:      30 * time.Minute,
+		BatchTimeout: 10 * time.Minute,
+		PollInterval: time.Minute,
+	}
+}
+
+// ConfigFromEnv returns the default configuration, overridden by the CRAWL_INTERVAL, CRAWL_JITTER, CRAWL_WORKERS,
+// CRAWL_TIMEOUT and CRAWL_BATCH_TIMEOUT environment variables if set.
+func ConfigFromEnv() (cfg Config, err error) {
+	cfg = DefaultConfig()
+	if s := os.Getenv("CRAWL_INTERVAL"); s != "" {
+		cfg.Interval, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("CRAWL_JITTER"); s != "" {
+		cfg.Jitter, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("CRAWL_WORKERS"); s != "" {
+		cfg.Workers, err = strconv.Atoi(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("CRAWL_TIMEOUT"); s != "" {
+		cfg.Timeout, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("CRAWL_BATCH_TIMEOUT"); s != "" {
+		cfg.BatchTimeout, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Scheduler runs a set of connectors on a fixed cadence using a bounded worker pool.
+type Scheduler struct {
+	cfg        Config
+	run        RunFunc
+	connectors []string
+	next       map[string]time.Time
+	running    map[string]bool
+	afterBatch JobFunc
+	// ranSinceBatch is whether a connector has finished since afterBatch last ran
+	ranSinceBatch bool
+	mu            sync.Mutex
+	rand          *rand.Rand
+	now           func() time.Time
+}
+
+// New creates a new scheduler running the given connectors using run.
+func New(cfg Config, connectorIDs []string, run RunFunc) *Scheduler {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+	ids := append([]string(nil), connectorIDs...)
+	sort.Strings(ids)
+	return &Scheduler{
+		cfg:        cfg,
+		run:        run,
+		connectors: ids,
+		next:       make(map[string]time.Time),
+		running:    make(map[string]bool),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:        time.Now,
+	}
+}
+
+// interval returns the time between two runs of the given connector.
+func (s *Scheduler) interval(connectorID string) time.Duration {
+	if d, ok := s.cfg.Intervals[connectorID]; ok && d > 0 {
+		return d
+	}
+	return s.cfg.Interval
+}
+
+// jitter returns a random duration between 0 and the configured jitter.
+func (s *Scheduler) jitter() time.Duration {
+	if s.cfg.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(s.rand.Int63n(int64(s.cfg.Jitter)))
+}
+
+// due returns the connectors that are due to run at the given time, and are not already running.
+func (s *Scheduler) due(now time.Time) (ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.connectors {
+		next, ok := s.next[id]
+		if !ok {
+			// spread out the first runs so the workers are not all hitting sites at startup
+			next = now.Add(s.jitter())
+			s.next[id] = next
+		}
+		if s.running[id] || next.After(now) {
+			continue
+		}
+		s.running[id] = true
+		ids = append(ids, id)
+	}
+	return
+}
+
+// finish marks a connector as no longer running, and schedules its next run.
+func (s *Scheduler) finish(connectorID string, finishedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running[connectorID] = false
+	s.ranSinceBatch = true
+	s.next[connectorID] = finishedAt.Add(s.interval(connectorID) + s.jitter())
+}
+
+// AfterBatch sets a job to run once every connector run has finished, and at least one has since the job last ran.
+// Connectors due while it runs wait until it is done, and it is cancelled after BatchTimeout.
+func (s *Scheduler) AfterBatch(job JobFunc) {
+	s.afterBatch = job
+}
+
+// batchFinished reports whether connectors have finished since the last batch, and none are running now. It starts a new
+// batch if so.
+func (s *Scheduler) batchFinished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ranSinceBatch {
+		return false
+	}
+	for _, running := range s.running {
+		if running {
+			return false
+		}
+	}
+	s.ranSinceBatch = false
+	return true
+}
+
+// runAfterBatch runs the job set by AfterBatch, if any, cancelling it if it exceeds the configured batch timeout.
+func (s *Scheduler) runAfterBatch(ctx context.Context) {
+	if s.afterBatch == nil || ctx.Err() != nil {
+		return
+	}
+	if s.cfg.BatchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.BatchTimeout)
+		defer cancel()
+	}
+	err := s.afterBatch(ctx)
+	if err != nil {
+		fmt.Printf("batch job failed: %v\n", err)
+	}
+}
+
+// runWithTimeout runs a connector, cancelling it if it exceeds the configured timeout.
+func (s *Scheduler) runWithTimeout(ctx context.Context, connectorID string) error {
+	if s.cfg.Timeout <= 0 {
+		return s.run(ctx, connectorID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	return s.run(ctx, connectorID)
+}
+
+func (s *Scheduler) worker(ctx context.Context, queue <-chan string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for id := range queue {
+		if ctx.Err() == nil {
+			fmt.Println("running " + id)
+			err := s.runWithTimeout(ctx, id)
+			if err != nil {
+				fmt.Printf("connector %s failed: %v\n", id, err)
+			}
+		}
+		s.finish(id, s.now())
+	}
+}
+
+// RunOnce runs every connector a single time, and returns once all of them have finished.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	var wg sync.WaitGroup
+	queue := make(chan string, len(s.connectors))
+	for _, id := range s.connectors {
+		queue <- id
+	}
+	close(queue)
+	for i := 0; i < min(s.cfg.Workers, len(s.connectors)); i++ {
+		wg.Add(1)
+		go s.worker(ctx, queue, &wg)
+	}
+	wg.Wait()
+	if s.batchFinished() {
+		s.runAfterBatch(ctx)
+	}
+}
+
+// Run runs connectors as they become due until ctx is cancelled.
+// Running connectors are allowed to finish before Run returns.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	queue := make(chan string, len(s.connectors))
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go s.worker(ctx, queue, &wg)
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if s.batchFinished() {
+			s.runAfterBatch(ctx)
+		}
+		for _, id := range s.due(s.now()) {
+			queue <- id
+		}
+		select {
+		case <-ctx.Done():
+			close(queue)
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunOnce(t *testing.T) {
+	var mu sync.Mutex
+	ran := make(map[string]int)
+	var active, maxActive int32
+	run := func(ctx context.Context, connectorID string) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		mu.Lock()
+		ran[connectorID]++
+		mu.Unlock()
+		return nil
+	}
+
+	cfg := DefaultConfig()
+	cfg.Workers = 2
+	s := New(cfg, []string{"a", "b", "c", "d", "e"}, run)
+	s.RunOnce(context.Background())
+
+	if len(ran) != 5 {
+		t.Errorf("expected 5 connectors to run, got %d", len(ran))
+	}
+	for id, n := range ran {
+		if n != 1 {
+			t.Errorf("expected connector %s to run once, ran %d times", id, n)
+		}
+	}
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 connectors running at once, got %d", maxActive)
+	}
+}
+
+func TestDue(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Jitter = 0
+	cfg.Interval = time.Hour
+	cfg.Intervals = map[string]time.Duration{"b": 2 * time.Hour}
+	s := New(cfg, []string{"a", "b"}, nil)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if due := s.due(now); len(due) != 2 {
+		t.Fatalf("expected all connectors to be due initially, got %v", due)
+	}
+	if due := s.due(now); len(due) != 0 {
+		t.Errorf("expected running connectors not to be due, got %v", due)
+	}
+
+	s.finish("a", now)
+	s.finish("b", now)
+	if due := s.due(now.Add(90 * time.Minute)); len(due) != 1 || due[0] != "a" {
+		t.Errorf("expected only a to be due after 90 minutes, got %v", due)
+	}
+	if due := s.due(now.Add(2 * time.Hour)); len(due) != 1 || due[0] != "b" {
+		t.Errorf("expected only b to be due after 2 hours, got %v", due)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Jitter = time.Minute
+	s := New(cfg, nil, nil)
+	for i := 0; i < 100; i++ {
+		if j := s.jitter(); j < 0 || j >= time.Minute {
+			t.Fatalf("jitter %s out of range", j)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var runErr error
+	run := func(ctx context.Context, connectorID string) error {
+		<-ctx.Done()
+		runErr = ctx.Err()
+		return runErr
+	}
+
+	cfg := DefaultConfig()
+	cfg.Timeout = 10 * time.Millisecond
+	s := New(cfg, []string{"a"}, run)
+	s.RunOnce(context.Background())
+
+	if !errors.Is(runErr, context.DeadlineExceeded) {
+		t.Errorf("expected run to be cancelled by timeout, got %v", runErr)
+	}
+}
+
+func TestAfterBatch(t *testing.T) {
+	var ran int32
+	run := func(ctx context.Context, connectorID string) error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+	var jobs int
+	var jobErr error
+	cfg := DefaultConfig()
+	cfg.BatchTimeout = 10 * time.Millisecond
+	s := New(cfg, []string{"a", "b", "c"}, run)
+	s.AfterBatch(func(ctx context.Context) error {
+		jobs++
+		if n := atomic.LoadInt32(&ran); n != 3 {
+			t.Errorf("expected every connector to have run before the job, %d have", n)
+		}
+		<-ctx.Done()
+		jobErr = ctx.Err()
+		return jobErr
+	})
+	s.RunOnce(context.Background())
+
+	if jobs != 1 {
+		t.Errorf("expected the job to run once, ran %d times", jobs)
+	}
+	if !errors.Is(jobErr, context.DeadlineExceeded) {
+		t.Errorf("expected job to be cancelled by batch timeout, got %v", jobErr)
+	}
+}
+
+func TestBatchFinished(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Jitter = 0
+	s := New(cfg, []string{"a", "b"}, nil)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if s.batchFinished() {
+		t.Error("expected no batch to have finished before any connector ran")
+	}
+	s.due(now)
+	s.finish("a", now)
+	if s.batchFinished() {
+		t.Error("expected the batch not to have finished while b is running")
+	}
+	s.finish("b", now)
+	if !s.batchFinished() {
+		t.Error("expected the batch to have finished once every connector has")
+	}
+	if s.batchFinished() {
+		t.Error("expected the batch to finish only once")
+	}
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// artistNote matches a note in brackets at the end of an artist credit, such as 「yonige (ワンマン)」 or 「yonige【LIVE】」.
+var artistNote = regexp.MustCompile(`\s*(?:\([^()]*\)|\[[^\[\]]*\]|【[^【】]*】|<[^<>]*>|〈[^〈〉]*〉|《[^《》]*》)$`)
+
+// ArtistDisplayName returns the name of the artist credited by credit, with full-width letters and half-width kana
+// folded, repeated spaces collapsed and notes in brackets at the end stripped. Credits that are nothing but a note are
+// kept as they are.
+func ArtistDisplayName(credit string) string {
+	name := strings.Join(strings.Fields(norm.NFC.String(width.Fold.String(credit))), " ")
+	for {
+		stripped := artistNote.ReplaceAllString(name, "")
+		if stripped == "" || stripped == name {
+			return name
+		}
+		name = stripped
+	}
+}
+
+// ArtistKey returns the key credits of the same artist share, which is the display name with its case folded as well.
+func ArtistKey(credit string) string {
+	return NormalizeSearchText(ArtistDisplayName(credit))
+}
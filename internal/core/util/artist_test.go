@@ -0,0 +1,29 @@
+package util
+
+import "testing"
+
+func TestArtistDisplayName(t *testing.T) {
+	tests := map[string]string{
+		"yonige":                "yonige",
+		"yonige (ワンマン)":         "yonige",
+		"yonige（ワンマン）【LIVE】":    "yonige",
+		"ＹＯＮＩＧＥ":                "YONIGE",
+		"ﾔﾖｲ  ﾊﾞﾝﾄﾞ":            "ヤヨイ バンド",
+		"(sic)boy":              "(sic)boy",
+		"(ゲスト)":                 "(ゲスト)",
+		"ZAZEN BOYS [Acoustic]": "ZAZEN BOYS",
+	}
+	for credit, expected := range tests {
+		if res := ArtistDisplayName(credit); res != expected {
+			t.Errorf("ArtistDisplayName(%q): expected %q, got %q", credit, expected, res)
+		}
+	}
+}
+
+func TestArtistKey(t *testing.T) {
+	for _, credit := range []string{"yonige", "yonige (ワンマン)", "YONIGE", "ｙｏｎｉｇｅ"} {
+		if res := ArtistKey(credit); res != "yonige" {
+			t.Errorf("ArtistKey(%q): expected %q, got %q", credit, "yonige", res)
+		}
+	}
+}
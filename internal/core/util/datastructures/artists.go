@@ -0,0 +1,81 @@
+package datastructures
+
+import "time"
+
+// AliasSource is where an alias of an artist comes from.
+type AliasSource string
+
+const (
+	// AliasSourceMachine aliases are generated from the name and credits of the artist
+	AliasSourceMachine AliasSource = "machine"
+	// AliasSourceUser aliases were suggested by a user and approved by an admin
+	AliasSourceUser AliasSource = "user"
+	// AliasSourceAdmin aliases were added by an admin
+	AliasSourceAdmin AliasSource = "admin"
+)
+
+func (as AliasSource) LocalizationKey() string {
+	return "artist.alias-source-" + string(as)
+}
+
+type ArtistAlias struct {
+	Alias     string      `json:"alias"`
+	Source    AliasSource `json:"source"`
+	Username  string      `json:"username,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type AliasSuggestionStatus string
+
+const (
+	AliasSuggestionStatusPending  AliasSuggestionStatus = "pending"
+	AliasSuggestionStatusApproved AliasSuggestionStatus = "approved"
+	AliasSuggestionStatusRejected AliasSuggestionStatus = "rejected"
+)
+
+type AliasSuggestion struct {
+	ID         int                   `json:"id"`
+	Artist     string                `json:"artist"`
+	Alias      string                `json:"alias"`
+	Username   string                `json:"username"`
+	Status     AliasSuggestionStatus `json:"status"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	ResolvedAt *time.Time            `json:"resolvedAt"`
+}
+
+// ArtistProfile is what is known about an artist besides their lives, which is edited by admins.
+type ArtistProfile struct {
+	URL         string   `form:"url" json:"url"`
+	Description string   `form:"description" json:"description"`
+	Socials     []string `form:"socials" json:"socials"`
+}
+
+type Artist struct {
+	Name string `json:"name"`
+	ArtistProfile
+	Aliases []ArtistAlias `json:"aliases"`
+	// Venues are the live houses the artist plays most, with the number of lives they played there
+	Venues []ArtistVenue `json:"venues"`
+	// Areas are the areas the artist plays most, with the number of lives they played there
+	Areas []ArtistArea `json:"areas"`
+	// CoBilled are the artists that most often play the same lives as the artist
+	CoBilled []CoBilledArtist `json:"coBilled"`
+	// HasSavedSearch is set if the user viewing the artist has saved a search for them
+	HasSavedSearch bool `json:"hasSavedSearch"`
+}
+
+type ArtistVenue struct {
+	ID    string `json:"id"`
+	Area  Area   `json:"area"`
+	Lives int    `json:"lives"`
+}
+
+type ArtistArea struct {
+	Area  Area `json:"area"`
+	Lives int  `json:"lives"`
+}
+
+type CoBilledArtist struct {
+	Name  string `json:"name"`
+	Lives int    `json:"lives"`
+}
@@ -0,0 +1,16 @@
+package datastructures
+
+type LiveChange struct {
+	Old    Live               `json:"old"`
+	New    Live               `json:"new"`
+	Fields NotificationFields `json:"fields"`
+}
+
+// Changeset describes the changes applying a scrape would make to the existing lives.
+type Changeset struct {
+	Added     []Live       `json:"added"`
+	Modified  []LiveChange `json:"modified"`
+	Deleted   []Live       `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+	Anomalies []string     `json:"anomalies"`
+}
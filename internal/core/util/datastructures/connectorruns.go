@@ -0,0 +1,90 @@
+package datastructures
+
+import "time"
+
+type ConnectorRun struct {
+	ID           int           `json:"id"`
+	ConnectorID  string        `json:"connectorId"`
+	StartedAt    time.Time     `json:"startedAt"`
+	FinishedAt   time.Time     `json:"finishedAt"`
+	Duration     time.Duration `json:"duration"`
+	PagesFetched int           `json:"pagesFetched"`
+	LivesParsed  int           `json:"livesParsed"`
+	Added        int           `json:"added"`
+	Modified     int           `json:"modified"`
+	Deleted      int           `json:"deleted"`
+	CacheHits    int           `json:"cacheHits"`
+	CacheMisses  int           `json:"cacheMisses"`
+	Error        string        `json:"error"`
+	ErrorKind    RunErrorKind  `json:"errorKind"`
+}
+
+// RunErrorKind classifies why a connector run failed, so that failures needing different fixes can be told apart.
+type RunErrorKind string
+
+const (
+	RunErrorKindNone        RunErrorKind = ""
+	RunErrorKindRobots      RunErrorKind = "robots"
+	RunErrorKindTimeout     RunErrorKind = "timeout"
+	RunErrorKindQuarantined RunErrorKind = "quarantined"
+	RunErrorKindOther       RunErrorKind = "error"
+)
+
+func (rek RunErrorKind) LocalizationKey() string {
+	return "status.error-kind." + string(rek)
+}
+
+// CacheHitRate returns the share of pages served from the fetch cache, or -1 if no pages were fetched through it.
+func (cr ConnectorRun) CacheHitRate() float64 {
+	total := cr.CacheHits + cr.CacheMisses
+	if total == 0 {
+		return -1
+	}
+	return float64(cr.CacheHits) / float64(total)
+}
+
+// Failed reports whether the run errored or produced no lives.
+func (cr ConnectorRun) Failed() bool {
+	return cr.Error != "" || cr.LivesParsed == 0
+}
+
+type ConnectorStatus string
+
+const (
+	ConnectorStatusUnknown  ConnectorStatus = "unknown"
+	ConnectorStatusHealthy  ConnectorStatus = "healthy"
+	ConnectorStatusDegraded ConnectorStatus = "degraded"
+	ConnectorStatusBroken   ConnectorStatus = "broken"
+)
+
+func (cs ConnectorStatus) LocalizationKey() string {
+	return "status." + string(cs)
+}
+
+type ConnectorHealth struct {
+	ConnectorID string          `json:"connectorId"`
+	VenueID     string          `json:"venueId"`
+	Status      ConnectorStatus `json:"status"`
+	LastRun     *ConnectorRun   `json:"lastRun"`
+	RecentRuns  []ConnectorRun  `json:"recentRuns"`
+}
+
+type QuarantineStatus string
+
+const (
+	QuarantineStatusPending  QuarantineStatus = "pending"
+	QuarantineStatusApproved QuarantineStatus = "approved"
+	QuarantineStatusRejected QuarantineStatus = "rejected"
+	// QuarantineStatusSuperseded scrapes were left pending until newer lives of the same live houses came in
+	QuarantineStatusSuperseded QuarantineStatus = "superseded"
+)
+
+type QuarantinedScrape struct {
+	ID         int              `json:"id"`
+	LiveHouses []string         `json:"livehouses"`
+	Anomalies  []string         `json:"anomalies"`
+	Lives      []Live           `json:"lives"`
+	Status     QuarantineStatus `json:"status"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	ResolvedAt *time.Time       `json:"resolvedAt"`
+}
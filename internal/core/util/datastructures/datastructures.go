@@ -67,12 +67,12 @@ type GetLivesJsonResponse struct {
 	CalendarEventMap map[string]CalendarEvents `json:"calendarEventMap"`
 }
 
+// Paginator links to the pages before and after a page of lives, the cursors of which are empty if there is none.
 type Paginator struct {
-	Total      int `json:"total"`
-	Offset     int `json:"offset"`
-	Limit      int `json:"limit"`
-	Page       int `json:"page"`
-	TotalPages int `json:"totalPages"`
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	PrevCursor string `json:"prevCursor"`
+	NextCursor string `json:"nextCursor"`
 }
 
 type Lives struct {
@@ -99,20 +99,69 @@ type Live struct {
 	CalendarOpenEventId  string         `json:"calendarOpenEventId"`
 	CalendarStartEventId string         `json:"calendarStartEventId"`
 
+	// only the date of unknown times is known, so they are midnight of that date in Japan
+	OpenTimeUnknown  bool `json:"opentimeUnknown"`
+	StartTimeUnknown bool `json:"starttimeUnknown"`
+
+	// parsed from Price when the live is saved, MinPrice and MaxPrice are nil if no ticket price is known
+	PriceTiers PriceTiers `json:"priceTiers"`
+	MinPrice   *int       `json:"minPrice"`
+	MaxPrice   *int       `json:"maxPrice"`
+
+	// only set when searching around a point, the distance of the venue from it in km
+	Distance *float64 `json:"distance"`
+
+	// only set when searching by text, the title and venue name split into the parts matching the search or not
+	TitleHighlight Highlight `json:"titleHighlight,omitempty"`
+	VenueHighlight Highlight `json:"venueHighlight,omitempty"`
+
 	// only used for livelists
 	LiveListLiveID  int    `json:"liveListLiveId"`
 	LiveListOwnerID int    `json:"liveListOwnerId"`
 	Desc            string `json:"desc"`
 }
 
+// HighlightFragment is a part of a highlighted text, which either matches the search or not.
+type HighlightFragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// Highlight is a text split into the fragments matching a search and those in between.
+type Highlight []HighlightFragment
+
+// HasMatch reports whether any part of the text matches the search.
+func (h Highlight) HasMatch() bool {
+	for _, f := range h {
+		if f.Match {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLiveDate formats the date of a live time in Japan as YYYY-MM-DD, which is all that is known of unknown times.
+func GetLiveDate(t time.Time) string {
+	return t.In(time.FixedZone("UTC+9", +9*60*60)).Format(time.DateOnly)
+}
+
+// GetEventEndTime estimates when a live ends from its number of artists.
+// If neither the open nor start time is known, the live is assumed to last until the end of its date.
 func GetEventEndTime(live Live) time.Time {
+	start := live.StartTime
+	if live.StartTimeUnknown {
+		if live.OpenTimeUnknown {
+			return live.StartTime.AddDate(0, 0, 1)
+		}
+		start = live.OpenTime
+	}
 	switch len(live.Artists) {
 	case 1:
-		return live.StartTime.Add(2 * time.Hour)
+		return start.Add(2 * time.Hour)
 	case 2:
-		return live.StartTime.Add(3 * time.Hour)
+		return start.Add(3 * time.Hour)
 	default:
-		return live.StartTime.Add(time.Duration(min(len(live.Artists), 10)) * time.Hour)
+		return start.Add(time.Duration(min(len(live.Artists), 10)) * time.Hour)
 	}
 }
 
@@ -0,0 +1,58 @@
+package datastructures
+
+import "slices"
+
+// PriceTierType is what a price tier is for, such as tickets bought in advance or the drink charge.
+type PriceTierType string
+
+const (
+	PriceTierGeneral   PriceTierType = "general"
+	PriceTierAdvance   PriceTierType = "advance"
+	PriceTierDoor      PriceTierType = "door"
+	PriceTierStudent   PriceTierType = "student"
+	PriceTierStreaming PriceTierType = "streaming"
+	// PriceTierDrink is the drink charge paid at the door on top of the ticket, rather than a ticket
+	PriceTierDrink PriceTierType = "drink"
+)
+
+// PriceTier is a single price of a live, parsed from its price text.
+type PriceTier struct {
+	Type PriceTierType `json:"type"`
+	// Amount is in yen, and is zero for free and unknown tiers
+	Amount int `json:"amount"`
+	// DrinkRequired is whether a drink must be bought on top of the ticket
+	DrinkRequired bool `json:"drinkRequired"`
+	Free          bool `json:"free"`
+	// Unknown is whether there is a price that could not be parsed, such as one that is yet to be announced
+	Unknown bool `json:"unknown"`
+}
+
+// PriceRangeExcludedTiers are the tier types left out of the price range of a live, which is what a ticket to the venue
+// costs anyone. The drink charge is not a ticket, streaming tickets are not to the venue, and student tickets are not
+// open to everyone.
+var PriceRangeExcludedTiers = []PriceTierType{PriceTierDrink, PriceTierStreaming, PriceTierStudent}
+
+// InPriceRange reports whether tiers of the type count towards the price range of a live.
+func (t PriceTierType) InPriceRange() bool {
+	return !slices.Contains(PriceRangeExcludedTiers, t)
+}
+
+type PriceTiers []PriceTier
+
+// Range returns the lowest and highest ticket price, leaving out the tiers in PriceRangeExcludedTiers.
+// ok is false if no such ticket price is known.
+func (pt PriceTiers) Range() (min int, max int, ok bool) {
+	for _, tier := range pt {
+		if !tier.Type.InPriceRange() || tier.Unknown {
+			continue
+		}
+		if !ok || tier.Amount < min {
+			min = tier.Amount
+		}
+		if !ok || tier.Amount > max {
+			max = tier.Amount
+		}
+		ok = true
+	}
+	return
+}
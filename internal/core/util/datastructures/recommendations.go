@@ -0,0 +1,33 @@
+package datastructures
+
+// RecommendationReasonKind is why an artist playing a recommended live is thought to be liked.
+type RecommendationReasonKind string
+
+const (
+	// RecommendationReasonCoBilled artists have played with artists the user follows
+	RecommendationReasonCoBilled RecommendationReasonKind = "co-billed"
+	// RecommendationReasonCoFollowed artists are followed by users who follow the same artists as the user
+	RecommendationReasonCoFollowed RecommendationReasonKind = "co-followed"
+)
+
+type RecommendationReason struct {
+	Kind   RecommendationReasonKind `json:"kind"`
+	Artist string                   `json:"artist"`
+	// Followed are the artists the user follows that Artist is similar to, most similar first
+	Followed []string `json:"followed"`
+}
+
+// LocalizationKey returns the key of the explanation of the reason, which names the followed artist when there is one.
+func (reason RecommendationReason) LocalizationKey() string {
+	if len(reason.Followed) == 1 {
+		return "recommendation." + string(reason.Kind) + "-one"
+	}
+	return "recommendation." + string(reason.Kind) + "-other"
+}
+
+// Recommendation is a live recommended to a user, which can be shown like any other live along with its reasons.
+type Recommendation struct {
+	Live
+	Score   float64                `json:"score"`
+	Reasons []RecommendationReason `json:"reasons"`
+}
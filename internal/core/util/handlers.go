@@ -12,8 +12,13 @@ type PriceHandler func(p string) (price string, err error)
 
 var timeLayout = "2006-01-02 15:04:05 -0700"
 
-func ParseTime(d string, t string) (res time.Time, err error) {
+// ParseTime parses the first time in t on the date d, which is formatted as YYYY-MM-DD.
+// If t contains no time, res is midnight of d, and unknown is true.
+func ParseTime(d string, t string) (res time.Time, unknown bool, err error) {
 	th, tm, nextDay := GetTimeFromString(t)
+	if th == "" {
+		th, tm, unknown = "00", "00", true
+	}
 	res, err = time.Parse(timeLayout, fmt.Sprintf("%s %s:%s:00 +0900", d, th, tm))
 	if err != nil {
 		return
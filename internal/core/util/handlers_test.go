@@ -4,12 +4,23 @@ import "testing"
 
 func TestSeparateTimeHandler(t *testing.T) {
 	// shimokitazawa mosaic
-	res, err := ParseTime("2023-06-13", "OPEN 18:00")
+	res, unknown, err := ParseTime("2023-06-13", "OPEN 18:00")
 	if err != nil {
 		t.Errorf("SeparateTimeHandler(\"2023-06-13\", \"OPEN 18:00\", \"/\") got error %s", err)
 	}
 	if res.Unix() != 1686646800 {
 		t.Errorf("SeparateTimeHandler(\"2023-06-13\", \"OPEN 18:00\", \"/\") got res %d, want 1686646800", res.Unix())
 	}
+	if unknown {
+		t.Errorf("SeparateTimeHandler(\"2023-06-13\", \"OPEN 18:00\", \"/\") got unknown time")
+	}
+
+	res, unknown, err = ParseTime("2023-06-13", "OPEN TBA")
+	if err != nil {
+		t.Errorf("ParseTime(\"2023-06-13\", \"OPEN TBA\") got error %s", err)
+	}
+	if res.Unix() != 1686582000 || !unknown {
+		t.Errorf("ParseTime(\"2023-06-13\", \"OPEN TBA\") got %d, %t, want 1686582000, true", res.Unix(), unknown)
+	}
 
 }
@@ -0,0 +1,179 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheEntry is a cached response body along with the validators used to revalidate it.
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"contentType"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"lastModified"`
+	StoredAt     time.Time `json:"storedAt"`
+}
+
+// validator returns a string identifying the version of the cached response.
+func (e *CacheEntry) validator() string {
+	return e.ETag + "|" + e.LastModified
+}
+
+// Cache stores response bodies keyed by URL.
+type Cache interface {
+	// Get returns the entry stored for key, or ErrCacheMiss if there is none.
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	// Set stores entry for key, replacing any existing entry.
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+}
+
+// DiskCache stores entries as files in a directory.
+type DiskCache struct {
+	Dir string
+}
+
+func (c DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(hash[:])
+	return filepath.Join(c.Dir, name[:2], name+".json")
+}
+
+func (c DiskCache) Get(ctx context.Context, key string) (entry *CacheEntry, err error) {
+	b, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrCacheMiss
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &entry)
+	return
+}
+
+func (c DiskCache) Set(ctx context.Context, key string, entry *CacheEntry) (err error) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return
+	}
+	// write to a temporary file of our own first, so that neither concurrent readers nor concurrent writers of the same
+	// entry ever see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err != nil {
+		tmp.Close()
+		return
+	}
+	err = tmp.Close()
+	if err != nil {
+		return
+	}
+	err = os.Chmod(tmp.Name(), 0o644)
+	if err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RedisCache stores entries in redis, expiring them after TTL.
+type RedisCache struct {
+	Client *redis.Client
+	TTL    time.Duration
+}
+
+func (c RedisCache) key(key string) string {
+	return "httpcache:" + key
+}
+
+func (c RedisCache) Get(ctx context.Context, key string) (entry *CacheEntry, err error) {
+	b, err := c.Client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		err = ErrCacheMiss
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &entry)
+	return
+}
+
+func (c RedisCache) Set(ctx context.Context, key string, entry *CacheEntry) (err error) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	return c.Client.Set(ctx, c.key(key), b, c.TTL).Err()
+}
+
+// CacheFromEnv returns the cache specified by the FETCH_CACHE environment variable, which is one of
+// "disk", "redis" or empty for no cache.
+//
+// The disk cache is stored in FETCH_CACHE_DIR, defaulting to .cache/http.
+// The redis cache uses rdb, expiring entries after FETCH_CACHE_TTL, defaulting to 30 days.
+func CacheFromEnv(rdb *redis.Client) (cache Cache, err error) {
+	switch os.Getenv("FETCH_CACHE") {
+	case "":
+		return
+	case "disk":
+		dir := os.Getenv("FETCH_CACHE_DIR")
+		if dir == "" {
+			dir = filepath.Join(".cache", "http")
+		}
+		cache = DiskCache{Dir: dir}
+	case "redis":
+		if rdb == nil {
+			err = errors.New("redis fetch cache requires a redis connection")
+			return
+		}
+		ttl := 30 * 24 * time.Hour
+		if s := os.Getenv("FETCH_CACHE_TTL"); s != "" {
+			ttl, err = time.ParseDuration(s)
+			if err != nil {
+				return
+			}
+		}
+		cache = RedisCache{Client: rdb, TTL: ttl}
+	default:
+		err = errors.New("unknown fetch cache: " + os.Getenv("FETCH_CACHE"))
+	}
+	return
+}
+
+// CacheStats counts the cache hits and misses of the requests made using a context.
+type CacheStats struct {
+	// Hits is the number of responses revalidated by the server, and served from the cache.
+	Hits atomic.Int64
+	// Misses is the number of responses downloaded in full.
+	Misses atomic.Int64
+}
+
+type cacheStatsKey struct{}
+
+// WithCacheStats returns a context that counts the cache hits and misses of requests made using it in stats.
+func WithCacheStats(ctx context.Context, stats *CacheStats) context.Context {
+	return context.WithValue(ctx, cacheStatsKey{}, stats)
+}
+
+func cacheStatsFromContext(ctx context.Context) *CacheStats {
+	stats, _ := ctx.Value(cacheStatsKey{}).(*CacheStats)
+	return stats
+}
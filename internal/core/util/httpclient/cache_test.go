@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	cache := DiskCache{Dir: t.TempDir()}
+	if _, err := cache.Get(ctx, "https://example.com/"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected cache miss, got %v", err)
+	}
+	err := cache.Set(ctx, "https://example.com/", &CacheEntry{Body: []byte("body"), ETag: `"1"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := cache.Get(ctx, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entry.Body) != "body" || entry.ETag != `"1"` {
+		t.Errorf("expected stored entry, got %+v", entry)
+	}
+}
+
+func TestDiskCacheConcurrentSet(t *testing.T) {
+	ctx := context.Background()
+	cache := DiskCache{Dir: t.TempDir()}
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- cache.Set(ctx, "https://example.com/", &CacheEntry{Body: bytes.Repeat([]byte{byte('a' + i)}, 1<<16)})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	entry, err := cache.Get(ctx, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entry.Body) != 1<<16 || len(bytes.Trim(entry.Body, string(entry.Body[:1]))) != 0 {
+		t.Errorf("expected one whole entry, got %d bytes", len(entry.Body))
+	}
+	leftovers, err := filepath.Glob(filepath.Join(cache.Dir, "*", "*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("expected temporary files to be removed, got %v", leftovers)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body><p>live</p></body></html>"))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.Cache = DiskCache{Dir: t.TempDir()}
+	c := New(cfg)
+	var stats CacheStats
+	ctx := WithCacheStats(context.Background(), &stats)
+
+	first, err := c.LoadHTML(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.LoadHTML(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected unchanged document to not be parsed again")
+	}
+	b, err := c.GetBytes(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "<html><body><p>live</p></body></html>" {
+		t.Errorf("expected cached body, got %s", b)
+	}
+
+	if downloads.Load() != 1 {
+		t.Errorf("expected 1 download, got %d", downloads.Load())
+	}
+	if stats.Hits.Load() != 2 || stats.Misses.Load() != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d hits and %d misses", stats.Hits.Load(), stats.Misses.Load())
+	}
+}
+
+func TestParsedDocumentsEviction(t *testing.T) {
+	var p parsedDocuments
+	for i := range maxParsedDocuments + 1 {
+		p.put(parsedDocument{url: strconv.Itoa(i), validator: "v1", node: &html.Node{}})
+		// the first document is kept in use, so that the second one is the least recently used
+		if _, ok := p.get("0", "v1"); !ok {
+			t.Fatal("expected the document in use to be kept")
+		}
+	}
+	if len(p.docs) != maxParsedDocuments || p.order.Len() != maxParsedDocuments {
+		t.Errorf("expected %d documents, got %d", maxParsedDocuments, len(p.docs))
+	}
+	if _, ok := p.get("1", "v1"); ok {
+		t.Error("expected the least recently used document to be dropped")
+	}
+	if _, ok := p.get("0", "v2"); ok {
+		t.Error("expected a document with another validator not to be returned")
+	}
+}
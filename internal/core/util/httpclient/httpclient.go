@@ -0,0 +1,496 @@
+// Package httpclient contains the HTTP client shared by all fetchers.
+//
+// It is polite towards the sites being fetched, spacing out requests to the same host,
+// and retrying with exponential backoff when a site is overloaded.
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// StatusError is returned when a site responds with a non-successful status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s responded with status %d", e.URL, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a *StatusError for a page that does not exist.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// Config specifies the behaviour of a Client.
+type Config struct {
+	// UserAgent is sent with every request.
+	UserAgent string
+
+	// Timeout is the time limit of a single attempt of a request, including reading the body.
+	Timeout time.Duration
+
+	// MaxRetries is the number of times a request is retried after a network error, 5xx or 429 response.
+	MaxRetries int
+
+	// BaseBackoff is the delay before the first retry, doubling for every subsequent retry.
+	BaseBackoff time.Duration
+
+	// MaxBackoff is the longest delay before a retry, including delays requested using Retry-After.
+	MaxBackoff time.Duration
+
+	// HostInterval is the minimum time between the start of two requests to the same host.
+	HostInterval time.Duration
+
+	// HostIntervals overrides HostInterval for specific hosts.
+	HostIntervals map[string]time.Duration
+
+	// RobotsTxt specifies whether robots.txt of every host is consulted before requests are made to it.
+	// Crawl-delay specified in robots.txt overrides the host interval if longer.
+	RobotsTxt bool
+
+	// Cache stores the bodies of GET responses, which are then revalidated using conditional requests.
+	// Leave this empty to disable caching.
+	Cache Cache
+}
+
+// DefaultConfig returns the default client configuration.
+func DefaultConfig() Config {
+	return Config{
+		UserAgent:     "livefetcher (+https://github.com/yayuyokitano/livefetcher)",
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    time.Minute,
+		HostInterval:  500 * time.Millisecond,
+		HostIntervals: make(map[string]time.Duration),
+		RobotsTxt:     true,
+	}
+}
+
+// ConfigFromEnv returns the default configuration, overridden by the FETCH_USER_AGENT, FETCH_TIMEOUT,
+// FETCH_MAX_RETRIES, FETCH_HOST_INTERVAL and FETCH_ROBOTS_TXT environment variables if set.
+func ConfigFromEnv() (cfg Config, err error) {
+	cfg = DefaultConfig()
+	if s := os.Getenv("FETCH_USER_AGENT"); s != "" {
+		cfg.UserAgent = s
+	}
+	if s := os.Getenv("FETCH_TIMEOUT"); s != "" {
+		cfg.Timeout, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("FETCH_MAX_RETRIES"); s != "" {
+		cfg.MaxRetries, err = strconv.Atoi(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("FETCH_HOST_INTERVAL"); s != "" {
+		cfg.HostInterval, err = time.ParseDuration(s)
+		if err != nil {
+			return
+		}
+	}
+	if s := os.Getenv("FETCH_ROBOTS_TXT"); s != "" {
+		cfg.RobotsTxt, err = strconv.ParseBool(s)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Client is a rate limited, retrying HTTP client.
+// It is safe for concurrent use, and should be shared so that rate limits apply across fetchers.
+type Client struct {
+	cfg   Config
+	http  *http.Client
+	mu    sync.Mutex
+	hosts map[string]time.Time
+
+	parsed parsedDocuments
+
+	robotsMu      sync.Mutex
+	robotsEntries map[string]*robotsEntry
+}
+
+// parsedDocument is a parsed HTML document along with the validator of the cached response it was parsed from.
+type parsedDocument struct {
+	url       string
+	validator string
+	node      *html.Node
+}
+
+// maxParsedDocuments limits the number of parsed documents kept in memory. Parsed documents are far larger than their
+// HTML, and the client lives as long as the process, so only the most recently used ones are kept.
+const maxParsedDocuments = 64
+
+// parsedDocuments keeps the most recently used parsed documents, keyed by URL.
+type parsedDocuments struct {
+	mu    sync.Mutex
+	docs  map[string]*list.Element
+	order *list.List
+}
+
+// get returns the document parsed from url, if its validator matches.
+func (p *parsedDocuments) get(url, validator string) (n *html.Node, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.docs[url]
+	if !ok {
+		return
+	}
+	doc := e.Value.(parsedDocument)
+	if doc.validator != validator {
+		return nil, false
+	}
+	p.order.MoveToFront(e)
+	return doc.node, true
+}
+
+// put keeps a parsed document, dropping the least recently used one if there are too many.
+func (p *parsedDocuments) put(doc parsedDocument) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.docs == nil {
+		p.docs = make(map[string]*list.Element)
+		p.order = list.New()
+	}
+	if e, ok := p.docs[doc.url]; ok {
+		e.Value = doc
+		p.order.MoveToFront(e)
+		return
+	}
+	p.docs[doc.url] = p.order.PushFront(doc)
+	if p.order.Len() > maxParsedDocuments {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.docs, oldest.Value.(parsedDocument).url)
+	}
+}
+
+// New creates a new client with the given configuration.
+func New(cfg Config) *Client {
+	return &Client{
+		cfg:           cfg,
+		http:          &http.Client{Timeout: cfg.Timeout},
+		hosts:         make(map[string]time.Time),
+		robotsEntries: make(map[string]*robotsEntry),
+	}
+}
+
+var defaultClient *Client
+var defaultClientOnce sync.Once
+
+// Default returns the shared client, configured using ConfigFromEnv.
+//
+// Environment variables are read on first use, so that .env has been loaded.
+func Default() *Client {
+	defaultClientOnce.Do(func() {
+		cfg, err := ConfigFromEnv()
+		if err != nil {
+			fmt.Println("invalid fetch configuration, using defaults:", err)
+			cfg = DefaultConfig()
+		}
+		defaultClient = New(cfg)
+	})
+	return defaultClient
+}
+
+// SetDefault replaces the shared client.
+// It should be called during startup, before any connectors are run.
+func SetDefault(c *Client) {
+	defaultClientOnce.Do(func() {})
+	defaultClient = c
+}
+
+func (c *Client) hostInterval(host string) time.Duration {
+	if d, ok := c.cfg.HostIntervals[host]; ok {
+		return d
+	}
+	return c.cfg.HostInterval
+}
+
+// reserve returns the time at which a request to host may start, reserving that slot.
+// The next request to host is held back by the host interval, or by minInterval if it is longer.
+func (c *Client) reserve(host string, minInterval time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	next := c.hosts[host]
+	if next.Before(now) {
+		next = now
+	}
+	c.hosts[host] = next.Add(max(c.hostInterval(host), minInterval))
+	return next
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.BaseBackoff << attempt
+	if d <= 0 || d > c.cfg.MaxBackoff {
+		d = c.cfg.MaxBackoff
+	}
+	// add up to 50% jitter so retries from concurrent fetches do not line up
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (d time.Duration, ok bool) {
+	if value == "" {
+		return
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return
+}
+
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+func shouldRetry(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func sleep(req *http.Request, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do sends a request, waiting for the rate limit of the host and retrying on network errors, 5xx and 429 responses.
+// Waiting and retrying stop as soon as the context of the request is done.
+//
+// Requests disallowed by robots.txt are not sent, and return a *RobotsError.
+//
+// Requests with a body are only retried if req.GetBody is set, which http.NewRequest does for common body types.
+// Non-2xx responses are returned as a *StatusError, except 304 responses to conditional requests.
+// On success, the caller must close the response body.
+func (c *Client) Do(req *http.Request) (res *http.Response, err error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	err = c.checkRobots(req)
+	if err != nil {
+		return
+	}
+	return c.send(req, c.crawlDelay(req))
+}
+
+// send sends a request without consulting robots.txt, retrying server errors and network errors with backoff.
+// Responses that are not successful are returned as a *StatusError.
+func (c *Client) send(req *http.Request, crawlDelay time.Duration) (res *http.Response, err error) {
+	for attempt := 0; ; attempt++ {
+		err = sleep(req, time.Until(c.reserve(req.URL.Host, crawlDelay)))
+		if err != nil {
+			return
+		}
+
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return
+			}
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return
+			}
+		}
+
+		res, err = c.http.Do(req)
+		var delay time.Duration
+		if err == nil {
+			if res.StatusCode >= 200 && res.StatusCode < 300 {
+				return
+			}
+			if res.StatusCode == http.StatusNotModified && isConditional(req) {
+				return
+			}
+			res.Body.Close()
+			err = &StatusError{URL: req.URL.String(), StatusCode: res.StatusCode}
+			if !shouldRetry(res.StatusCode) {
+				return nil, err
+			}
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+				delay = min(retryAfter, c.cfg.MaxBackoff)
+			}
+			res = nil
+		} else if req.Context().Err() != nil {
+			return
+		}
+
+		if attempt >= c.cfg.MaxRetries {
+			return
+		}
+		if delay == 0 {
+			delay = c.backoff(attempt)
+		}
+		if sleepErr := sleep(req, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+// Get sends a GET request to url.
+//
+// If the client has a cache, the cached response is revalidated and returned if it has not changed.
+func (c *Client) Get(ctx context.Context, url string) (res *http.Response, err error) {
+	res, _, err = c.get(ctx, url)
+	return
+}
+
+// get sends a GET request to url, also returning the cache entry if the response was served from the cache.
+func (c *Client) get(ctx context.Context, url string) (res *http.Response, cached *CacheEntry, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	if c.cfg.Cache == nil {
+		res, err = c.Do(req)
+		return
+	}
+
+	entry, cacheErr := c.cfg.Cache.Get(ctx, url)
+	if cacheErr != nil && !errors.Is(cacheErr, ErrCacheMiss) {
+		// a broken cache should not stop fetching
+		fmt.Println(cacheErr)
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err = c.Do(req)
+	if err != nil {
+		return
+	}
+	stats := cacheStatsFromContext(ctx)
+
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		res.Body.Close()
+		if stats != nil {
+			stats.Hits.Add(1)
+		}
+		res.StatusCode = http.StatusOK
+		res.Header.Set("Content-Type", entry.ContentType)
+		res.Body = io.NopCloser(bytes.NewReader(entry.Body))
+		cached = entry
+		return
+	}
+
+	if stats != nil {
+		stats.Misses.Add(1)
+	}
+	etag, lastModified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	cacheErr = c.cfg.Cache.Set(ctx, url, &CacheEntry{
+		Body:         body,
+		ContentType:  res.Header.Get("Content-Type"),
+		ETag:         etag,
+		LastModified: lastModified,
+		StoredAt:     time.Now(),
+	})
+	if cacheErr != nil {
+		fmt.Println(cacheErr)
+	}
+	return
+}
+
+// GetBytes returns the body of url.
+func (c *Client) GetBytes(ctx context.Context, url string) (b []byte, err error) {
+	res, err := c.Get(ctx, url)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+// GetJSON decodes the JSON body of url into target.
+func (c *Client) GetJSON(ctx context.Context, url string, target any) (err error) {
+	res, err := c.Get(ctx, url)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(target)
+}
+
+// LoadHTML parses the HTML document at url, decoding it to UTF-8 based on its declared charset.
+//
+// If the document has not changed since it was last loaded by this client, the previously parsed document is returned.
+// The returned document may therefore be shared, and must not be modified.
+func (c *Client) LoadHTML(ctx context.Context, url string) (n *html.Node, err error) {
+	res, cached, err := c.get(ctx, url)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if cached != nil {
+		if doc, ok := c.parsed.get(url, cached.validator()); ok {
+			return doc, nil
+		}
+	}
+
+	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return
+	}
+	n, err = html.Parse(r)
+	if err != nil || c.cfg.Cache == nil {
+		return
+	}
+
+	entry := cached
+	if entry == nil {
+		entry = &CacheEntry{ETag: res.Header.Get("ETag"), LastModified: res.Header.Get("Last-Modified")}
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return
+	}
+	c.parsed.put(parsedDocument{url: url, validator: entry.validator(), node: n})
+	return
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.UserAgent = "livefetcher-test"
+	cfg.BaseBackoff = time.Millisecond
+	cfg.MaxBackoff = 10 * time.Millisecond
+	cfg.HostInterval = 0
+	cfg.RobotsTxt = false
+	return cfg
+}
+
+func TestRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "livefetcher-test" {
+			t.Errorf("expected user agent livefetcher-test, got %s", r.UserAgent())
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	b, err := New(testConfig()).GetBytes(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "ok" || requests.Load() != 3 {
+		t.Errorf("expected ok after 3 requests, got %s after %d requests", b, requests.Load())
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := New(testConfig()).GetBytes(context.Background(), server.URL)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 status error, got %v", err)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("expected IsNotFound to report %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestGiveUp(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	_, err := New(cfg).GetBytes(context.Background(), server.URL)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 status error, got %v", err)
+	}
+	if int(requests.Load()) != cfg.MaxRetries+1 {
+		t.Errorf("expected %d requests, got %d", cfg.MaxRetries+1, requests.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-5", 0, true},
+		{"Sat, 01 Jun 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Sat, 01 Jun 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseRetryAfter(test.value, now)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestHostInterval(t *testing.T) {
+	cfg := testConfig()
+	cfg.HostInterval = time.Minute
+	cfg.HostIntervals = map[string]time.Duration{"fast.example": 0}
+	c := New(cfg)
+
+	first := c.reserve("example.com", 0)
+	second := c.reserve("example.com", 0)
+	if second.Sub(first) != time.Minute {
+		t.Errorf("expected requests to example.com to be a minute apart, got %v", second.Sub(first))
+	}
+	if other := c.reserve("another.example.com", 0); other.After(second) || !other.Before(first.Add(time.Second)) {
+		t.Errorf("expected another host to not be limited, got %v", other.Sub(first))
+	}
+	c.reserve("fast.example", 0)
+	if fast := c.reserve("fast.example", 0); fast.After(first.Add(time.Second)) {
+		t.Errorf("expected host override to apply, got %v", fast.Sub(first))
+	}
+}
+
+func TestCancel(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.BaseBackoff = time.Minute
+	cfg.MaxBackoff = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New(cfg).GetBytes(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expected request to be aborted during backoff, took %v", time.Since(start))
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", requests.Load())
+	}
+}
@@ -0,0 +1,284 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long a fetched robots.txt is used before it is fetched again
+const (
+	robotsTTL      = 24 * time.Hour
+	robotsErrorTTL = time.Hour
+)
+
+// maximum size of a robots.txt file, anything after this is ignored
+const maxRobotsSize = 500 * 1024
+
+// RobotsError is returned when robots.txt of a site disallows fetching a URL.
+type RobotsError struct {
+	URL string
+}
+
+func (e *RobotsError) Error() string {
+	return "disallowed by robots.txt: " + e.URL
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// robotsRules is the part of a robots.txt file that applies to our user agent.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	disallowed bool
+}
+
+// allowed reports whether the given path, including its query, may be fetched.
+//
+// The longest matching rule wins, and allow rules win ties.
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowed {
+		return false
+	}
+	allowed, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
+
+func compileRobotsPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.Compile(expr)
+}
+
+// productToken returns the name robots.txt user agent lines are matched against, such as "livefetcher" for
+// "livefetcher/1.0 (+https://example.com)".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, " ")
+	token, _, _ = strings.Cut(token, "/")
+	return strings.ToLower(token)
+}
+
+// parseRobots returns the rules of a robots.txt file that apply to the given user agent.
+//
+// Rules of groups naming the user agent are used if there are any, otherwise rules of the * group are used.
+func parseRobots(r io.Reader, userAgent string) (rules *robotsRules, err error) {
+	token := productToken(userAgent)
+	type group struct {
+		agents     []string
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			continue
+		}
+		inAgents = false
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "allow", "disallow":
+			// an empty disallow rule allows everything, which is the default
+			if value == "" {
+				continue
+			}
+			var pattern *regexp.Regexp
+			pattern, err = compileRobotsPattern(value)
+			if err != nil {
+				return
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: pattern,
+			})
+		case "crawl-delay":
+			seconds, parseErr := strconv.ParseFloat(value, 64)
+			if parseErr == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return
+	}
+
+	rules = &robotsRules{}
+	for _, name := range []string{token, "*"} {
+		matched := false
+		for _, g := range groups {
+			if !slices.Contains(g.agents, name) {
+				continue
+			}
+			matched = true
+			rules.rules = append(rules.rules, g.rules...)
+			rules.crawlDelay = max(rules.crawlDelay, g.crawlDelay)
+		}
+		if matched {
+			break
+		}
+	}
+	return
+}
+
+type robotsEntry struct {
+	mu    sync.Mutex
+	rules *robotsRules
+	// err is the error fetching robots.txt, which is returned instead of fetching it again until it expires
+	err     error
+	expires time.Time
+}
+
+type ignoreRobotsKey struct{}
+
+// WithoutRobotsTxt returns a context whose requests do not consult robots.txt.
+// Only use this for sites that have explicitly allowed us to fetch them.
+func WithoutRobotsTxt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ignoreRobotsKey{}, true)
+}
+
+func ignoresRobots(ctx context.Context) bool {
+	ignore, _ := ctx.Value(ignoreRobotsKey{}).(bool)
+	return ignore
+}
+
+// robots returns the robots.txt rules of the host of u, fetching them if they are not cached.
+func (c *Client) robots(ctx context.Context, u *url.URL) (rules *robotsRules, err error) {
+	origin := u.Scheme + "://" + u.Host
+	c.robotsMu.Lock()
+	entry, ok := c.robotsEntries[origin]
+	if !ok {
+		entry = &robotsEntry{}
+		c.robotsEntries[origin] = entry
+	}
+	c.robotsMu.Unlock()
+
+	// concurrent requests to the same host wait for a single fetch of robots.txt
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if (entry.rules != nil || entry.err != nil) && time.Now().Before(entry.expires) {
+		return entry.rules, entry.err
+	}
+	rules, ttl, err := c.fetchRobots(ctx, origin)
+	// a cancelled request says nothing about the host, so it is not cached
+	if ctx.Err() != nil {
+		return
+	}
+	entry.rules, entry.err = rules, err
+	entry.expires = time.Now().Add(ttl)
+	return
+}
+
+// fetchRobots fetches and parses robots.txt, retrying like any other request, and following RFC 9309 for unavailable
+// files: a missing robots.txt allows everything, while a server error disallows everything until it is fetched again.
+// The returned ttl is how long the result, including an error, is cached.
+func (c *Client) fetchRobots(ctx context.Context, origin string) (rules *robotsRules, ttl time.Duration, err error) {
+	ttl = robotsErrorTTL
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
+
+	res, err := c.send(req, 0)
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500:
+		return &robotsRules{}, robotsTTL, nil
+	case errors.As(err, &statusErr):
+		return &robotsRules{disallowed: true}, robotsErrorTTL, nil
+	case err != nil:
+		// an unreachable host would fail the request anyway, so report the actual error instead of disallowing
+		return
+	}
+	defer res.Body.Close()
+	rules, err = parseRobots(res.Body, c.cfg.UserAgent)
+	if err != nil {
+		return
+	}
+	ttl = robotsTTL
+	return
+}
+
+// checkRobots returns a *RobotsError if robots.txt disallows the request.
+func (c *Client) checkRobots(req *http.Request) (err error) {
+	if !c.cfg.RobotsTxt || ignoresRobots(req.Context()) {
+		return
+	}
+	rules, err := c.robots(req.Context(), req.URL)
+	if err != nil {
+		return
+	}
+	if !rules.allowed(req.URL.RequestURI()) {
+		return &RobotsError{URL: req.URL.String()}
+	}
+	return
+}
+
+// crawlDelay returns the crawl delay requested by the cached robots.txt of host, if any.
+func (c *Client) crawlDelay(req *http.Request) time.Duration {
+	if !c.cfg.RobotsTxt || ignoresRobots(req.Context()) {
+		return 0
+	}
+	c.robotsMu.Lock()
+	entry, ok := c.robotsEntries[req.URL.Scheme+"://"+req.URL.Host]
+	c.robotsMu.Unlock()
+	if !ok {
+		return 0
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.rules == nil {
+		return 0
+	}
+	return entry.rules.crawlDelay
+}
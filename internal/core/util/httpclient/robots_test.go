@@ -0,0 +1,180 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `# comment
+User-agent: *
+Disallow: /
+
+User-agent: otherbot
+Allow: /
+
+User-agent: LiveFetcher
+User-agent: anotherbot
+Disallow: /private
+Allow: /private/schedule
+Disallow: /*.php$
+Crawl-delay: 2
+`
+
+func TestParseRobots(t *testing.T) {
+	rules, err := parseRobots(strings.NewReader(testRobots), "livefetcher/1.0 (+https://example.com)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/schedule", true},
+		{"/private", false},
+		{"/private/lives", false},
+		{"/private/schedule/2024", true},
+		{"/index.php", false},
+		{"/index.php?month=1", true},
+	}
+	for _, test := range tests {
+		if allowed := rules.allowed(test.path); allowed != test.allowed {
+			t.Errorf("allowed(%s) = %v, want %v", test.path, allowed, test.allowed)
+		}
+	}
+	if rules.crawlDelay != 2*time.Second {
+		t.Errorf("expected crawl delay of 2s, got %v", rules.crawlDelay)
+	}
+
+	rules, err = parseRobots(strings.NewReader(testRobots), "somebot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.allowed("/schedule") {
+		t.Error("expected * group to apply to unknown user agents")
+	}
+}
+
+func TestRobots(t *testing.T) {
+	var robotsRequests, pageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		pageRequests.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.RobotsTxt = true
+	c := New(cfg)
+	ctx := context.Background()
+
+	if _, err := c.GetBytes(ctx, server.URL+"/schedule"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.GetBytes(ctx, server.URL+"/private/lives")
+	var robotsErr *RobotsError
+	if !errors.As(err, &robotsErr) {
+		t.Errorf("expected robots error, got %v", err)
+	}
+	if _, err := c.GetBytes(WithoutRobotsTxt(ctx), server.URL+"/private/lives"); err != nil {
+		t.Errorf("expected opt-out to ignore robots.txt, got %v", err)
+	}
+
+	if robotsRequests.Load() != 1 {
+		t.Errorf("expected robots.txt to be fetched once, got %d", robotsRequests.Load())
+	}
+	if pageRequests.Load() != 2 {
+		t.Errorf("expected 2 page requests, got %d", pageRequests.Load())
+	}
+}
+
+func TestMissingRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.RobotsTxt = true
+	if _, err := New(cfg).GetBytes(context.Background(), server.URL+"/private"); err != nil {
+		t.Errorf("expected missing robots.txt to allow everything, got %v", err)
+	}
+}
+
+// closeConnection fails the request with a network error by closing the connection without a response.
+func closeConnection(t *testing.T, w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestRobotsRetry(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			if robotsRequests.Add(1) == 1 {
+				closeConnection(t, w)
+				return
+			}
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.RobotsTxt = true
+	if _, err := New(cfg).GetBytes(context.Background(), server.URL+"/schedule"); err != nil {
+		t.Errorf("expected robots.txt to be retried after a network error, got %v", err)
+	}
+	if robotsRequests.Load() != 2 {
+		t.Errorf("expected robots.txt to be fetched twice, got %d", robotsRequests.Load())
+	}
+}
+
+func TestRobotsErrorCached(t *testing.T) {
+	var robotsRequests, pageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			closeConnection(t, w)
+			return
+		}
+		pageRequests.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.RobotsTxt = true
+	c := New(cfg)
+	for range 3 {
+		if _, err := c.GetBytes(context.Background(), server.URL+"/schedule"); err == nil {
+			t.Error("expected unreachable robots.txt to fail the request")
+		}
+	}
+	if expected := int32(cfg.MaxRetries + 1); robotsRequests.Load() != expected {
+		t.Errorf("expected robots.txt to be fetched %d times, got %d", expected, robotsRequests.Load())
+	}
+	if pageRequests.Load() != 0 {
+		t.Errorf("expected no page requests, got %d", pageRequests.Load())
+	}
+}
@@ -0,0 +1,66 @@
+package mecab
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Reading returns the reading of s in katakana as far as it can be told without a dictionary, which is its kana in
+// katakana with anything else, such as kanji and latin letters, kept as is. It is used when mecab is not available.
+func Reading(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'ぁ' && r <= 'ゖ' {
+			return r + 'ァ' - 'ぁ'
+		}
+		return r
+	}, norm.NFC.String(width.Fold.String(s)))
+}
+
+type script int
+
+const (
+	scriptNone script = iota
+	scriptHan
+	scriptHiragana
+	scriptKatakana
+	scriptOther
+)
+
+func scriptOf(r rune) script {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return scriptHan
+	case unicode.Is(unicode.Hiragana, r):
+		return scriptHiragana
+	case unicode.Is(unicode.Katakana, r) || r == 'ー':
+		return scriptKatakana
+	case unicode.IsLetter(r) || unicode.IsNumber(r):
+		return scriptOther
+	}
+	return scriptNone
+}
+
+// Words splits s into words wherever the script changes, such as between kanji and hiragana, as far as words can be
+// told apart without a dictionary. Spaces and punctuation are left out. It is used when mecab is not available.
+func Words(s string) (words []string) {
+	var word strings.Builder
+	current := scriptNone
+	for _, r := range s {
+		sc := scriptOf(r)
+		if sc != current && word.Len() != 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+		current = sc
+		if sc != scriptNone {
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() != 0 {
+		words = append(words, word.String())
+	}
+	return
+}
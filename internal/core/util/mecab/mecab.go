@@ -1,47 +1,51 @@
 package mecab
 
 import (
-	"io"
+	"errors"
+	"log"
 	"os/exec"
+	"runtime"
+	"strings"
+	"time"
 )
 
-func spawnMecab() (cmd *exec.Cmd, stdin io.WriteCloser, stdout io.ReadCloser, err error) {
-	cmd = exec.Command("mecab", `--node-format=%pS%f[7]`, `--unk-format=%M`, `--eos-format=`)
+const timeout = 5 * time.Second
 
-	stdin, err = cmd.StdinPipe()
-	if err != nil {
-		return
-	}
+var (
+	workers = min(runtime.GOMAXPROCS(0), 4)
+	// readings answers every line with its reading, where words that are not in the dictionary are kept as is
+	readings = NewPool(workers, timeout, "mecab", `--node-format=%pS%f[7]`, `--unk-format=%M`, `--eos-format=\n`)
+	wakati   = NewPool(workers, timeout, "mecab", "-Owakati")
+)
 
-	stdout, err = cmd.StdoutPipe()
+// Mecab returns the reading of s in katakana, falling back to Reading if mecab is not available or fails.
+func Mecab(s string) (kana string, err error) {
+	kana, err = readings.Parse(strings.Join(strings.Fields(s), " "))
 	if err != nil {
-		return
+		logFallback(err)
+		return Reading(s), nil
 	}
-	err = cmd.Start()
 	return
 }
 
-func Mecab(s string) (kana string, err error) {
-	cmd, stdin, stdout, err := spawnMecab()
-	if err != nil {
-		return
-	}
-
-	_, err = stdin.Write([]byte(s + "\n"))
-	if err != nil {
-		return
-	}
-	stdin.Close()
-
-	out, err := io.ReadAll(stdout)
-	if err != nil {
-		return
+// Wakati splits s into its words, which are not separated by spaces in Japanese, falling back to Words if mecab is not
+// available or fails.
+func Wakati(s string) (words []string, err error) {
+	for _, line := range strings.Split(s, "\n") {
+		var out string
+		out, err = wakati.Parse(strings.TrimSuffix(line, "\r"))
+		if err != nil {
+			logFallback(err)
+			return Words(s), nil
+		}
+		words = append(words, strings.Fields(out)...)
 	}
+	return
+}
 
-	err = cmd.Wait()
-	if err != nil {
-		return
+// logFallback logs why mecab could not be used, unless it is not installed at all.
+func logFallback(err error) {
+	if !errors.Is(err, exec.ErrNotFound) {
+		log.Println("mecab failed, falling back to reading without a dictionary:", err)
 	}
-	kana = string(out)
-	return
 }
@@ -0,0 +1,81 @@
+package mecab
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReading(t *testing.T) {
+	tests := map[string]string{
+		"ずっと真夜中でいいのに。": "ズット真夜中デイイノニ。",
+		"ﾖﾙｼｶ":         "ヨルシカ",
+		"yonige":       "yonige",
+	}
+	for s, expected := range tests {
+		if res := Reading(s); res != expected {
+			t.Errorf("Reading(%q): expected %q, got %q", s, expected, res)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"東京ワンマンライブ2024": {"東京", "ワンマンライブ", "2024"},
+		"夜の本気ダンス":       {"夜", "の", "本気", "ダンス"},
+		"one man / 対バン": {"one", "man", "対", "バン"},
+	}
+	for s, expected := range tests {
+		if res := Words(s); !slices.Equal(res, expected) {
+			t.Errorf("Words(%q): expected %q, got %q", s, expected, res)
+		}
+	}
+}
+
+func requireCommand(t *testing.T, name string) {
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skip(name + " is not available")
+	}
+}
+
+func TestPool(t *testing.T) {
+	requireCommand(t, "cat")
+	p := NewPool(2, time.Second, "cat")
+	defer p.Close()
+	for _, line := range []string{"ヨルシカ", "", "yonige"} {
+		if res, err := p.Parse(line); err != nil || res != line {
+			t.Errorf("expected %q, got %q (%v)", line, res, err)
+		}
+	}
+}
+
+func TestPoolRestart(t *testing.T) {
+	requireCommand(t, "head")
+	// every process answers a single line before exiting
+	p := NewPool(1, time.Second, "head", "-n", "1")
+	defer p.Close()
+	for _, line := range []string{"first", "second", "third"} {
+		if res, err := p.Parse(line); err != nil || res != line {
+			t.Errorf("expected %q after restart, got %q (%v)", line, res, err)
+		}
+	}
+}
+
+func TestPoolTimeout(t *testing.T) {
+	requireCommand(t, "sleep")
+	p := NewPool(1, 50*time.Millisecond, "sleep", "10")
+	defer p.Close()
+	if _, err := p.Parse("line"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
+
+func TestPoolNotFound(t *testing.T) {
+	p := NewPool(1, time.Second, "livefetcher-command-that-does-not-exist")
+	defer p.Close()
+	if _, err := p.Parse("line"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("expected command not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package mecab
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrTimeout is returned when a process does not answer a line in time, in which case it is restarted.
+var ErrTimeout = errors.New("mecab: timed out")
+
+// Pool is a set of long-lived processes of a command that answers every line written to its stdin with a line on its
+// stdout, such as mecab, so that a process does not need to be spawned for every line. Processes are started when
+// first needed, and restarted if they crash or time out.
+type Pool struct {
+	name    string
+	args    []string
+	timeout time.Duration
+	// processes are the idle processes of the pool, where nil is a process that is not running
+	processes chan *process
+}
+
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// NewPool returns a pool of at most size processes of the command name, which time out after not answering a line for
+// timeout.
+func NewPool(size int, timeout time.Duration, name string, args ...string) *Pool {
+	p := &Pool{
+		name:      name,
+		args:      args,
+		timeout:   timeout,
+		processes: make(chan *process, size),
+	}
+	for i := 0; i < size; i++ {
+		p.processes <- nil
+	}
+	return p
+}
+
+// Parse writes line to an idle process, waiting for one if all are busy, and returns the line it answers with. A
+// process that has crashed since it was last used is restarted once before giving up.
+func (p *Pool) Parse(line string) (out string, err error) {
+	proc := <-p.processes
+	defer func() {
+		p.processes <- proc
+	}()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if proc == nil {
+			proc, err = startProcess(p.name, p.args...)
+			if err != nil {
+				return
+			}
+		}
+		out, err = proc.parse(line, p.timeout)
+		if err == nil {
+			return
+		}
+		proc.stop()
+		proc = nil
+		if errors.Is(err, ErrTimeout) {
+			return
+		}
+	}
+	return
+}
+
+// Close stops every process of the pool, waiting for those in use. The pool cannot be used afterwards.
+func (p *Pool) Close() {
+	for i := 0; i < cap(p.processes); i++ {
+		if proc := <-p.processes; proc != nil {
+			proc.stop()
+		}
+	}
+}
+
+func startProcess(name string, args ...string) (proc *process, err error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	err = cmd.Start()
+	if err != nil {
+		return
+	}
+	proc = &process{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}
+	return
+}
+
+func (proc *process) parse(line string, timeout time.Duration) (out string, err error) {
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		_, err := io.WriteString(proc.stdin, line+"\n")
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		out, err := proc.stdout.ReadString('\n')
+		done <- result{strings.TrimSuffix(out, "\n"), err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.out, r.err
+	case <-timer.C:
+		return "", ErrTimeout
+	}
+}
+
+func (proc *process) stop() {
+	proc.stdin.Close()
+	proc.cmd.Process.Kill()
+	proc.cmd.Wait()
+}
@@ -0,0 +1,158 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"golang.org/x/text/width"
+)
+
+const priceAmountExp = `[¥\\]\s*(\d+)|(\d+)\s*(?:円|yen)`
+
+var (
+	priceThousandsPattern = regexp.MustCompile(`(\d)[,.](\d{3})(\D|$)`)
+	priceAmountPattern    = regexp.MustCompile(priceAmountExp)
+	priceSeparatorPattern = regexp.MustCompile(`[/\n、|]`)
+	// drinkIncludedPattern matches a drink that is included in the ticket, such as 1ドリンク付
+	drinkIncludedPattern = regexp.MustCompile(`(?:\d\s*)?(?:ドリンク|drinks?|d)\s*(?:付き?|込み?|incl[a-z.]*)`)
+	// drinkRequiredPattern matches a drink that must be bought on top of the ticket, such as +1D, D代別 or 別途1ドリンク
+	drinkRequiredPattern = regexp.MustCompile(`\+\s*\d?\s*(?:ドリンク|drinks?|d)\s*代?\s*(?:別途?|必要|要|order)?|(?:\d\s*)?(?:ドリンク|drinks?|d)\s*代?\s*(?:別途?|要|必要|order)|(?:別途|要)\s*\d?\s*(?:ドリンク|drinks?|d)\s*代?(?:\s*order)?`)
+	// drinkChargePattern matches the price of a required drink directly following it, such as the ¥600 of +1D(¥600)
+	drinkChargePattern = regexp.MustCompile(`^[\s(:]*(?:` + priceAmountExp + `)\)?`)
+	// priceParenPattern matches a label in parentheses directly following a price, such as the 前売 of ¥3000(前売)
+	priceParenPattern   = regexp.MustCompile(`^\s*\(([^)]*)\)`)
+	freePattern         = regexp.MustCompile(`無料|free|フリー`)
+	unknownPricePattern = regexp.MustCompile(`未定|tba|tbc|調整中`)
+)
+
+// priceTierPatterns are the labels of every tier type, in the order they take precedence in when a label matches several.
+var priceTierPatterns = []struct {
+	tierType datastructures.PriceTierType
+	pattern  *regexp.Regexp
+}{
+	{datastructures.PriceTierStreaming, regexp.MustCompile(`配信|視聴|stream|online|オンライン|ツイキャス`)},
+	{datastructures.PriceTierStudent, regexp.MustCompile(`学生|高校生|student|学割|u-?\d{2}`)},
+	{datastructures.PriceTierDrink, regexp.MustCompile(`ドリンク|drink|\dd\b`)},
+	{datastructures.PriceTierDoor, regexp.MustCompile(`当日|door`)},
+	{datastructures.PriceTierAdvance, regexp.MustCompile(`前売|予約|事前|先行|早割|adv`)},
+}
+
+func priceTierType(label string) (tierType datastructures.PriceTierType, ok bool) {
+	for _, p := range priceTierPatterns {
+		if p.pattern.MatchString(label) {
+			return p.tierType, true
+		}
+	}
+	return datastructures.PriceTierGeneral, false
+}
+
+func parsePriceAmount(s string, m []int) int {
+	// the amount is in either the first or second group, depending on whether it is written as ¥3000 or 3000円
+	if m[2] == -1 {
+		m = m[2:]
+	}
+	amount, _ := strconv.Atoi(s[m[2]:m[3]])
+	return amount
+}
+
+// ParsePriceTiers parses the price text of a live, such as 前売¥3,000 / 当日¥3,500 (+1D), into its price tiers.
+//
+// Prices are labelled by the text preceding them, or by text in parentheses directly following them.
+// If the text mentions a required drink, every ticket tier requires one, and its price is a tier of its own if given.
+// Text without any price is a single unknown tier, unless it says the live is free.
+func ParsePriceTiers(price string) (tiers datastructures.PriceTiers) {
+	s := strings.ToLower(width.Fold.String(price))
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	// separators are removed twice, as the character following one is consumed to tell it apart from a decimal point
+	s = priceThousandsPattern.ReplaceAllString(s, "$1$2$3")
+	s = priceThousandsPattern.ReplaceAllString(s, "$1$2$3")
+	s = drinkIncludedPattern.ReplaceAllString(s, " ")
+
+	drinkRequired := false
+	for {
+		m := drinkRequiredPattern.FindStringIndex(s)
+		if m == nil {
+			break
+		}
+		drinkRequired = true
+		end := m[1]
+		if charge := drinkChargePattern.FindStringSubmatchIndex(s[end:]); charge != nil {
+			tiers = append(tiers, datastructures.PriceTier{
+				Type:   datastructures.PriceTierDrink,
+				Amount: parsePriceAmount(s[end:], charge),
+			})
+			end += charge[1]
+		}
+		s = s[:m[0]] + " " + s[end:]
+	}
+
+	// pending are the types of labels written together before their prices, such as ADV/DOOR ¥2500/¥3000
+	var pending []datastructures.PriceTierType
+	var last datastructures.PriceTier
+	for _, segment := range priceSeparatorPattern.Split(s, -1) {
+		matches := priceAmountPattern.FindAllStringSubmatchIndex(segment, -1)
+		if matches == nil {
+			tierType, ok := priceTierType(segment)
+			if freePattern.MatchString(segment) {
+				tiers = append(tiers, datastructures.PriceTier{Type: tierType, Free: true})
+			} else if unknownPricePattern.MatchString(segment) {
+				tiers = append(tiers, datastructures.PriceTier{Type: tierType, Unknown: true})
+			} else if ok {
+				pending = append(pending, tierType)
+			}
+			continue
+		}
+
+		labelStart := 0
+		for _, m := range matches {
+			if m[0] < labelStart {
+				// the price was inside the parentheses of the previous one
+				continue
+			}
+			tierType, ok := priceTierType(segment[labelStart:m[0]])
+			labelStart = m[1]
+			if paren := priceParenPattern.FindStringSubmatch(segment[m[1]:]); !ok && paren != nil {
+				tierType, ok = priceTierType(paren[1])
+				labelStart += len(paren[0])
+			}
+			if len(pending) != 0 {
+				if ok {
+					pending = append(pending, tierType)
+				}
+				tierType, pending = pending[0], pending[1:]
+			}
+			amount := parsePriceAmount(segment, m)
+			last = datastructures.PriceTier{
+				Type:   tierType,
+				Amount: amount,
+				Free:   amount == 0,
+			}
+			tiers = append(tiers, last)
+		}
+	}
+	// labels left without a price of their own share the last one, such as ADV/DOOR ¥2500
+	if last.Type != "" {
+		for _, tierType := range pending {
+			last.Type = tierType
+			tiers = append(tiers, last)
+		}
+	}
+
+	if len(tiers) == 0 {
+		tiers = append(tiers, datastructures.PriceTier{Type: datastructures.PriceTierGeneral, Unknown: true})
+	}
+
+	for _, tier := range tiers {
+		if tier.Type == datastructures.PriceTierDrink {
+			drinkRequired = true
+		}
+	}
+	for i := range tiers {
+		tiers[i].DrinkRequired = drinkRequired && tiers[i].Type != datastructures.PriceTierDrink
+	}
+	return
+}
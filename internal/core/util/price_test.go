@@ -0,0 +1,89 @@
+package util
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestParsePriceTiers(t *testing.T) {
+	advance := datastructures.PriceTierAdvance
+	door := datastructures.PriceTierDoor
+	general := datastructures.PriceTierGeneral
+	student := datastructures.PriceTierStudent
+	streaming := datastructures.PriceTierStreaming
+	drink := datastructures.PriceTierDrink
+
+	tests := []struct {
+		price    string
+		expected datastructures.PriceTiers
+	}{
+		{"前売¥3,000 / 当日¥3,500 (+1D)", datastructures.PriceTiers{
+			{Type: advance, Amount: 3000, DrinkRequired: true},
+			{Type: door, Amount: 3500, DrinkRequired: true},
+		}},
+		{"前売り ￥２，５００　当日 ￥３，０００（＋１Ｄ ¥600）", datastructures.PriceTiers{
+			{Type: drink, Amount: 600},
+			{Type: advance, Amount: 2500, DrinkRequired: true},
+			{Type: door, Amount: 3000, DrinkRequired: true},
+		}},
+		{"ADV ¥2000 / DOOR ¥2500 (D代別)", datastructures.PriceTiers{
+			{Type: advance, Amount: 2000, DrinkRequired: true},
+			{Type: door, Amount: 2500, DrinkRequired: true},
+		}},
+		{"一般3000円、学生2000円、配信チケット1500円", datastructures.PriceTiers{
+			{Type: general, Amount: 3000},
+			{Type: student, Amount: 2000},
+			{Type: streaming, Amount: 1500},
+		}},
+		{"¥2500(前売) ¥3000(当日) ドリンク代¥600", datastructures.PriceTiers{
+			{Type: advance, Amount: 2500, DrinkRequired: true},
+			{Type: door, Amount: 3000, DrinkRequired: true},
+			{Type: drink, Amount: 600},
+		}},
+		{"¥3000(1ドリンク付)", datastructures.PriceTiers{
+			{Type: general, Amount: 3000},
+		}},
+		{"ADV/DOOR ¥2500+1D/¥3000+1D", datastructures.PriceTiers{
+			{Type: advance, Amount: 2500, DrinkRequired: true},
+			{Type: door, Amount: 3000, DrinkRequired: true},
+		}},
+		{"ADV/DOOR ￥2.500 各＋1drink代別途(¥600)", datastructures.PriceTiers{
+			{Type: drink, Amount: 600},
+			{Type: advance, Amount: 2500, DrinkRequired: true},
+			{Type: door, Amount: 2500, DrinkRequired: true},
+		}},
+		{"入場無料", datastructures.PriceTiers{
+			{Type: general, Free: true},
+		}},
+		{"前売¥2000 / 当日未定", datastructures.PriceTiers{
+			{Type: advance, Amount: 2000},
+			{Type: door, Unknown: true},
+		}},
+		{"お問い合わせください", datastructures.PriceTiers{
+			{Type: general, Unknown: true},
+		}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		res := ParsePriceTiers(test.price)
+		if !slices.Equal(res, test.expected) {
+			t.Errorf("ParsePriceTiers(%q): expected %+v, got %+v", test.price, test.expected, res)
+		}
+	}
+}
+
+func TestPriceTiersRange(t *testing.T) {
+	tiers := ParsePriceTiers("前売¥2500 / 当日¥3000 / 学生無料 / 配信¥1500 / ドリンク代¥600")
+	min, max, ok := tiers.Range()
+	if !ok || min != 2500 || max != 3000 {
+		t.Errorf("expected range 2500-3000, got %d-%d (%t)", min, max, ok)
+	}
+	if _, _, ok := ParsePriceTiers("配信チケット¥2000").Range(); ok {
+		t.Error("expected no range for streaming only price")
+	}
+	if _, _, ok := ParsePriceTiers("TBA").Range(); ok {
+		t.Error("expected no range for unknown price")
+	}
+}
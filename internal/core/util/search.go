@@ -0,0 +1,96 @@
+package util
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/mecab"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// foldSearchRune folds the width and case of r, keeping r if it does not fold into a single rune, so that folded text
+// lines up with the original text rune by rune.
+func foldSearchRune(r rune) rune {
+	folded := []rune(strings.ToLower(width.Fold.String(string(r))))
+	if len(folded) != 1 {
+		return r
+	}
+	return folded[0]
+}
+
+// NormalizeSearchText folds the width and case of s, so that full-width and half-width text are searched alike.
+// Half-width voiced kana fold into a kana and a combining mark, which are composed again.
+func NormalizeSearchText(s string) string {
+	return norm.NFC.String(strings.Map(foldSearchRune, strings.TrimSpace(s)))
+}
+
+// SearchTokens splits normalized text into the words it is searched by, using mecab as Japanese is not written with
+// spaces between words. Words without any letters or numbers are left out.
+func SearchTokens(s string) (tokens []string, err error) {
+	words, err := mecab.Wakati(s)
+	if err != nil {
+		return
+	}
+	tokens = make([]string, 0, len(words))
+	for _, word := range words {
+		if strings.IndexFunc(word, isSearchRune) == -1 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return
+}
+
+func isSearchRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// SearchTerms returns the terms highlighted in results of the normalized search text s, given its tokens. Single
+// characters such as particles would match almost anything, so they are left out unless they are the whole search.
+func SearchTerms(s string, tokens []string) (terms []string) {
+	terms = []string{s}
+	for _, token := range tokens {
+		if len([]rune(token)) < 2 || slices.Contains(terms, token) {
+			continue
+		}
+		terms = append(terms, token)
+	}
+	return
+}
+
+// HighlightMatches splits text into the parts matching any of the normalized terms and those in between, ignoring
+// differences in width and case.
+func HighlightMatches(text string, terms []string) (highlight datastructures.Highlight) {
+	runes := []rune(text)
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = foldSearchRune(r)
+	}
+
+	matched := make([]bool, len(runes))
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(folded); i++ {
+			if slices.Equal(folded[i:i+len(t)], t) {
+				for j := i; j < i+len(t); j++ {
+					matched[j] = true
+				}
+			}
+		}
+	}
+
+	for i, r := range runes {
+		if len(highlight) != 0 && highlight[len(highlight)-1].Match == matched[i] {
+			highlight[len(highlight)-1].Text += string(r)
+			continue
+		}
+		highlight = append(highlight, datastructures.HighlightFragment{Text: string(r), Match: matched[i]})
+	}
+	return
+}
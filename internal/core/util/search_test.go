@@ -0,0 +1,43 @@
+package util
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+)
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := map[string]string{
+		" ＯＮＥ－ＭＡＮ ": "one-man",
+		"ﾜﾝﾏﾝﾗｲﾌﾞ":  "ワンマンライブ",
+		"ワンマンLive":  "ワンマンlive",
+	}
+	for s, expected := range tests {
+		if res := NormalizeSearchText(s); res != expected {
+			t.Errorf("NormalizeSearchText(%q): expected %q, got %q", s, expected, res)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	terms := SearchTerms("ワンマンの夜", []string{"ワンマン", "の", "夜"})
+	if !slices.Equal(terms, []string{"ワンマンの夜", "ワンマン"}) {
+		t.Errorf("expected single characters to be left out, got %v", terms)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	res := HighlightMatches("ＴＯＵＲ 2024 ワンマン", []string{"tour", "ワンマン"})
+	expected := datastructures.Highlight{
+		{Text: "ＴＯＵＲ", Match: true},
+		{Text: " 2024 "},
+		{Text: "ワンマン", Match: true},
+	}
+	if !slices.Equal(res, expected) {
+		t.Errorf("expected %+v, got %+v", expected, res)
+	}
+	if HighlightMatches("対バン", []string{"ワンマン"}).HasMatch() {
+		t.Error("expected no match")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"io"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"reflect"
 	"slices"
@@ -29,6 +30,9 @@ func Build(w io.Writer, r *http.Request, user datastructures.AuthUser, funcMap t
 		"FormatDate": func(t time.Time) string {
 			return i18nloader.FormatDate(t, i18nloader.GetLanguages(r), true)
 		},
+		"FormatLiveTime": func(t time.Time, unknown bool) string {
+			return i18nloader.FormatDate(t, i18nloader.GetLanguages(r), !unknown)
+		},
 		"Lang": func() string { return i18nloader.GetMainLanguage(r) },
 		"GetUser": func() datastructures.AuthUser {
 			return user
@@ -84,6 +88,9 @@ func Build(w io.Writer, r *http.Request, user datastructures.AuthUser, funcMap t
 		"FormatTime": func(t time.Time) string {
 			return t.Format(time.RFC3339)
 		},
+		"FormatDistance": func(km float64) string {
+			return strconv.FormatFloat(km, 'f', 1, 64)
+		},
 		"Add": func(a, b int) int {
 			return a + b
 		},
@@ -93,10 +100,22 @@ func Build(w io.Writer, r *http.Request, user datastructures.AuthUser, funcMap t
 		"Mult": func(a, b int) int {
 			return a * b
 		},
-		"GetPaginatedUrl": func(offset int) string {
+		// Weekdays returns the weekdays in the order they are shown in, starting from Monday
+		"Weekdays": func() []int {
+			return []int{1, 2, 3, 4, 5, 6, 0}
+		},
+		// LiveSorts returns the orders lives can be listed in
+		"LiveSorts": func() []queries.LiveSort {
+			return queries.LiveSorts
+		},
+		// ArtistURL returns the link to the page of an artist, whose name may contain slashes
+		"ArtistURL": func(name string) string {
+			return "/artist/" + url.PathEscape(name)
+		},
+		"GetPaginatedUrl": func(cursor string) string {
 			url := *r.URL
 			values := url.Query()
-			values.Set("offset", strconv.Itoa(offset))
+			values.Set("cursor", cursor)
 			url.RawQuery = values.Encode()
 			return url.String()
 		},
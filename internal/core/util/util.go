@@ -15,24 +15,30 @@ import (
 	"github.com/go-playground/form"
 	"github.com/yayuyokitano/livefetcher/internal/core/logging"
 	"github.com/yayuyokitano/livefetcher/internal/core/util/datastructures"
+	"github.com/yayuyokitano/livefetcher/internal/core/util/httpclient"
 	i18nloader "github.com/yayuyokitano/livefetcher/internal/i18n"
 	"github.com/yayuyokitano/livefetcher/internal/services/calendar"
 )
 
+// GetTimeFromString gets the hour and minute of the first time written as hh:mm in s, which are empty if s contains
+// no time. Hours of 24 or more are moved to the next day, which nextDay reports.
 func GetTimeFromString(s string) (hour string, minute string, nextDay bool) {
 	colon := strings.Index(s, ":")
 	if colon == -1 {
-		hour = "03"
-		minute = "24"
 		return
 	}
-	hour = fmt.Sprintf("%02s", stripNonNumeric(s[max(colon-2, 0):colon]))
-	minute = fmt.Sprintf("%02s", stripNonNumeric(s[colon+1:min(colon+3, len(s))]))
+	hour = stripNonNumeric(s[max(colon-2, 0):colon])
+	minute = stripNonNumeric(s[colon+1 : min(colon+3, len(s))])
+	if hour == "" || minute == "" {
+		hour, minute = "", ""
+		return
+	}
+	hour = fmt.Sprintf("%02s", hour)
+	minute = fmt.Sprintf("%02s", minute)
 
 	nhour, err := strconv.Atoi(hour)
 	if err != nil {
-		hour = "03"
-		minute = "24"
+		hour, minute = "", ""
 		return
 	}
 	if nhour >= 24 {
@@ -308,7 +314,9 @@ func InsertShortYearMonth(s string) string {
 	return fmt.Sprintf(s, t.Year()%100, int(t.Month()))
 }
 
-func SpacedPriceTimeFetcher(d string, s string) (price string, open time.Time, start time.Time, err error) {
+// SpacedPriceTimeFetcher gets the open time, start time and price from s, which lists the times followed by the price.
+// If s does not contain both times, open and start are midnight of d, and timeUnknown is true.
+func SpacedPriceTimeFetcher(d string, s string) (price string, open time.Time, start time.Time, timeUnknown bool, err error) {
 	r, err := regexp.Compile(`\s+`)
 	if err != nil {
 		return
@@ -317,7 +325,7 @@ func SpacedPriceTimeFetcher(d string, s string) (price string, open time.Time, s
 	split := strings.Split(string(processed), " ")
 	for i, v := range split {
 		hour, min, nextDay := GetTimeFromString(v)
-		if hour == "03" && min == "24" {
+		if hour == "" {
 			continue
 		}
 		if open.IsZero() {
@@ -340,11 +348,11 @@ func SpacedPriceTimeFetcher(d string, s string) (price string, open time.Time, s
 			return
 		}
 	}
-	defaultTime, err := time.Parse(timeLayout, fmt.Sprintf("%s %s:%s:00 +0900", d, "03", "24"))
+	date, err := time.Parse(timeLayout, fmt.Sprintf("%s 00:00:00 +0900", d))
 	if err != nil {
 		return
 	}
-	price, open, start = "", defaultTime, defaultTime
+	price, open, start, timeUnknown = "", date, date, true
 	return
 }
 
@@ -373,11 +381,11 @@ func GetUniqueVenueIDs(a []datastructures.LiveHouse) (b []string) {
 func findNthTime(s string, n int) string {
 	re, err := regexp.Compile(`\d{2}:\d{2}`)
 	if err != nil {
-		return "03:24"
+		return ""
 	}
 	matches := re.FindAllString(s, n)
 	if matches == nil {
-		return "03:24"
+		return ""
 	}
 	return matches[len(matches)-1]
 }
@@ -454,15 +462,9 @@ func GetRelevantYear(month int) int {
 	return time.Now().Year()
 }
 
-func GetJSON(url string, target interface{}) error {
-	client := &http.Client{Timeout: 10 * time.Second}
-	r, err := client.Get(url)
-	if err != nil {
-		return err
-	}
-	defer r.Body.Close()
-
-	return json.NewDecoder(r.Body).Decode(target)
+// GetJSON decodes the JSON body of url into target, using the shared fetch client.
+func GetJSON(ctx context.Context, url string, target interface{}) error {
+	return httpclient.Default().GetJSON(ctx, url, target)
 }
 
 var JapanTime = time.FixedZone("UTC+9", +9*60*60)
@@ -39,8 +39,11 @@ func TestGetTimeFromString(t *testing.T) {
 	if hour, min, nextDay := GetTimeFromString("sdkl ndms  asdkjn dsfm dsm , sd 01:02 asd  adsf ads"); hour != "01" || min != "02" || nextDay != false {
 		t.Errorf("GetTimeFromString(\"sdkl ndms  asdkjn dsfm dsm , sd 01:02 asd  adsf ads\") != \"01\", \"02\", false, got %s, %s, %t", hour, min, nextDay)
 	}
-	if hour, min, nextDay := GetTimeFromString("not a time"); hour != "03" || min != "24" || nextDay != false {
-		t.Errorf("GetTimeFromString(\"not a time\") != \"03\", \"34\", false, got %s, %s, %t", hour, min, nextDay)
+	if hour, min, nextDay := GetTimeFromString("not a time"); hour != "" || min != "" || nextDay != false {
+		t.Errorf("GetTimeFromString(\"not a time\") != \"\", \"\", false, got %s, %s, %t", hour, min, nextDay)
+	}
+	if hour, min, nextDay := GetTimeFromString("開場 --:--"); hour != "" || min != "" || nextDay != false {
+		t.Errorf("GetTimeFromString(\"開場 --:--\") != \"\", \"\", false, got %s, %s, %t", hour, min, nextDay)
 	}
 	if hour, min, nextDay := GetTimeFromString("25:30"); hour != "01" || min != "30" || nextDay != true {
 		t.Errorf("GetTimeFromString(\"25:30\") != \"01\", \"30\", true, got %s, %s, %t", hour, min, nextDay)
@@ -102,13 +105,13 @@ func TestGetYearMonthDay(t *testing.T) {
 }
 
 func TestSpacedPriceTimeFetcher(t *testing.T) {
-	if price, open, start, err := SpacedPriceTimeFetcher("2023-06-01", "OPEN 17:30 START 18:00 ADV ¥2000 DOOR ¥2500 1D別"); price != "ADV ¥2000 DOOR ¥2500 1D別" || open.Unix() != 1685608200 || start.Unix() != 1685610000 || err != nil {
-		t.Errorf("SpacedPriceTimeFetcher(\"2023-06-01\", \"OPEN 17:30 START 18:00 ADV ¥2000 DOOR ¥2500 1D別\") != \"ADV ¥2000 DOOR ¥2500 1D別\", 1685608200, 1685610000, nil, got %s, %d, %d, %s", price, open.Unix(), start.Unix(), err)
+	if price, open, start, unknown, err := SpacedPriceTimeFetcher("2023-06-01", "OPEN 17:30 START 18:00 ADV ¥2000 DOOR ¥2500 1D別"); price != "ADV ¥2000 DOOR ¥2500 1D別" || open.Unix() != 1685608200 || start.Unix() != 1685610000 || unknown || err != nil {
+		t.Errorf("SpacedPriceTimeFetcher(\"2023-06-01\", \"OPEN 17:30 START 18:00 ADV ¥2000 DOOR ¥2500 1D別\") != \"ADV ¥2000 DOOR ¥2500 1D別\", 1685608200, 1685610000, false, nil, got %s, %d, %d, %t, %s", price, open.Unix(), start.Unix(), unknown, err)
 	}
-	if price, open, start, err := SpacedPriceTimeFetcher("2023-06-01", "kjagdsn"); price != "" || open.Unix() != 1685557440 || start.Unix() != 1685557440 || err != nil {
-		t.Errorf("SpacedPriceTimeFetcher(\"2023-06-01\", \"kjagdsn\") != \"\", 1685557440, 1685557440, nil, got %s, %d, %d, %s", price, open.Unix(), start.Unix(), err)
+	if price, open, start, unknown, err := SpacedPriceTimeFetcher("2023-06-01", "kjagdsn"); price != "" || open.Unix() != 1685545200 || start.Unix() != 1685545200 || !unknown || err != nil {
+		t.Errorf("SpacedPriceTimeFetcher(\"2023-06-01\", \"kjagdsn\") != \"\", 1685545200, 1685545200, true, nil, got %s, %d, %d, %t, %s", price, open.Unix(), start.Unix(), unknown, err)
 	}
-	if price, open, start, err := SpacedPriceTimeFetcher("invalid date", "aksfls"); err == nil {
+	if price, open, start, _, err := SpacedPriceTimeFetcher("invalid date", "aksfls"); err == nil {
 		t.Errorf("SpacedPriceTimeFetcher(\"invalid date\", \"aksfls\") != error, got %s, %d, %d, %s", price, open.Unix(), start.Unix(), err)
 	}
 }
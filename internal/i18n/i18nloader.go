@@ -3,6 +3,7 @@ package i18nloader
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -49,15 +50,14 @@ func Init() (err error) {
 	return
 }
 
-func TimeIsIndeterminate(t time.Time) bool {
-	return t.Hour() == 3 && t.Minute() == 24
-}
-
+// FormatDate formats the date of t, followed by its time if includeTime is true.
+//
+// In Japanese, times before 6:00 are written in 24+ hour notation on the previous date.
 func FormatDate(t time.Time, langs []string, includeTime bool) string {
 	for _, lang := range langs {
 		if strings.HasPrefix(lang, "ja") {
 			hour := t.Hour()
-			if hour <= 5 && !TimeIsIndeterminate(t) {
+			if hour <= 5 && includeTime {
 				hour += 24
 				t = t.AddDate(0, 0, -1)
 			}
@@ -80,33 +80,35 @@ func FormatDate(t time.Time, langs []string, includeTime bool) string {
 				weekday = "土"
 			}
 
-			if !includeTime || TimeIsIndeterminate(t) {
+			if !includeTime {
 				return fmt.Sprintf("%d年%d月%d日（%s）", t.Year(), int(t.Month()), t.Day(), weekday)
 			}
 			return fmt.Sprintf("%d年%d月%d日（%s）%02d:%02d", t.Year(), int(t.Month()), t.Day(), weekday, hour, t.Minute())
 		}
 		if strings.HasPrefix(lang, "en") {
-			if !includeTime || TimeIsIndeterminate(t) {
+			if !includeTime {
 				return t.Format("Mon 2 Jan 2006")
 			}
 			return t.Format("Mon 2 Jan 2006 03:04 PM")
 		}
 	}
-	if !includeTime || TimeIsIndeterminate(t) {
+	if !includeTime {
 		return t.Format("Mon 2 Jan 2006")
 	}
 	return t.Format("Mon 2 Jan 2006 03:04 PM")
 }
 
-func FormatOpenStartTime(openTime time.Time, startTime time.Time, langs []string) string {
-	dateString := FormatDate(startTime, langs, false)
+// FormatOpenStartTime formats the date of a live followed by its open and start times, leaving out unknown times.
+func FormatOpenStartTime(openTime time.Time, openUnknown bool, startTime time.Time, startUnknown bool, langs []string) string {
+	date := startTime
 	openHour := openTime.Hour()
 	startHour := startTime.Hour()
 	for _, lang := range langs {
 		if strings.HasPrefix(lang, "ja") {
-			if startHour <= 5 && !TimeIsIndeterminate(startTime) {
+			if startHour <= 5 && !startUnknown {
+				date = date.AddDate(0, 0, -1)
 				startHour += 24
-				if !TimeIsIndeterminate(openTime) {
+				if !openUnknown {
 					openHour += 24
 				}
 			}
@@ -116,10 +118,11 @@ func FormatOpenStartTime(openTime time.Time, startTime time.Time, langs []string
 			break
 		}
 	}
-	if !TimeIsIndeterminate(openTime) {
+	dateString := FormatDate(date, langs, false)
+	if !openUnknown {
 		dateString += fmt.Sprintf(" OPEN: %02d:%02d", openHour, openTime.Minute())
 	}
-	if !TimeIsIndeterminate(startTime) {
+	if !startUnknown {
 		dateString += fmt.Sprintf(" START: %02d:%02d", startHour, startTime.Minute())
 	}
 	return dateString
@@ -165,6 +168,21 @@ func GetMainLanguageFromLangs(langs []string) string {
 	return tag.String()
 }
 
+// LocalizeAll returns the message str in every language it is translated to, such as to search by any of them.
+func LocalizeAll(str string) (strs []string) {
+	if bundle == nil {
+		return
+	}
+	for _, tag := range bundle.LanguageTags() {
+		s, err := i18n.NewLocalizer(bundle, tag.String()).Localize(&i18n.LocalizeConfig{MessageID: str})
+		if err != nil || slices.Contains(strs, s) {
+			continue
+		}
+		strs = append(strs, s)
+	}
+	return
+}
+
 func LocalizerFromLangs(langs []string) SimplifiedLocalizer {
 	return SimplifiedLocalizer{i18n.NewLocalizer(bundle, langs...)}
 }
@@ -52,18 +52,41 @@ func initService(ctx context.Context, props datastructures.CalendarProperties) (
 	return calendar.NewService(ctx, option.WithHTTPClient(config.Client(ctx, token)))
 }
 
+// eventDateTime converts a live time to an event time, which is an all day event time if the time is unknown.
+func eventDateTime(t time.Time, unknown bool) *calendar.EventDateTime {
+	if unknown {
+		return &calendar.EventDateTime{
+			Date: datastructures.GetLiveDate(t),
+		}
+	}
+	return &calendar.EventDateTime{
+		DateTime: t.Format(time.RFC3339),
+	}
+}
+
+// parseEventDateTime parses an event time, where all day events start at midnight of their date in Japan.
+func parseEventDateTime(t *calendar.EventDateTime) (time.Time, error) {
+	if t.DateTime == "" {
+		return time.ParseInLocation(time.DateOnly, t.Date, time.FixedZone("UTC+9", +9*60*60))
+	}
+	return time.Parse(time.RFC3339, t.DateTime)
+}
+
 func buildEvents(live datastructures.Live) (*calendar.Event, *calendar.Event) {
 	fmt.Printf("%+v\n", live)
+	// events must start and end at a time or on a date alike, so a single unknown time makes both events all day
+	timeUnknown := live.OpenTimeUnknown || live.StartTimeUnknown
+	openEnd, startEnd := live.StartTime, datastructures.GetEventEndTime(live)
+	if timeUnknown {
+		// all day events end on the following date
+		openEnd, startEnd = live.StartTime.AddDate(0, 0, 1), live.StartTime.AddDate(0, 0, 1)
+	}
 	openEvent := &calendar.Event{
 		Summary:     "OPEN " + live.Venue.Name,
 		Description: "<h2>ARTIST</h2><ul>",
 		Location:    live.Venue.Name,
-		Start: &calendar.EventDateTime{
-			DateTime: live.OpenTime.Format(time.RFC3339),
-		},
-		End: &calendar.EventDateTime{
-			DateTime: live.StartTime.Format(time.RFC3339),
-		},
+		Start:       eventDateTime(live.OpenTime, timeUnknown),
+		End:         eventDateTime(openEnd, timeUnknown),
 	}
 
 	for _, a := range live.Artists {
@@ -75,12 +98,8 @@ func buildEvents(live datastructures.Live) (*calendar.Event, *calendar.Event) {
 		Summary:     "START " + live.Venue.Name,
 		Description: openEvent.Description,
 		Location:    live.Venue.Name,
-		Start: &calendar.EventDateTime{
-			DateTime: live.StartTime.Format(time.RFC3339),
-		},
-		End: &calendar.EventDateTime{
-			DateTime: datastructures.GetEventEndTime(live).Format(time.RFC3339),
-		},
+		Start:       eventDateTime(live.StartTime, timeUnknown),
+		End:         eventDateTime(startEnd, timeUnknown),
 	}
 
 	return openEvent, startEvent
@@ -179,13 +198,13 @@ func (g *GoogleCalendar) GetAllEvents(ctx context.Context, props datastructures.
 	}
 
 	for _, e := range serviceEvents.Items {
-		startTime, err := time.Parse(time.RFC3339, e.Start.DateTime)
+		startTime, err := parseEventDateTime(e.Start)
 		if err != nil {
 			// TODO: log
 			continue
 		}
 
-		endTime, err := time.Parse(time.RFC3339, e.End.DateTime)
+		endTime, err := parseEventDateTime(e.End)
 		if err != nil {
 			// TODO: log
 			continue
@@ -109,7 +109,7 @@ func Start() (err error) {
 		return
 	}
 
-	Pool, err = pgxpool.New(context.Background(), GetPGConnectionString())
+	err = StartPool()
 	if err != nil {
 		return
 	}
@@ -122,6 +122,12 @@ func Start() (err error) {
 	return
 }
 
+// StartPool connects to Postgres only, for commands that need nothing else, such as migrate.
+func StartPool() (err error) {
+	Pool, err = pgxpool.New(context.Background(), GetPGConnectionString())
+	return
+}
+
 func Stop() {
 	if Pool != nil {
 		Pool.Close()
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+CREATE TABLE connector_runs (
+	id BIGSERIAL PRIMARY KEY,
+	connector_id TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	finished_at TIMESTAMPTZ NOT NULL,
+	duration_ms BIGINT NOT NULL,
+	pages_fetched INT NOT NULL DEFAULT 0,
+	lives_parsed INT NOT NULL DEFAULT 0,
+	added INT NOT NULL DEFAULT 0,
+	modified INT NOT NULL DEFAULT 0,
+	deleted INT NOT NULL DEFAULT 0,
+	error_message TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_connector_runs_connector_started ON connector_runs(connector_id, started_at DESC);
+
+-- +migrate Down
+
+DROP INDEX idx_connector_runs_connector_started;
+DROP TABLE connector_runs;
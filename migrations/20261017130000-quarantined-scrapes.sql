@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE quarantined_scrapes (
+	id BIGSERIAL PRIMARY KEY,
+	livehouses_ids TEXT[] NOT NULL,
+	anomalies TEXT[] NOT NULL,
+	lives JSONB NOT NULL,
+	-- pending, approved, rejected, or superseded by a newer scrape of the same live houses, as only the latest can be approved
+	status TEXT NOT NULL DEFAULT 'pending',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	resolved_at TIMESTAMPTZ
+);
+CREATE INDEX idx_quarantined_scrapes_status ON quarantined_scrapes(status);
+
+-- +migrate Down
+
+DROP INDEX idx_quarantined_scrapes_status;
+DROP TABLE quarantined_scrapes;
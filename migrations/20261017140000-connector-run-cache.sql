@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE connector_runs ADD COLUMN cache_hits INT NOT NULL DEFAULT 0;
+ALTER TABLE connector_runs ADD COLUMN cache_misses INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+
+ALTER TABLE connector_runs DROP COLUMN cache_misses;
+ALTER TABLE connector_runs DROP COLUMN cache_hits;
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+ALTER TABLE connector_runs ADD COLUMN error_kind TEXT NOT NULL DEFAULT '';
+UPDATE connector_runs SET error_kind='error' WHERE error_message<>'';
+
+-- +migrate Down
+
+ALTER TABLE connector_runs DROP COLUMN error_kind;
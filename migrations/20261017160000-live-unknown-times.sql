@@ -0,0 +1,15 @@
+-- +migrate Up
+
+ALTER TABLE lives ADD COLUMN opentime_unknown BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE lives ADD COLUMN starttime_unknown BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE lives SET opentime_unknown=TRUE, opentime=date_trunc('day', opentime AT TIME ZONE 'Asia/Tokyo') AT TIME ZONE 'Asia/Tokyo'
+	WHERE (opentime AT TIME ZONE 'Asia/Tokyo')::time='03:24';
+UPDATE lives SET starttime_unknown=TRUE, starttime=date_trunc('day', starttime AT TIME ZONE 'Asia/Tokyo') AT TIME ZONE 'Asia/Tokyo'
+	WHERE (starttime AT TIME ZONE 'Asia/Tokyo')::time='03:24';
+
+-- +migrate Down
+
+UPDATE lives SET opentime=opentime + INTERVAL '3 hours 24 minutes' WHERE opentime_unknown;
+UPDATE lives SET starttime=starttime + INTERVAL '3 hours 24 minutes' WHERE starttime_unknown;
+ALTER TABLE lives DROP COLUMN starttime_unknown;
+ALTER TABLE lives DROP COLUMN opentime_unknown;
@@ -0,0 +1,24 @@
+-- +migrate Up
+
+CREATE TABLE live_prices (
+	lives_id BIGINT NOT NULL,
+	position SMALLINT NOT NULL,
+	tier_type TEXT NOT NULL,
+	amount INT NOT NULL DEFAULT 0,
+	drink_required BOOLEAN NOT NULL DEFAULT FALSE,
+	free BOOLEAN NOT NULL DEFAULT FALSE,
+	unknown BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (lives_id, position),
+	FOREIGN KEY (lives_id) REFERENCES lives(id) ON DELETE CASCADE
+);
+-- the predicate matches ticketAmountsExp in internal/core/queries/filters.go, so that the price filter and sort can use it
+-- set whenever the price tiers of a live are parsed, as a price can parse to no tiers at all
+ALTER TABLE lives ADD COLUMN price_parsed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_live_prices_amount ON live_prices(amount) WHERE tier_type NOT IN ('drink', 'streaming', 'student') AND NOT unknown;
+
+-- +migrate Down
+
+DROP INDEX idx_live_prices_amount;
+ALTER TABLE lives DROP COLUMN price_parsed_at;
+DROP TABLE live_prices;
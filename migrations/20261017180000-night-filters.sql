@@ -0,0 +1,21 @@
+-- +migrate Up
+
+ALTER TABLE saved_searches ADD COLUMN min_price INT;
+ALTER TABLE saved_searches ADD COLUMN max_price INT;
+ALTER TABLE saved_searches ADD COLUMN weekdays SMALLINT[] NOT NULL DEFAULT '{}';
+ALTER TABLE saved_searches ADD COLUMN start_after SMALLINT;
+ALTER TABLE saved_searches ADD COLUMN start_before SMALLINT;
+
+CREATE INDEX idx_lives_night_weekday ON lives ((EXTRACT(DOW FROM CASE WHEN starttime_unknown THEN starttime AT TIME ZONE 'Asia/Tokyo' ELSE ((starttime AT TIME ZONE 'Asia/Tokyo') - INTERVAL '6 hours') END)));
+CREATE INDEX idx_lives_night_start ON lives ((((starttime AT TIME ZONE 'Asia/Tokyo') - INTERVAL '6 hours')::time)) WHERE NOT starttime_unknown;
+
+-- +migrate Down
+
+DROP INDEX idx_lives_night_start;
+DROP INDEX idx_lives_night_weekday;
+
+ALTER TABLE saved_searches DROP COLUMN start_before;
+ALTER TABLE saved_searches DROP COLUMN start_after;
+ALTER TABLE saved_searches DROP COLUMN weekdays;
+ALTER TABLE saved_searches DROP COLUMN max_price;
+ALTER TABLE saved_searches DROP COLUMN min_price;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE live_search_documents (
+	lives_id BIGINT PRIMARY KEY,
+	document TEXT NOT NULL,
+	tokens TSVECTOR NOT NULL,
+	FOREIGN KEY (lives_id) REFERENCES lives(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_live_search_documents_tokens ON live_search_documents USING GIN (tokens);
+CREATE INDEX idx_live_search_documents_document ON live_search_documents USING GIN (document gin_trgm_ops);
+
+-- +migrate Down
+
+DROP INDEX idx_live_search_documents_document;
+DROP INDEX idx_live_search_documents_tokens;
+DROP TABLE live_search_documents;
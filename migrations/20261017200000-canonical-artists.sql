@@ -0,0 +1,35 @@
+-- +migrate Up
+
+ALTER TABLE liveartists DROP CONSTRAINT liveartists_artists_name_fkey;
+ALTER TABLE liveartists ADD CONSTRAINT liveartists_artists_name_fkey FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE artistaliases DROP CONSTRAINT artistaliases_artists_name_fkey;
+ALTER TABLE artistaliases ADD CONSTRAINT artistaliases_artists_name_fkey FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE liveartists ADD COLUMN credit TEXT;
+UPDATE liveartists SET credit = artists_name;
+ALTER TABLE liveartists ALTER COLUMN credit SET NOT NULL;
+
+CREATE TABLE artistcredits (
+	credit TEXT PRIMARY KEY,
+	artist_key TEXT NOT NULL,
+	artists_name TEXT NOT NULL,
+	FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX idx_artistcredits_artist_key ON artistcredits(artist_key);
+CREATE INDEX idx_artistcredits_artists ON artistcredits(artists_name);
+
+-- keys are only lowercased here, and are folded by util.ArtistKey when `livefetcher migrate` normalizes them afterwards
+INSERT INTO artistcredits (credit, artist_key, artists_name) SELECT name, lower(name), name FROM artists;
+
+-- +migrate Down
+
+DROP INDEX idx_artistcredits_artists;
+DROP INDEX idx_artistcredits_artist_key;
+DROP TABLE artistcredits;
+
+ALTER TABLE liveartists DROP COLUMN credit;
+
+ALTER TABLE artistaliases DROP CONSTRAINT artistaliases_artists_name_fkey;
+ALTER TABLE artistaliases ADD CONSTRAINT artistaliases_artists_name_fkey FOREIGN KEY (artists_name) REFERENCES artists(name) ON DELETE CASCADE;
+ALTER TABLE liveartists DROP CONSTRAINT liveartists_artists_name_fkey;
+ALTER TABLE liveartists ADD CONSTRAINT liveartists_artists_name_fkey FOREIGN KEY (artists_name) REFERENCES artists(name) ON DELETE CASCADE;
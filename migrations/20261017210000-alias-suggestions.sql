@@ -0,0 +1,37 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE artistaliases ADD COLUMN source TEXT NOT NULL DEFAULT 'machine';
+ALTER TABLE artistaliases ADD COLUMN users_id BIGINT;
+ALTER TABLE artistaliases ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE artistaliases ADD CONSTRAINT artistaliases_users_id_fkey FOREIGN KEY (users_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE alias_suggestions (
+	id BIGSERIAL PRIMARY KEY,
+	artists_name TEXT NOT NULL,
+	alias TEXT NOT NULL,
+	users_id BIGINT,
+	status TEXT NOT NULL DEFAULT 'pending',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	resolved_at TIMESTAMPTZ,
+	resolved_by BIGINT,
+	FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (users_id) REFERENCES users(id) ON DELETE SET NULL,
+	FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_alias_suggestions_status ON alias_suggestions(status);
+CREATE UNIQUE INDEX idx_alias_suggestions_pending ON alias_suggestions(artists_name, lower(alias)) WHERE status = 'pending';
+
+-- +migrate Down
+
+DROP INDEX idx_alias_suggestions_pending;
+DROP INDEX idx_alias_suggestions_status;
+DROP TABLE alias_suggestions;
+
+ALTER TABLE artistaliases DROP CONSTRAINT artistaliases_users_id_fkey;
+ALTER TABLE artistaliases DROP COLUMN created_at;
+ALTER TABLE artistaliases DROP COLUMN users_id;
+ALTER TABLE artistaliases DROP COLUMN source;
+
+ALTER TABLE users DROP COLUMN is_admin;
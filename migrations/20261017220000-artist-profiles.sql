@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE artists ALTER COLUMN socials TYPE TEXT[] USING string_to_array(NULLIF(socials, ''), E'\n');
+
+-- +migrate Down
+
+ALTER TABLE artists ALTER COLUMN socials TYPE TEXT USING array_to_string(socials, E'\n');
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+CREATE TABLE artist_similarity (
+	artists_name TEXT NOT NULL,
+	similar_artists_name TEXT NOT NULL,
+	score REAL NOT NULL,
+	co_lives INT NOT NULL DEFAULT 0,
+	co_fans INT NOT NULL DEFAULT 0,
+	PRIMARY KEY (artists_name, similar_artists_name),
+	FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (similar_artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX idx_artist_similarity_similar ON artist_similarity(similar_artists_name);
+
+CREATE TABLE artist_similarity_queue (
+	artists_name TEXT PRIMARY KEY,
+	queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	FOREIGN KEY (artists_name) REFERENCES artists(name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- every artist is computed after the next crawl
+INSERT INTO artist_similarity_queue (artists_name) SELECT name FROM artists;
+
+-- +migrate Down
+
+DROP TABLE artist_similarity_queue;
+DROP INDEX idx_artist_similarity_similar;
+DROP TABLE artist_similarity;
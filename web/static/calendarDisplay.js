@@ -12,14 +12,29 @@
  * @param {string} startTime - The time the live starts, as a string
  * @param {Array.<string>} artists - An array of the artists performing at the live
  * @param {Object.<string, Array.<CalendarEvent>>} calendarEvents - The calendar events to look for conflicts with
+ * @param {boolean} startTimeUnknown - Whether only the date of the live is known, in which case the whole date is checked
  *
  * @returns {Array.<CalendarEvent>} - The list of conflicting events
  */
-function getConflicts(openTime, startTime, artists, calendarEvents) {
+function getConflicts(
+  openTime,
+  startTime,
+  artists,
+  calendarEvents,
+  startTimeUnknown = false,
+) {
   const boundaryStart = new Date(openTime);
-  boundaryStart.setHours(boundaryStart.getHours() - 1);
   const boundaryEnd = new Date(startTime);
-  boundaryEnd.setHours(boundaryEnd.getHours() + liveDurationHours(artists) + 1);
+  if (startTimeUnknown) {
+    // unknown times are midnight of the date of the live
+    boundaryStart.setTime(boundaryEnd.getTime());
+    boundaryEnd.setDate(boundaryEnd.getDate() + 1);
+  } else {
+    boundaryStart.setHours(boundaryStart.getHours() - 1);
+    boundaryEnd.setHours(
+      boundaryEnd.getHours() + liveDurationHours(artists) + 1,
+    );
+  }
 
   /**
    * @type {Object.<string, CalendarEvent>}
@@ -23,27 +23,42 @@ function createMapDataRetriever() {
       geoJson: [],
     },
     filteredLives: [],
+    filterRequestId: 0,
+    isPopupOpen: false,
     liveMarkers: {},
     date: dateString,
     filterLives() {
-      const bounds = leaflet.getBounds();
-      const minLat = bounds.getSouth();
-      const maxLat = bounds.getNorth();
-      const minLng = bounds.getWest();
-      const maxLng = bounds.getEast();
-      this.filteredLives = this.map.lives.filter((live) => {
-        if (live.venue.latitude < minLat || live.venue.latitude > maxLat) {
-          return false;
-        }
-        if (live.venue.longitude < minLng || live.venue.longitude > maxLng) {
-          return false;
-        }
-        return true;
+      // the lives in view are fetched from the server, so that they can be sorted by their distance from the center
+      const requestId = ++this.filterRequestId;
+      const center = leaflet.getCenter();
+      const params = new URLSearchParams({
+        bbox: leaflet.getBounds().toBBoxString(),
+        lat: center.lat,
+        lng: center.lng,
+        sortByDistance: true,
       });
+      const urlDate = this.date.split("-").join("/");
+      fetch(`/api/dailylives/${urlDate}?${params}`)
+        .then((res) => res.json())
+        .then((map) => {
+          if (requestId !== this.filterRequestId) {
+            return;
+          }
+          this.filteredLives = map.lives;
+        });
     },
     initMapData() {
-      leaflet.addEventListener("moveend", this.filterLives.bind(this));
-      leaflet.addEventListener("zoomend", this.filterLives.bind(this));
+      let timeout;
+      leaflet.addEventListener("popupopen", () => (this.isPopupOpen = true));
+      leaflet.addEventListener("popupclose", () => (this.isPopupOpen = false));
+      // moveend also fires after zooming. Panning to fit a popup does not reorder the list underneath the cursor.
+      leaflet.addEventListener("moveend", () => {
+        if (this.isPopupOpen) {
+          return;
+        }
+        clearTimeout(timeout);
+        timeout = setTimeout(this.filterLives.bind(this), 300);
+      });
       this.getMapData();
     },
     getMapData() {
@@ -93,3 +108,10 @@ function getLiveCounter(num) {
   }
   return `${num}ライブ`;
 }
+
+function getDistanceLabel(km) {
+  if (document.documentElement.lang == "en") {
+    return `${km.toFixed(1)} km away`;
+  }
+  return `${km.toFixed(1)}km`;
+}